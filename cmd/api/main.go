@@ -4,11 +4,12 @@ import (
     "context"
     "insurance-claims-api/internal/config"
     "insurance-claims-api/internal/handlers"
-    "insurance-claims-api/internal/middleware"
     "insurance-claims-api/internal/repositories"
     "insurance-claims-api/internal/services"
+    "insurance-claims-api/internal/utils"
     "log"
     "time"
+    _ "time/tzdata"
 
     "github.com/gin-gonic/gin"
     "go.mongodb.org/mongo-driver/mongo"
@@ -17,13 +18,31 @@ import (
 )
 
 var (
-    client     *mongo.Client
-    claimService services.ClaimService
-    authService  services.AuthService
+    client              *mongo.Client
+    claimService        services.ClaimService
+    authService         services.AuthService
+    policyService       services.PolicyService
+    tenantService       services.TenantService
+    delegationService   services.DelegationService
+    auditService        services.AuditService
+    queueService        services.QueueService
+    slaService          services.SLAService
+    schedulerService    services.SchedulerService
+    ruleService         services.RuleService
+    commentService      services.CommentService
+    notificationService services.NotificationService
+    reportService       services.ReportService
+    exportService       services.ExportService
+    importService       services.ImportService
+    documentService     services.DocumentService
+    idempotencyService  services.IdempotencyService
 )
 
 func main() {
     config.LoadConfig()
+    if err := utils.RegisterValidations(config.AppConfig.PolicyNumberPattern); err != nil {
+        log.Fatal(err)
+    }
 
     var err error
     client, err = mongo.Connect(context.TODO(), options.Client().ApplyURI(config.AppConfig.MongoURI))
@@ -38,37 +57,113 @@ func main() {
     }
     log.Println("Connected to MongoDB Atlas!")
 
-    userRepo := repositories.NewUserRepository(client)
-    claimRepo := repositories.NewClaimRepository(client)
+    dbs := repositories.NewTenantDatabases(client, config.AppConfig.TenantDBMode)
+    repositories.BackfillTenant(dbs, config.AppConfig.DefaultTenant, "users", "claims")
+
+    userRepo := repositories.NewUserRepository(dbs)
+    claimRepo := repositories.NewClaimRepository(dbs)
+    roleRepo := repositories.NewRoleRepository(dbs)
+    tenantRepo := repositories.NewTenantRepository(dbs)
+    delegationRepo := repositories.NewDelegationRepository(dbs)
+    auditRepo := repositories.NewAuditRepository(dbs)
+    queueRepo := repositories.NewQueueRepository(dbs)
+    slaRepo := repositories.NewSLARepository(dbs)
+    jobRepo := repositories.NewJobRepository(dbs)
+    ruleRepo := repositories.NewRuleRepository(dbs)
+    counterRepo := repositories.NewCounterRepository(dbs)
+    commentRepo := repositories.NewCommentRepository(dbs)
+    notificationRepo := repositories.NewNotificationRepository(dbs)
+    preferenceRepo := repositories.NewNotificationPreferenceRepository(dbs)
+    outboxRepo := repositories.NewEmailOutboxRepository(dbs)
+    idempotencyRepo := repositories.NewIdempotencyRepository(dbs)
+    exportRepo := repositories.NewExportRepository(dbs)
+    documentRepo := repositories.NewDocumentRepository(dbs)
 
-    authService = services.NewAuthService(userRepo)
-    claimService = services.NewClaimService(claimRepo, userRepo)
+    auditService = services.NewAuditService(auditRepo)
+    idempotencyService = services.NewIdempotencyService(idempotencyRepo, config.AppConfig.IdempotencyTTL)
+    tenantService = services.NewTenantService(tenantRepo)
+    authService = services.NewAuthService(userRepo, tenantService, auditService)
+    policyService = services.NewPolicyService(roleRepo, userRepo, delegationRepo)
+    queueService = services.NewQueueService(claimRepo, userRepo, queueRepo, policyService, tenantService)
+    ruleService = services.NewRuleService(ruleRepo, claimRepo, userRepo, policyService)
+    fraudService := services.NewFraudService(tenantService, config.AppConfig.FraudReviewThreshold,
+        services.PolicyVelocityScorer{ClaimRepo: claimRepo, Window: config.AppConfig.FraudVelocityWindow, MinOthers: 2},
+        services.NearThresholdScorer{Thresholds: config.AppConfig.FraudAmountThresholds, Margin: 0.05},
+        services.RecycledDocumentScorer{ClaimRepo: claimRepo},
+        services.PolicyInceptionScorer{Window: config.AppConfig.FraudInceptionWindow},
+    )
+    duplicateService := services.NewDuplicateService(claimRepo)
+    documentService = services.NewDocumentService(claimRepo, documentRepo, userRepo)
+    notificationService = services.NewNotificationService(notificationRepo, preferenceRepo, outboxRepo, userRepo,
+        services.NewMailTransport(config.AppConfig), config.AppConfig.EmailMaxAttempts)
+    claimService = services.NewClaimService(claimRepo, counterRepo, userRepo, queueService, ruleService, fraudService, duplicateService, auditService, documentService, notificationService, tenantService)
+    slaService = services.NewSLAService(slaRepo, claimRepo, userRepo, policyService, tenantService, notificationService)
+    delegationService = services.NewDelegationService(delegationRepo, userRepo)
+    reportService = services.NewReportService(claimRepo)
+    exportService = services.NewExportService(claimRepo, exportRepo)
+    importService = services.NewImportService(claimRepo, counterRepo, userRepo)
+    commentService = services.NewCommentService(commentRepo, claimRepo, userRepo, policyService, notificationService)
+
+    schedulerService = services.NewSchedulerService(jobRepo)
+    jobs := []struct {
+        name, spec, description string
+        fn                      services.JobFunc
+    }{
+        {"sla-evaluate", config.AppConfig.SLASchedule, "Re-evaluate claim SLAs and escalate breaches",
+            services.EvaluateSLAJob(slaService)},
+        {"expire-stale-drafts", config.AppConfig.DraftExpirySchedule, "Expire drafts with no activity",
+            services.ExpireStaleDraftsJob(claimRepo, tenantService, config.AppConfig.DraftExpiry)},
+        {"release-expired-locks", config.AppConfig.LockSweepSchedule, "Return lapsed queue checkouts to the pool",
+            services.ReleaseExpiredLocksJob(claimRepo, tenantService)},
+        {"email-dispatch", config.AppConfig.EmailDispatchSchedule, "Send queued notification emails",
+            services.DispatchEmailsJob(notificationService, tenantService)},
+        {"report-digest", config.AppConfig.ReportDigestSchedule, "Email the periodic claims summary to report readers",
+            services.ReportDigestJob(reportService, policyService, userRepo, notificationService, tenantService, config.AppConfig.ReportDigestDays)},
+    }
+    for _, job := range jobs {
+        if err := schedulerService.Register(job.name, job.spec, job.description, job.fn); err != nil {
+            log.Fatal("Cannot schedule job:", err)
+        }
+    }
+    schedulerService.Start()
 
     r := gin.Default()
     r.Use(cors.New(cors.Config{
         AllowOrigins:     []string{"http://localhost:3000","http://localhost:3001",},
-        AllowMethods:     []string{"GET", "POST", "PATCH", "DELETE"},
-        AllowHeaders:     []string{"Origin", "Content-Type", "Authorization"},
-        ExposeHeaders:    []string{"Content-Length"},
+        AllowMethods:     []string{"GET", "POST", "PUT", "PATCH", "DELETE"},
+        AllowHeaders:     []string{"Origin", "Content-Type", "Authorization", "Idempotency-Key", "If-Match"},
+        ExposeHeaders:    []string{"Content-Length", "ETag"},
         AllowCredentials: true,
         MaxAge:           12 * time.Hour,
     }))
 
-    r.POST("/api/v1/login", handlers.Login(authService))
-
-    authRoutes := r.Group("/api/v1")
-    authRoutes.Use(middleware.AuthMiddleware())
-
-    authRoutes.POST("/claims", handlers.CreateClaim(claimService))
-    authRoutes.GET("/claims", handlers.GetMyClaims(claimService))
-    authRoutes.GET("/claims/all", handlers.GetAllClaims(claimService)) // hanya verifier & approver
-    authRoutes.GET("/claims/:id", handlers.GetClaimByID(claimService))
-    authRoutes.PATCH("/claims/:id", handlers.UpdateClaim(claimService))
-    authRoutes.DELETE("/claims/:id", handlers.DeleteClaim(claimService))
-    authRoutes.PATCH("/claims/:id/submit", handlers.SubmitClaim(claimService))
-    authRoutes.PATCH("/claims/:id/review", handlers.ReviewClaim(claimService))
-    authRoutes.PATCH("/claims/:id/approve", handlers.ApproveClaim(claimService))
-    authRoutes.PATCH("/claims/:id/reject", handlers.RejectClaim(claimService))
+    var oidcService services.OIDCService
+    if config.AppConfig.OIDCEnabled() {
+        oidcService, err = services.NewOIDCService(ctx, config.AppConfig, userRepo)
+        if err != nil {
+            log.Fatal("Cannot initialise OIDC provider:", err)
+        }
+    }
+    handlers.RegisterRoutes(r, handlers.Services{
+        Auth:          authService,
+        OIDC:          oidcService,
+        Policy:        policyService,
+        Tenants:       tenantService,
+        Audit:         auditService,
+        Idempotency:   idempotencyService,
+        Claims:        claimService,
+        Import:        importService,
+        Export:        exportService,
+        Comments:      commentService,
+        Documents:     documentService,
+        Notifications: notificationService,
+        Queue:         queueService,
+        Delegations:   delegationService,
+        SLA:           slaService,
+        Reports:       reportService,
+        Rules:         ruleService,
+        Scheduler:     schedulerService,
+    })
 
     r.Run(":" + config.AppConfig.Port)
 }
\ No newline at end of file
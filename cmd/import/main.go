@@ -0,0 +1,88 @@
+// Command import loads a CSV or NDJSON claim backlog into a tenant, e.g.
+//
+//    go run ./cmd/import -tenant acme -as admin -file legacy.csv -dry-run
+package main
+
+import (
+    "context"
+    "encoding/json"
+    "flag"
+    "insurance-claims-api/internal/config"
+    "insurance-claims-api/internal/models"
+    "insurance-claims-api/internal/repositories"
+    "insurance-claims-api/internal/services"
+    "insurance-claims-api/internal/utils"
+    "log"
+    "os"
+    "path/filepath"
+    "strings"
+
+    "go.mongodb.org/mongo-driver/mongo"
+    "go.mongodb.org/mongo-driver/mongo/options"
+)
+
+func main() {
+    tenant := flag.String("tenant", "", "tenant to import into (default DEFAULT_TENANT)")
+    as := flag.String("as", "", "username of the importing user; needs claim:import")
+    file := flag.String("file", "", "CSV or NDJSON file")
+    format := flag.String("format", "", "csv or ndjson (default from the file extension)")
+    dryRun := flag.Bool("dry-run", false, "validate only, write nothing")
+    flag.Parse()
+    if *as == "" || *file == "" {
+        flag.Usage()
+        os.Exit(2)
+    }
+
+    config.LoadConfig()
+    if err := utils.RegisterValidations(config.AppConfig.PolicyNumberPattern); err != nil {
+        log.Fatal(err)
+    }
+    if *tenant == "" {
+        *tenant = config.AppConfig.DefaultTenant
+    }
+    if *format == "" {
+        *format = strings.TrimPrefix(filepath.Ext(*file), ".")
+    }
+
+    client, err := mongo.Connect(context.TODO(), options.Client().ApplyURI(config.AppConfig.MongoURI))
+    if err != nil {
+        log.Fatal(err)
+    }
+    defer client.Disconnect(context.TODO())
+
+    dbs := repositories.NewTenantDatabases(client, config.AppConfig.TenantDBMode)
+    userRepo := repositories.NewUserRepository(dbs)
+    claimRepo := repositories.NewClaimRepository(dbs)
+    policyService := services.NewPolicyService(repositories.NewRoleRepository(dbs), userRepo, repositories.NewDelegationRepository(dbs))
+    importService := services.NewImportService(claimRepo, repositories.NewCounterRepository(dbs), userRepo)
+
+    user, err := userRepo.FindByUsername(*tenant, *as)
+    if err != nil {
+        log.Fatal("Unknown user ", *as)
+    }
+    actor, err := policyService.Actor(*tenant, user.ID, user.Role)
+    if err != nil {
+        log.Fatal(err)
+    }
+    if !actor.Can(models.PermClaimImport) {
+        log.Fatal(*as, " lacks ", models.PermClaimImport)
+    }
+
+    f, err := os.Open(*file)
+    if err != nil {
+        log.Fatal(err)
+    }
+    defer f.Close()
+
+    report, err := importService.Import(actor, *format, f, *dryRun)
+    if err != nil {
+        log.Fatal(err)
+    }
+    enc := json.NewEncoder(os.Stdout)
+    enc.SetIndent("", "  ")
+    enc.Encode(report)
+    if report.Invalid > 0 {
+        log.Printf("%d of %d rows invalid", report.Invalid, report.Total)
+        os.Exit(1)
+    }
+}
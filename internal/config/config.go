@@ -2,6 +2,9 @@ package config
 
 import (
     "log"
+    "strconv"
+    "strings"
+    "time"
 
     "github.com/joho/godotenv"
     "os"
@@ -11,6 +14,60 @@ type Config struct {
     MongoURI  string
     JWTSecret string
     Port      string
+
+    DefaultTenant string
+    TenantDBMode  string
+
+    // Admins of PlatformTenant provision other tenants; empty disables it.
+    PlatformTenant string
+
+    AssignmentStrategy string
+    QueueLockTTL       time.Duration
+
+    SLATimezone string
+
+    SLASchedule         string
+    DraftExpiry         time.Duration
+    DraftExpirySchedule string
+    LockSweepSchedule   string
+
+    ReportDigestSchedule string
+    ReportDigestDays     int
+
+    FraudReviewThreshold  float64
+    FraudAmountThresholds []float64
+    FraudVelocityWindow   time.Duration
+    FraudInceptionWindow  time.Duration
+
+    DuplicateHardBlock bool
+
+    AppealWindow time.Duration
+
+    ExportSyncLimit int64
+
+    DocumentLanguage string
+
+    IdempotencyTTL     time.Duration
+    IdempotencyMaxBody int64
+
+    PolicyNumberPattern string
+
+    SMTPHost              string
+    SMTPPort              int
+    SMTPUsername          string
+    SMTPPassword          string
+    SMTPFrom              string
+    EmailDispatchSchedule string
+    EmailMaxAttempts      int
+
+    OIDCIssuer         string
+    OIDCClientID       string
+    OIDCClientSecret   string
+    OIDCRedirectURL    string
+    OIDCGroupsClaim    string
+    OIDCVerifierGroups []string
+    OIDCApproverGroups []string
+    OIDCTenant         string
 }
 
 var AppConfig Config
@@ -24,6 +81,42 @@ func LoadConfig() {
         MongoURI:  os.Getenv("MONGODB_URI"),
         JWTSecret: os.Getenv("JWT_SECRET"),
         Port:      os.Getenv("PORT"),
+
+        DefaultTenant: os.Getenv("DEFAULT_TENANT"),
+
+        PlatformTenant: os.Getenv("PLATFORM_TENANT"),
+        TenantDBMode:   os.Getenv("TENANT_DB_MODE"),
+
+        AssignmentStrategy: os.Getenv("ASSIGNMENT_STRATEGY"),
+
+        SLATimezone: os.Getenv("SLA_TIMEZONE"),
+
+        SLASchedule:         os.Getenv("SLA_SCHEDULE"),
+        DraftExpirySchedule: os.Getenv("DRAFT_EXPIRY_SCHEDULE"),
+        LockSweepSchedule:   os.Getenv("LOCK_SWEEP_SCHEDULE"),
+
+        ReportDigestSchedule: os.Getenv("REPORT_DIGEST_SCHEDULE"),
+
+        DuplicateHardBlock: os.Getenv("DUPLICATE_HARD_BLOCK") == "true",
+
+        DocumentLanguage: os.Getenv("DOCUMENT_LANGUAGE"),
+
+        PolicyNumberPattern: os.Getenv("POLICY_NUMBER_PATTERN"),
+
+        SMTPHost:              os.Getenv("SMTP_HOST"),
+        SMTPUsername:          os.Getenv("SMTP_USERNAME"),
+        SMTPPassword:          os.Getenv("SMTP_PASSWORD"),
+        SMTPFrom:              os.Getenv("SMTP_FROM"),
+        EmailDispatchSchedule: os.Getenv("EMAIL_DISPATCH_SCHEDULE"),
+
+        OIDCIssuer:         os.Getenv("OIDC_ISSUER"),
+        OIDCClientID:       os.Getenv("OIDC_CLIENT_ID"),
+        OIDCClientSecret:   os.Getenv("OIDC_CLIENT_SECRET"),
+        OIDCRedirectURL:    os.Getenv("OIDC_REDIRECT_URL"),
+        OIDCGroupsClaim:    os.Getenv("OIDC_GROUPS_CLAIM"),
+        OIDCVerifierGroups: splitList(os.Getenv("OIDC_VERIFIER_GROUPS")),
+        OIDCApproverGroups: splitList(os.Getenv("OIDC_APPROVER_GROUPS")),
+        OIDCTenant:         os.Getenv("OIDC_TENANT"),
     }
 
     if AppConfig.Port == "" {
@@ -35,4 +128,128 @@ func LoadConfig() {
     if AppConfig.MongoURI == "" {
         log.Fatal("MONGODB_URI required")
     }
-}
\ No newline at end of file
+    if AppConfig.OIDCGroupsClaim == "" {
+        AppConfig.OIDCGroupsClaim = "groups"
+    }
+    if AppConfig.DefaultTenant == "" {
+        AppConfig.DefaultTenant = "default"
+    }
+    if AppConfig.OIDCTenant == "" {
+        AppConfig.OIDCTenant = AppConfig.DefaultTenant
+    }
+    if AppConfig.TenantDBMode == "" {
+        AppConfig.TenantDBMode = "shared"
+    }
+    if AppConfig.TenantDBMode != "shared" && AppConfig.TenantDBMode != "database" {
+        log.Fatal("TENANT_DB_MODE must be shared or database")
+    }
+    switch AppConfig.AssignmentStrategy {
+    case "":
+        AppConfig.AssignmentStrategy = "manual"
+    case "manual", "round_robin", "least_workload":
+    default:
+        log.Fatal("ASSIGNMENT_STRATEGY must be manual, round_robin or least_workload")
+    }
+    AppConfig.QueueLockTTL = durationEnv("QUEUE_LOCK_TTL", 30*time.Minute)
+    AppConfig.FraudReviewThreshold = floatEnv("FRAUD_REVIEW_THRESHOLD", 70)
+    for _, v := range splitList(os.Getenv("FRAUD_AMOUNT_THRESHOLDS")) {
+        t, err := strconv.ParseFloat(v, 64)
+        if err != nil {
+            log.Fatal("FRAUD_AMOUNT_THRESHOLDS must be a comma separated list of amounts")
+        }
+        AppConfig.FraudAmountThresholds = append(AppConfig.FraudAmountThresholds, t)
+    }
+    AppConfig.FraudVelocityWindow = durationEnv("FRAUD_VELOCITY_WINDOW", 30*24*time.Hour)
+    AppConfig.FraudInceptionWindow = durationEnv("FRAUD_INCEPTION_WINDOW", 30*24*time.Hour)
+    AppConfig.AppealWindow = durationEnv("APPEAL_WINDOW", 30*24*time.Hour)
+    AppConfig.DraftExpiry = durationEnv("DRAFT_EXPIRY", 30*24*time.Hour)
+    AppConfig.ExportSyncLimit = intEnv("EXPORT_SYNC_LIMIT", 10000)
+    AppConfig.IdempotencyTTL = durationEnv("IDEMPOTENCY_TTL", 24*time.Hour)
+    AppConfig.IdempotencyMaxBody = intEnv("IDEMPOTENCY_MAX_BODY", 10<<20)
+    if AppConfig.SLASchedule == "" {
+        AppConfig.SLASchedule = "*/15 * * * *"
+    }
+    if AppConfig.DraftExpirySchedule == "" {
+        AppConfig.DraftExpirySchedule = "0 2 * * *"
+    }
+    if AppConfig.LockSweepSchedule == "" {
+        AppConfig.LockSweepSchedule = "*/5 * * * *"
+    }
+    if AppConfig.ReportDigestSchedule == "" {
+        AppConfig.ReportDigestSchedule = "0 7 * * 1"
+    }
+    AppConfig.ReportDigestDays = int(intEnv("REPORT_DIGEST_DAYS", 7))
+    AppConfig.SMTPPort = int(intEnv("SMTP_PORT", 587))
+    AppConfig.EmailMaxAttempts = int(intEnv("EMAIL_MAX_ATTEMPTS", 5))
+    if AppConfig.SMTPFrom == "" {
+        AppConfig.SMTPFrom = "claims@localhost"
+    }
+    if AppConfig.EmailDispatchSchedule == "" {
+        AppConfig.EmailDispatchSchedule = "* * * * *"
+    }
+    switch AppConfig.DocumentLanguage {
+    case "":
+        AppConfig.DocumentLanguage = "id"
+    case "id", "en":
+    default:
+        log.Fatal("DOCUMENT_LANGUAGE must be id or en")
+    }
+    if AppConfig.SLATimezone == "" {
+        AppConfig.SLATimezone = "Asia/Jakarta"
+    }
+    if AppConfig.PolicyNumberPattern == "" {
+        AppConfig.PolicyNumberPattern = `^[A-Z0-9][A-Z0-9/-]{5,29}$`
+    }
+}
+
+// OIDCEnabled reports whether single sign-on has been configured.
+func (c Config) OIDCEnabled() bool {
+    return c.OIDCIssuer != "" && c.OIDCClientID != ""
+}
+
+func durationEnv(name string, def time.Duration) time.Duration {
+    v := os.Getenv(name)
+    if v == "" {
+        return def
+    }
+    d, err := time.ParseDuration(v)
+    if err != nil {
+        log.Fatal(name + " must be a duration such as 30m")
+    }
+    return d
+}
+
+func floatEnv(name string, def float64) float64 {
+    v := os.Getenv(name)
+    if v == "" {
+        return def
+    }
+    f, err := strconv.ParseFloat(v, 64)
+    if err != nil {
+        log.Fatal(name + " must be a number")
+    }
+    return f
+}
+
+func intEnv(name string, def int64) int64 {
+    v := os.Getenv(name)
+    if v == "" {
+        return def
+    }
+    n, err := strconv.ParseInt(v, 10, 64)
+    if err != nil {
+        log.Fatal(name + " must be a whole number")
+    }
+    return n
+}
+
+// splitList parses a comma separated env value, e.g. "claims-verifiers,claims-leads".
+func splitList(v string) []string {
+    var out []string
+    for _, item := range strings.Split(v, ",") {
+        if item = strings.TrimSpace(item); item != "" {
+            out = append(out, item)
+        }
+    }
+    return out
+}
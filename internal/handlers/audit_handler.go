@@ -0,0 +1,46 @@
+package handlers
+
+import (
+    "insurance-claims-api/internal/models"
+    "insurance-claims-api/internal/services"
+    "insurance-claims-api/internal/utils"
+    "net/http"
+
+    "github.com/gin-gonic/gin"
+)
+
+func Impersonate(svc services.AuthService) gin.HandlerFunc {
+    return func(c *gin.Context) {
+        var req models.ImpersonateRequest
+        if err := c.ShouldBindJSON(&req); err != nil {
+            utils.ProblemResponse(c, utils.BindingError(err), http.StatusBadRequest)
+            return
+        }
+        resp, err := svc.Impersonate(currentActor(c), req)
+        if err != nil {
+            utils.ProblemResponse(c, err, http.StatusInternalServerError)
+            return
+        }
+        utils.SuccessResponse(c, resp)
+    }
+}
+
+func ListAuditLogs(svc services.AuditService) gin.HandlerFunc {
+    return func(c *gin.Context) {
+        var filter models.AuditLogFilter
+        if err := c.ShouldBindQuery(&filter); err != nil {
+            utils.ProblemResponse(c, utils.BindingError(err), http.StatusBadRequest)
+            return
+        }
+        page, limit, ok := pageQuery(c)
+        if !ok {
+            return
+        }
+        entries, total, err := svc.List(currentActor(c), filter, page, limit)
+        if err != nil {
+            utils.ProblemResponse(c, err, http.StatusInternalServerError)
+            return
+        }
+        utils.PaginatedResponse(c, entries, total, page, limit)
+    }
+}
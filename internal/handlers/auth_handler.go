@@ -13,16 +13,65 @@ func Login(authService services.AuthService) gin.HandlerFunc {
     return func(c *gin.Context) {
         var req models.LoginRequest
         if err := c.ShouldBindJSON(&req); err != nil {
-            utils.ErrorResponse(c, http.StatusBadRequest, err.Error())
+            utils.ProblemResponse(c, utils.BindingError(err), http.StatusBadRequest)
             return
         }
 
         resp, err := authService.Login(req)
         if err != nil {
-            utils.ErrorResponse(c, http.StatusUnauthorized, err.Error())
+            utils.ProblemResponse(c, err, http.StatusInternalServerError)
             return
         }
 
         utils.SuccessResponse(c, resp)
     }
-}
\ No newline at end of file
+}
+
+var errOIDCState = utils.NewError(utils.KindBadRequest, utils.CodeBadRequest, "invalid state")
+
+const oidcCookiePath = "/api/v1/auth/oidc"
+
+func OIDCLogin(svc services.OIDCService) gin.HandlerFunc {
+    return func(c *gin.Context) {
+        req := svc.NewAuthRequest()
+        secure := c.Request.TLS != nil
+        c.SetSameSite(http.SameSiteLaxMode)
+        c.SetCookie("oidc_state", req.State, 600, oidcCookiePath, "", secure, true)
+        c.SetCookie("oidc_nonce", req.Nonce, 600, oidcCookiePath, "", secure, true)
+        c.SetCookie("oidc_verifier", req.Verifier, 600, oidcCookiePath, "", secure, true)
+        c.Redirect(http.StatusFound, req.URL)
+    }
+}
+
+func OIDCCallback(svc services.OIDCService) gin.HandlerFunc {
+    return func(c *gin.Context) {
+        if idpErr := c.Query("error"); idpErr != "" {
+            utils.ProblemResponse(c, utils.NewError(utils.KindUnauthorized, utils.CodeUnauthorized, idpErr), http.StatusUnauthorized)
+            return
+        }
+        var req models.OIDCCallbackRequest
+        if err := c.ShouldBindQuery(&req); err != nil {
+            utils.ProblemResponse(c, utils.BindingError(err), http.StatusBadRequest)
+            return
+        }
+
+        state, _ := c.Cookie("oidc_state")
+        nonce, _ := c.Cookie("oidc_nonce")
+        verifier, _ := c.Cookie("oidc_verifier")
+        for _, name := range []string{"oidc_state", "oidc_nonce", "oidc_verifier"} {
+            c.SetCookie(name, "", -1, oidcCookiePath, "", c.Request.TLS != nil, true)
+        }
+        if state == "" || state != req.State {
+            utils.ProblemResponse(c, errOIDCState, http.StatusBadRequest)
+            return
+        }
+
+        resp, err := svc.Callback(c.Request.Context(), req.Code, verifier, nonce)
+        if err != nil {
+            utils.ProblemResponse(c, err, http.StatusInternalServerError)
+            return
+        }
+
+        utils.SuccessResponse(c, resp)
+    }
+}
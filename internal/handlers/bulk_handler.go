@@ -0,0 +1,28 @@
+package handlers
+
+import (
+    "insurance-claims-api/internal/models"
+    "insurance-claims-api/internal/services"
+    "insurance-claims-api/internal/utils"
+    "net/http"
+
+    "github.com/gin-gonic/gin"
+)
+
+// BulkClaimAction handles POST /claims/bulk/{review,approve,reject}. Per-item
+// failures still return 200; see the items of the result.
+func BulkClaimAction(svc services.ClaimService, action string) gin.HandlerFunc {
+    return func(c *gin.Context) {
+        var req models.BulkActionRequest
+        if err := c.ShouldBindJSON(&req); err != nil {
+            utils.ProblemResponse(c, utils.BindingError(err), http.StatusBadRequest)
+            return
+        }
+        result, err := svc.BulkAction(currentActor(c), action, req)
+        if err != nil {
+            utils.ProblemResponse(c, err, http.StatusInternalServerError)
+            return
+        }
+        utils.SuccessResponse(c, result)
+    }
+}
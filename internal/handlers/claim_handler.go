@@ -1,27 +1,89 @@
 package handlers
 
 import (
+    "errors"
+    "fmt"
     "insurance-claims-api/internal/models"
     "insurance-claims-api/internal/services"
     "insurance-claims-api/internal/utils"
+    "io"
     "net/http"
     "strconv"
+    "strings"
 
     "github.com/gin-gonic/gin"
     "go.mongodb.org/mongo-driver/bson/primitive"
 )
 
+func currentActor(c *gin.Context) models.Actor {
+    return c.MustGet("actor").(models.Actor)
+}
+
+var errInvalidID = utils.NewError(utils.KindBadRequest, "invalid_id", "invalid id")
+
+// objectIDParam parses the path parameter name as an ObjectID. On failure
+// the response has been written.
+func objectIDParam(c *gin.Context, name string) (primitive.ObjectID, bool) {
+    id, err := primitive.ObjectIDFromHex(c.Param(name))
+    if err != nil {
+        utils.ProblemResponse(c, errInvalidID.WithMessage(name+" is not a valid id"), http.StatusBadRequest)
+        return primitive.NilObjectID, false
+    }
+    return id, true
+}
+
+var (
+    errPatchMediaType = utils.NewError(utils.KindUnsupportedMediaType, utils.CodeUnsupportedMediaType, "use "+models.MergePatchContentType+" or "+models.JSONPatchContentType)
+    errFraudDecision  = utils.NewError(utils.KindBadRequest, utils.CodeBadRequest, "decision must be clear or confirm")
+)
+
+// pageQuery reads and validates page and limit. On failure the response has
+// been written.
+func pageQuery(c *gin.Context) (int, int, bool) {
+    var q models.PageQuery
+    if err := c.ShouldBindQuery(&q); err != nil {
+        utils.ProblemResponse(c, utils.BindingError(err), http.StatusBadRequest)
+        return 0, 0, false
+    }
+    return q.Page, q.Limit, true
+}
+
+var errIfMatchRequired = utils.NewError(utils.KindPreconditionRequired, "if_match_required", "If-Match header with the claim ETag is required")
+
+// ifMatch reads the claim version a change is conditional on from If-Match.
+// Without the header any version matches, unless it is required. On failure
+// the response has been written.
+func ifMatch(c *gin.Context, required bool) (int64, bool) {
+    tag := c.GetHeader("If-Match")
+    if tag == "" || tag == "*" {
+        if required {
+            utils.ProblemResponse(c, errIfMatchRequired, http.StatusPreconditionRequired)
+            return 0, false
+        }
+        return models.AnyVersion, true
+    }
+    version, err := strconv.ParseInt(strings.Trim(strings.TrimPrefix(tag, "W/"), `"`), 10, 64)
+    if err != nil || version < 0 {
+        utils.ProblemResponse(c, services.ErrVersionMismatch, http.StatusPreconditionFailed)
+        return 0, false
+    }
+    return version, true
+}
+
+func claimETag(claim *models.Claim) string {
+    return fmt.Sprintf("%q", strconv.FormatInt(claim.Version, 10))
+}
+
 func CreateClaim(svc services.ClaimService) gin.HandlerFunc {
     return func(c *gin.Context) {
-        userID := c.MustGet("user_id").(primitive.ObjectID)
         var req models.CreateClaimRequest
         if err := c.ShouldBindJSON(&req); err != nil {
-            utils.ErrorResponse(c, http.StatusBadRequest, err.Error())
+            utils.ProblemResponse(c, utils.BindingError(err), http.StatusBadRequest)
             return
         }
-        claim, err := svc.CreateClaim(userID, req)
+        claim, err := svc.CreateClaim(currentActor(c), req)
         if err != nil {
-            utils.ErrorResponse(c, http.StatusInternalServerError, err.Error())
+            utils.ProblemResponse(c, err, http.StatusInternalServerError)
             return
         }
         utils.SuccessResponse(c, claim)
@@ -30,23 +92,33 @@ func CreateClaim(svc services.ClaimService) gin.HandlerFunc {
 
 func GetMyClaims(svc services.ClaimService) gin.HandlerFunc {
     return func(c *gin.Context) {
-        userID := c.MustGet("user_id").(primitive.ObjectID)
-        page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
-        limit, _ := strconv.Atoi(c.DefaultQuery("limit", "10"))
-        claims, total, _ := svc.GetMyClaims(userID, page, limit)
+        page, limit, ok := pageQuery(c)
+        if !ok {
+            return
+        }
+        claims, total, err := svc.GetMyClaims(currentActor(c), page, limit)
+        if err != nil {
+            utils.ProblemResponse(c, err, http.StatusInternalServerError)
+            return
+        }
         utils.PaginatedResponse(c, claims, total, page, limit)
     }
 }
 
 func GetAllClaims(svc services.ClaimService) gin.HandlerFunc {
     return func(c *gin.Context) {
-        c.Header("X-Role-Required", "verifier or approver")
-        role := c.GetString("role")
-        page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
-        limit, _ := strconv.Atoi(c.DefaultQuery("limit", "10"))
-        claims, total, err := svc.GetAllClaims(role, page, limit)
+        page, limit, ok := pageQuery(c)
+        if !ok {
+            return
+        }
+        var query models.ClaimListQuery
+        if err := c.ShouldBindQuery(&query); err != nil {
+            utils.ProblemResponse(c, utils.BindingError(err), http.StatusBadRequest)
+            return
+        }
+        claims, total, err := svc.GetAllClaims(currentActor(c), query, page, limit)
         if err != nil {
-            utils.ErrorResponse(c, http.StatusForbidden, err.Error())
+            utils.ProblemResponse(c, err, http.StatusInternalServerError)
             return
         }
         utils.PaginatedResponse(c, claims, total, page, limit)
@@ -55,41 +127,61 @@ func GetAllClaims(svc services.ClaimService) gin.HandlerFunc {
 
 func GetClaimByID(svc services.ClaimService) gin.HandlerFunc {
     return func(c *gin.Context) {
-        userID := c.MustGet("user_id").(primitive.ObjectID)
-        role := c.GetString("role")
-        id, _ := primitive.ObjectIDFromHex(c.Param("id"))
-        claim, err := svc.GetClaimByID(userID, role, id)
+        var claim *models.Claim
+        id, err := primitive.ObjectIDFromHex(c.Param("id"))
+        if err == nil {
+            claim, err = svc.GetClaimByID(currentActor(c), id)
+        } else {
+            claim, err = svc.GetClaimByReference(currentActor(c), c.Param("id"))
+        }
         if err != nil {
-            utils.ErrorResponse(c, http.StatusNotFound, "claim not found")
+            utils.ProblemResponse(c, err, http.StatusInternalServerError)
             return
         }
+        c.Header("ETag", claimETag(claim))
         utils.SuccessResponse(c, claim)
     }
 }
 
 func UpdateClaim(svc services.ClaimService) gin.HandlerFunc {
     return func(c *gin.Context) {
-        userID := c.MustGet("user_id").(primitive.ObjectID)
-        id, _ := primitive.ObjectIDFromHex(c.Param("id"))
-        var req models.UpdateClaimRequest
-        if err := c.ShouldBindJSON(&req); err != nil {
-            utils.ErrorResponse(c, http.StatusBadRequest, err.Error())
+        id, ok := objectIDParam(c, "id")
+        if !ok {
             return
         }
-        if err := svc.UpdateClaim(userID, id, req); err != nil {
-            utils.ErrorResponse(c, http.StatusBadRequest, err.Error())
+        version, ok := ifMatch(c, true)
+        if !ok {
             return
         }
-        utils.SuccessResponse(c, map[string]string{"message": "claim updated"})
+        switch c.ContentType() {
+        case models.MergePatchContentType, models.JSONPatchContentType, "application/json":
+        default:
+            utils.ProblemResponse(c, errPatchMediaType, http.StatusUnsupportedMediaType)
+            return
+        }
+        patch, err := io.ReadAll(c.Request.Body)
+        if err != nil {
+            utils.ProblemResponse(c, err, http.StatusBadRequest)
+            return
+        }
+        claim, err := svc.UpdateClaim(currentActor(c), id, version, c.ContentType(), patch)
+        if err != nil {
+            utils.ProblemResponse(c, err, http.StatusInternalServerError)
+            return
+        }
+        c.Header("ETag", claimETag(claim))
+        utils.SuccessResponse(c, claim)
     }
 }
 
 func DeleteClaim(svc services.ClaimService) gin.HandlerFunc {
     return func(c *gin.Context) {
-        userID := c.MustGet("user_id").(primitive.ObjectID)
-        id, _ := primitive.ObjectIDFromHex(c.Param("id"))
-        if err := svc.DeleteClaim(userID, id); err != nil {
-            utils.ErrorResponse(c, http.StatusBadRequest, err.Error())
+        id, ok := objectIDParam(c, "id")
+        if !ok {
+            return
+        }
+        if err := svc.DeleteClaim(currentActor(c), id); err != nil {
+            utils.ProblemResponse(c, err, http.StatusInternalServerError)
             return
         }
         utils.SuccessResponse(c, map[string]string{"message": "claim deleted"})
@@ -98,10 +190,22 @@ func DeleteClaim(svc services.ClaimService) gin.HandlerFunc {
 
 func SubmitClaim(svc services.ClaimService) gin.HandlerFunc {
     return func(c *gin.Context) {
-        userID := c.MustGet("user_id").(primitive.ObjectID)
-        id, _ := primitive.ObjectIDFromHex(c.Param("id"))
-        if err := svc.SubmitClaim(userID, id); err != nil {
-            utils.ErrorResponse(c, http.StatusBadRequest, err.Error())
+        id, ok := objectIDParam(c, "id")
+        if !ok {
+            return
+        }
+        version, ok := ifMatch(c, false)
+        if !ok {
+            return
+        }
+        err := svc.SubmitClaim(currentActor(c), id, version, c.PostForm("duplicate_justification"))
+        var dup *services.DuplicateClaimError
+        if errors.As(err, &dup) {
+            utils.ProblemResponse(c, &utils.Error{Kind: utils.KindConflict, Code: "duplicate_claim", Message: err.Error(), Data: dup.Matches}, http.StatusConflict)
+            return
+        }
+        if err != nil {
+            utils.ProblemResponse(c, err, http.StatusInternalServerError)
             return
         }
         utils.SuccessResponse(c, map[string]string{"message": "claim submitted"})
@@ -110,16 +214,17 @@ func SubmitClaim(svc services.ClaimService) gin.HandlerFunc {
 
 func ReviewClaim(svc services.ClaimService) gin.HandlerFunc {
     return func(c *gin.Context) {
-        c.Header("X-Role-Required", "verifier")
-        if c.GetString("role") != "verifier" {
-            utils.ErrorResponse(c, http.StatusForbidden, "only verifier")
+        id, ok := objectIDParam(c, "id")
+        if !ok {
+            return
+        }
+        version, ok := ifMatch(c, false)
+        if !ok {
             return
         }
-        verifierID := c.MustGet("user_id").(primitive.ObjectID)
-        id, _ := primitive.ObjectIDFromHex(c.Param("id"))
         note := c.PostForm("note")
-        if err := svc.ReviewClaim(verifierID, id, note); err != nil {
-            utils.ErrorResponse(c, http.StatusBadRequest, err.Error())
+        if err := svc.ReviewClaim(currentActor(c), id, version, note); err != nil {
+            utils.ProblemResponse(c, err, http.StatusInternalServerError)
             return
         }
         utils.SuccessResponse(c, map[string]string{"message": "claim reviewed"})
@@ -128,15 +233,16 @@ func ReviewClaim(svc services.ClaimService) gin.HandlerFunc {
 
 func ApproveClaim(svc services.ClaimService) gin.HandlerFunc {
     return func(c *gin.Context) {
-        c.Header("X-Role-Required", "approver")
-        if c.GetString("role") != "approver" {
-            utils.ErrorResponse(c, http.StatusForbidden, "only approver")
+        id, ok := objectIDParam(c, "id")
+        if !ok {
+            return
+        }
+        version, ok := ifMatch(c, false)
+        if !ok {
             return
         }
-        approverID := c.MustGet("user_id").(primitive.ObjectID)
-        id, _ := primitive.ObjectIDFromHex(c.Param("id"))
-        if err := svc.ApproveClaim(approverID, id); err != nil {
-            utils.ErrorResponse(c, http.StatusBadRequest, err.Error())
+        if err := svc.ApproveClaim(currentActor(c), id, version); err != nil {
+            utils.ProblemResponse(c, err, http.StatusInternalServerError)
             return
         }
         utils.SuccessResponse(c, map[string]string{"message": "claim approved"})
@@ -145,18 +251,103 @@ func ApproveClaim(svc services.ClaimService) gin.HandlerFunc {
 
 func RejectClaim(svc services.ClaimService) gin.HandlerFunc {
     return func(c *gin.Context) {
-        c.Header("X-Role-Required", "approver")
-        if c.GetString("role") != "approver" {
-            utils.ErrorResponse(c, http.StatusForbidden, "only approver")
+        id, ok := objectIDParam(c, "id")
+        if !ok {
+            return
+        }
+        version, ok := ifMatch(c, false)
+        if !ok {
             return
         }
-        approverID := c.MustGet("user_id").(primitive.ObjectID)
-        id, _ := primitive.ObjectIDFromHex(c.Param("id"))
         reason := c.PostForm("reason")
-        if err := svc.RejectClaim(approverID, id, reason); err != nil {
-            utils.ErrorResponse(c, http.StatusBadRequest, err.Error())
+        if err := svc.RejectClaim(currentActor(c), id, version, reason); err != nil {
+            utils.ProblemResponse(c, err, http.StatusInternalServerError)
             return
         }
         utils.SuccessResponse(c, map[string]string{"message": "claim rejected"})
     }
-}
\ No newline at end of file
+}
+
+func ResolveFraudReview(svc services.ClaimService) gin.HandlerFunc {
+    return func(c *gin.Context) {
+        id, ok := objectIDParam(c, "id")
+        if !ok {
+            return
+        }
+        version, ok := ifMatch(c, false)
+        if !ok {
+            return
+        }
+        decision := c.PostForm("decision")
+        if decision != "clear" && decision != "confirm" {
+            utils.ProblemResponse(c, errFraudDecision, http.StatusBadRequest)
+            return
+        }
+        if err := svc.ResolveFraudReview(currentActor(c), id, version, decision == "clear", c.PostForm("note")); err != nil {
+            utils.ProblemResponse(c, err, http.StatusInternalServerError)
+            return
+        }
+        utils.SuccessResponse(c, map[string]string{"message": "fraud review resolved"})
+    }
+}
+
+func WithdrawClaim(svc services.ClaimService) gin.HandlerFunc {
+    return func(c *gin.Context) {
+        id, ok := objectIDParam(c, "id")
+        if !ok {
+            return
+        }
+        version, ok := ifMatch(c, false)
+        if !ok {
+            return
+        }
+        reason := c.PostForm("reason")
+        if err := svc.WithdrawClaim(currentActor(c), id, version, reason); err != nil {
+            utils.ProblemResponse(c, err, http.StatusInternalServerError)
+            return
+        }
+        utils.SuccessResponse(c, map[string]string{"message": "claim withdrawn"})
+    }
+}
+
+func AppealClaim(svc services.ClaimService) gin.HandlerFunc {
+    return func(c *gin.Context) {
+        id, ok := objectIDParam(c, "id")
+        if !ok {
+            return
+        }
+        version, ok := ifMatch(c, false)
+        if !ok {
+            return
+        }
+        var req models.AppealClaimRequest
+        if err := c.ShouldBindJSON(&req); err != nil {
+            utils.ProblemResponse(c, utils.BindingError(err), http.StatusBadRequest)
+            return
+        }
+        if err := svc.AppealClaim(currentActor(c), id, version, req); err != nil {
+            utils.ProblemResponse(c, err, http.StatusInternalServerError)
+            return
+        }
+        utils.SuccessResponse(c, map[string]string{"message": "appeal filed"})
+    }
+}
+
+func ReopenClaim(svc services.ClaimService) gin.HandlerFunc {
+    return func(c *gin.Context) {
+        id, ok := objectIDParam(c, "id")
+        if !ok {
+            return
+        }
+        version, ok := ifMatch(c, false)
+        if !ok {
+            return
+        }
+        reason := c.PostForm("reason")
+        if err := svc.ReopenClaim(currentActor(c), id, version, reason); err != nil {
+            utils.ProblemResponse(c, err, http.StatusInternalServerError)
+            return
+        }
+        utils.SuccessResponse(c, map[string]string{"message": "claim reopened"})
+    }
+}
@@ -0,0 +1,137 @@
+package handlers
+
+import (
+    "insurance-claims-api/internal/models"
+    "insurance-claims-api/internal/services"
+    "insurance-claims-api/internal/utils"
+    "net/http"
+
+    "github.com/gin-gonic/gin"
+)
+
+func ListComments(svc services.CommentService) gin.HandlerFunc {
+    return func(c *gin.Context) {
+        id, ok := objectIDParam(c, "id")
+        if !ok {
+            return
+        }
+        comments, err := svc.List(currentActor(c), id)
+        if err != nil {
+            utils.ProblemResponse(c, err, http.StatusInternalServerError)
+            return
+        }
+        utils.SuccessResponse(c, comments)
+    }
+}
+
+func CreateComment(svc services.CommentService) gin.HandlerFunc {
+    return func(c *gin.Context) {
+        id, ok := objectIDParam(c, "id")
+        if !ok {
+            return
+        }
+        var req models.CreateCommentRequest
+        if err := c.ShouldBindJSON(&req); err != nil {
+            utils.ProblemResponse(c, utils.BindingError(err), http.StatusBadRequest)
+            return
+        }
+        comment, err := svc.Create(currentActor(c), id, req)
+        if err != nil {
+            utils.ProblemResponse(c, err, http.StatusInternalServerError)
+            return
+        }
+        utils.SuccessResponse(c, comment)
+    }
+}
+
+func UpdateComment(svc services.CommentService) gin.HandlerFunc {
+    return func(c *gin.Context) {
+        id, ok := objectIDParam(c, "id")
+        if !ok {
+            return
+        }
+        commentID, ok := objectIDParam(c, "comment_id")
+        if !ok {
+            return
+        }
+        var req models.UpdateCommentRequest
+        if err := c.ShouldBindJSON(&req); err != nil {
+            utils.ProblemResponse(c, utils.BindingError(err), http.StatusBadRequest)
+            return
+        }
+        comment, err := svc.Update(currentActor(c), id, commentID, req)
+        if err != nil {
+            utils.ProblemResponse(c, err, http.StatusInternalServerError)
+            return
+        }
+        utils.SuccessResponse(c, comment)
+    }
+}
+
+func ListNotifications(svc services.NotificationService) gin.HandlerFunc {
+    return func(c *gin.Context) {
+        page, limit, ok := pageQuery(c)
+        if !ok {
+            return
+        }
+        unread := c.Query("unread") == "true"
+        items, total, err := svc.List(currentActor(c), unread, page, limit)
+        if err != nil {
+            utils.ProblemResponse(c, err, http.StatusInternalServerError)
+            return
+        }
+        utils.PaginatedResponse(c, items, total, page, limit)
+    }
+}
+
+func UnreadNotificationCount(svc services.NotificationService) gin.HandlerFunc {
+    return func(c *gin.Context) {
+        count, err := svc.UnreadCount(currentActor(c))
+        if err != nil {
+            utils.ProblemResponse(c, err, http.StatusInternalServerError)
+            return
+        }
+        utils.SuccessResponse(c, map[string]int64{"unread": count})
+    }
+}
+
+func GetNotificationPreferences(svc services.NotificationService) gin.HandlerFunc {
+    return func(c *gin.Context) {
+        prefs, err := svc.Preferences(currentActor(c))
+        if err != nil {
+            utils.ProblemResponse(c, err, http.StatusInternalServerError)
+            return
+        }
+        utils.SuccessResponse(c, prefs)
+    }
+}
+
+func UpdateNotificationPreferences(svc services.NotificationService) gin.HandlerFunc {
+    return func(c *gin.Context) {
+        var req models.UpdateNotificationPreferencesRequest
+        if err := c.ShouldBindJSON(&req); err != nil {
+            utils.ProblemResponse(c, utils.BindingError(err), http.StatusBadRequest)
+            return
+        }
+        prefs, err := svc.UpdatePreferences(currentActor(c), req)
+        if err != nil {
+            utils.ProblemResponse(c, err, http.StatusInternalServerError)
+            return
+        }
+        utils.SuccessResponse(c, prefs)
+    }
+}
+
+func MarkNotificationRead(svc services.NotificationService) gin.HandlerFunc {
+    return func(c *gin.Context) {
+        id, ok := objectIDParam(c, "id")
+        if !ok {
+            return
+        }
+        if err := svc.MarkRead(currentActor(c), id); err != nil {
+            utils.ProblemResponse(c, err, http.StatusInternalServerError)
+            return
+        }
+        utils.SuccessResponse(c, map[string]string{"message": "notification marked as read"})
+    }
+}
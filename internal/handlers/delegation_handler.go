@@ -0,0 +1,51 @@
+package handlers
+
+import (
+    "insurance-claims-api/internal/models"
+    "insurance-claims-api/internal/services"
+    "insurance-claims-api/internal/utils"
+    "net/http"
+
+    "github.com/gin-gonic/gin"
+)
+
+func ListDelegations(svc services.DelegationService) gin.HandlerFunc {
+    return func(c *gin.Context) {
+        delegations, err := svc.List(currentActor(c))
+        if err != nil {
+            utils.ProblemResponse(c, err, http.StatusInternalServerError)
+            return
+        }
+        utils.SuccessResponse(c, delegations)
+    }
+}
+
+func CreateDelegation(svc services.DelegationService) gin.HandlerFunc {
+    return func(c *gin.Context) {
+        var req models.CreateDelegationRequest
+        if err := c.ShouldBindJSON(&req); err != nil {
+            utils.ProblemResponse(c, utils.BindingError(err), http.StatusBadRequest)
+            return
+        }
+        delegation, err := svc.Create(currentActor(c), req)
+        if err != nil {
+            utils.ProblemResponse(c, err, http.StatusInternalServerError)
+            return
+        }
+        utils.SuccessResponse(c, delegation)
+    }
+}
+
+func RevokeDelegation(svc services.DelegationService) gin.HandlerFunc {
+    return func(c *gin.Context) {
+        id, ok := objectIDParam(c, "id")
+        if !ok {
+            return
+        }
+        if err := svc.Revoke(currentActor(c), id); err != nil {
+            utils.ProblemResponse(c, err, http.StatusInternalServerError)
+            return
+        }
+        utils.SuccessResponse(c, map[string]string{"message": "delegation revoked"})
+    }
+}
@@ -0,0 +1,56 @@
+package handlers
+
+import (
+    "insurance-claims-api/internal/models"
+    "insurance-claims-api/internal/services"
+    "insurance-claims-api/internal/utils"
+    "net/http"
+
+    "github.com/gin-gonic/gin"
+)
+
+func ListDocumentTemplates(svc services.DocumentService) gin.HandlerFunc {
+    return func(c *gin.Context) {
+        utils.SuccessResponse(c, svc.Templates())
+    }
+}
+
+func GenerateDocument(svc services.DocumentService) gin.HandlerFunc {
+    return func(c *gin.Context) {
+        id, ok := objectIDParam(c, "id")
+        if !ok {
+            return
+        }
+        var req models.GenerateDocumentRequest
+        if err := c.ShouldBindJSON(&req); err != nil {
+            utils.ProblemResponse(c, utils.BindingError(err), http.StatusBadRequest)
+            return
+        }
+        doc, err := svc.Generate(currentActor(c), id, req)
+        if err != nil {
+            utils.ProblemResponse(c, err, http.StatusInternalServerError)
+            return
+        }
+        c.JSON(http.StatusCreated, utils.Response{Success: true, Data: doc})
+    }
+}
+
+func DownloadDocument(svc services.DocumentService) gin.HandlerFunc {
+    return func(c *gin.Context) {
+        id, ok := objectIDParam(c, "id")
+        if !ok {
+            return
+        }
+        docID, ok := objectIDParam(c, "document_id")
+        if !ok {
+            return
+        }
+        doc, data, err := svc.Download(currentActor(c), id, docID)
+        if err != nil {
+            utils.ProblemResponse(c, err, http.StatusInternalServerError)
+            return
+        }
+        c.Header("Content-Disposition", `attachment; filename="`+doc.FileName+`"`)
+        c.Data(http.StatusOK, "application/pdf", data)
+    }
+}
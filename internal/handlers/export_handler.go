@@ -0,0 +1,109 @@
+package handlers
+
+import (
+    "errors"
+    "fmt"
+    "insurance-claims-api/internal/config"
+    "insurance-claims-api/internal/models"
+    "insurance-claims-api/internal/services"
+    "insurance-claims-api/internal/utils"
+    "log"
+    "net/http"
+    "time"
+
+    "github.com/gin-gonic/gin"
+)
+
+// ExportClaims streams the export in the response, or queues an export job
+// (202) when more than EXPORT_SYNC_LIMIT rows match.
+func ExportClaims(svc services.ExportService) gin.HandlerFunc {
+    return func(c *gin.Context) {
+        var req models.ExportRequest
+        if err := c.ShouldBindQuery(&req); err != nil {
+            utils.ProblemResponse(c, utils.BindingError(err), http.StatusBadRequest)
+            return
+        }
+        actor := currentActor(c)
+        count, err := svc.Count(actor, req)
+        if err != nil {
+            utils.ProblemResponse(c, err, http.StatusInternalServerError)
+            return
+        }
+        if count > config.AppConfig.ExportSyncLimit {
+            job, err := svc.StartJob(actor, req)
+            if err != nil {
+                utils.ProblemResponse(c, err, http.StatusInternalServerError)
+                return
+            }
+            c.JSON(http.StatusAccepted, gin.H{"success": true, "data": job})
+            return
+        }
+
+        ext, contentType := services.ExportFormat(req)
+        c.Header("Content-Type", contentType)
+        c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="claims-%s.%s"`, time.Now().Format("20060102-150405"), ext))
+        if _, err := svc.Stream(actor, req, c.Writer); err != nil {
+            if !c.Writer.Written() {
+                utils.ProblemResponse(c, err, http.StatusInternalServerError)
+                return
+            }
+            log.Println("export aborted:", err)
+        }
+    }
+}
+
+func CreateExportJob(svc services.ExportService) gin.HandlerFunc {
+    return func(c *gin.Context) {
+        var req models.ExportRequest
+        if err := c.ShouldBind(&req); err != nil {
+            utils.ProblemResponse(c, utils.BindingError(err), http.StatusBadRequest)
+            return
+        }
+        job, err := svc.StartJob(currentActor(c), req)
+        if err != nil {
+            utils.ProblemResponse(c, err, http.StatusInternalServerError)
+            return
+        }
+        c.JSON(http.StatusAccepted, gin.H{"success": true, "data": job})
+    }
+}
+
+func GetExportJob(svc services.ExportService) gin.HandlerFunc {
+    return func(c *gin.Context) {
+        id, ok := objectIDParam(c, "id")
+        if !ok {
+            return
+        }
+        job, err := svc.GetJob(currentActor(c), id)
+        if err != nil {
+            utils.ProblemResponse(c, err, http.StatusInternalServerError)
+            return
+        }
+        utils.SuccessResponse(c, job)
+    }
+}
+
+func DownloadExport(svc services.ExportService) gin.HandlerFunc {
+    return func(c *gin.Context) {
+        id, ok := objectIDParam(c, "id")
+        if !ok {
+            return
+        }
+        actor := currentActor(c)
+        job, err := svc.GetJob(actor, id)
+        if err != nil {
+            utils.ProblemResponse(c, err, http.StatusInternalServerError)
+            return
+        }
+        if job.Status != models.ExportDone {
+            utils.ProblemResponse(c, services.ErrExportNotReady.WithMessage("export is "+job.Status), http.StatusConflict)
+            return
+        }
+        _, contentType := services.ExportFormat(job.Request)
+        c.Header("Content-Type", contentType)
+        c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="%s"`, job.FileName))
+        if err := svc.Download(actor, id, c.Writer); err != nil && !errors.Is(err, services.ErrExportNotFound) {
+            log.Println("export download failed:", err)
+        }
+    }
+}
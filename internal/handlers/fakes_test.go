@@ -0,0 +1,420 @@
+package handlers
+
+import (
+    "context"
+    "insurance-claims-api/internal/models"
+    "insurance-claims-api/internal/services"
+    "io"
+
+    "github.com/gin-gonic/gin"
+    "go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// The fakes below back every route: each call a handler makes returns err,
+// or a zero result when err is nil. The calls the auth middleware makes
+// always succeed. They embed the service interfaces, so background methods
+// no route reaches panic.
+
+// fakePolicy grants a role its default bindings; the role "root" holds every
+// permission, platform ones included.
+type fakePolicy struct {
+    services.PolicyService
+    err error
+}
+
+func (fakePolicy) Actor(tenantID string, userID primitive.ObjectID, role string) (models.Actor, error) {
+    grants := services.DefaultRoleBindings[role]
+    if role == "root" {
+        grants = nil
+        for _, p := range append(models.KnownPermissions, models.PlatformPermissions...) {
+            grants = append(grants, models.Grant{Permission: p})
+        }
+    }
+    return models.Actor{UserID: userID, TenantID: tenantID, Role: role, Grants: grants}, nil
+}
+
+func (f fakePolicy) ListBindings(tenantID string) ([]models.RoleBinding, error) {
+    return nil, f.err
+}
+
+func (f fakePolicy) UpdateBinding(tenantID, role string, grants []models.Grant) (*models.RoleBinding, error) {
+    return nil, f.err
+}
+
+type fakeTenants struct {
+    services.TenantService
+    err error
+}
+
+func (fakeTenants) CheckActive(id string) error { return nil }
+
+func (f fakeTenants) Resolve(id string) (*models.Tenant, error) { return nil, f.err }
+
+func (f fakeTenants) List() ([]models.Tenant, error) { return nil, f.err }
+
+func (f fakeTenants) Create(req models.CreateTenantRequest) (*models.Tenant, error) {
+    return nil, f.err
+}
+
+func (f fakeTenants) Update(actor models.Actor, id string, req models.UpdateTenantRequest) (*models.Tenant, error) {
+    return nil, f.err
+}
+
+type fakeAudit struct {
+    services.AuditService
+    err error
+}
+
+func (fakeAudit) Record(tenantID string, entry models.AuditLog) {}
+
+func (f fakeAudit) List(actor models.Actor, filter models.AuditLogFilter, page, limit int) ([]models.AuditLog, int64, error) {
+    return nil, 0, f.err
+}
+
+type fakeAuth struct {
+    services.AuthService
+    err error
+}
+
+func (f fakeAuth) Login(req models.LoginRequest) (*models.LoginResponse, error) {
+    return nil, f.err
+}
+
+func (f fakeAuth) Impersonate(actor models.Actor, req models.ImpersonateRequest) (*models.LoginResponse, error) {
+    return nil, f.err
+}
+
+type fakeOIDC struct {
+    services.OIDCService
+    err error
+}
+
+func (fakeOIDC) NewAuthRequest() services.OIDCAuthRequest {
+    return services.OIDCAuthRequest{URL: "https://idp.example/authorize", State: "s", Nonce: "n", Verifier: "v"}
+}
+
+func (f fakeOIDC) Callback(ctx context.Context, code, verifier, nonce string) (*models.LoginResponse, error) {
+    return nil, f.err
+}
+
+// fakeClaims answers lookups with claim when err is nil.
+type fakeClaims struct {
+    services.ClaimService
+    claim *models.Claim
+    err   error
+}
+
+func (f fakeClaims) CreateClaim(actor models.Actor, req models.CreateClaimRequest) (*models.Claim, error) {
+    return f.claim, f.err
+}
+
+func (f fakeClaims) GetMyClaims(actor models.Actor, page, limit int) ([]models.Claim, int64, error) {
+    return nil, 0, f.err
+}
+
+func (f fakeClaims) GetAllClaims(actor models.Actor, query models.ClaimListQuery, page, limit int) ([]models.Claim, int64, error) {
+    return nil, 0, f.err
+}
+
+func (f fakeClaims) GetClaimByID(actor models.Actor, claimID primitive.ObjectID) (*models.Claim, error) {
+    return f.claim, f.err
+}
+
+func (f fakeClaims) GetClaimByReference(actor models.Actor, reference string) (*models.Claim, error) {
+    return f.claim, f.err
+}
+
+func (f fakeClaims) UpdateClaim(actor models.Actor, claimID primitive.ObjectID, version int64, contentType string, patch []byte) (*models.Claim, error) {
+    return f.claim, f.err
+}
+
+func (f fakeClaims) DeleteClaim(actor models.Actor, claimID primitive.ObjectID) error {
+    return f.err
+}
+
+func (f fakeClaims) SubmitClaim(actor models.Actor, claimID primitive.ObjectID, version int64, justification string) error {
+    return f.err
+}
+
+func (f fakeClaims) ReviewClaim(actor models.Actor, claimID primitive.ObjectID, version int64, note string) error {
+    return f.err
+}
+
+func (f fakeClaims) ApproveClaim(actor models.Actor, claimID primitive.ObjectID, version int64) error {
+    return f.err
+}
+
+func (f fakeClaims) RejectClaim(actor models.Actor, claimID primitive.ObjectID, version int64, reason string) error {
+    return f.err
+}
+
+func (f fakeClaims) RequestInfo(actor models.Actor, claimID primitive.ObjectID, version int64, note string) error {
+    return f.err
+}
+
+func (f fakeClaims) ProvideInfo(actor models.Actor, claimID primitive.ObjectID, version int64, note string) error {
+    return f.err
+}
+
+func (f fakeClaims) ResolveFraudReview(actor models.Actor, claimID primitive.ObjectID, version int64, cleared bool, note string) error {
+    return f.err
+}
+
+func (f fakeClaims) WithdrawClaim(actor models.Actor, claimID primitive.ObjectID, version int64, reason string) error {
+    return f.err
+}
+
+func (f fakeClaims) AppealClaim(actor models.Actor, claimID primitive.ObjectID, version int64, req models.AppealClaimRequest) error {
+    return f.err
+}
+
+func (f fakeClaims) ReopenClaim(actor models.Actor, claimID primitive.ObjectID, version int64, reason string) error {
+    return f.err
+}
+
+func (f fakeClaims) BulkAction(actor models.Actor, action string, req models.BulkActionRequest) (*models.BulkActionResult, error) {
+    return nil, f.err
+}
+
+type fakeImport struct {
+    services.ImportService
+    err error
+}
+
+func (f fakeImport) Import(actor models.Actor, format string, r io.Reader, dryRun bool) (*models.ImportReport, error) {
+    return nil, f.err
+}
+
+type fakeExport struct {
+    services.ExportService
+    err error
+}
+
+func (f fakeExport) Count(actor models.Actor, req models.ExportRequest) (int64, error) {
+    return 0, f.err
+}
+
+func (f fakeExport) Stream(actor models.Actor, req models.ExportRequest, w io.Writer) (int64, error) {
+    return 0, f.err
+}
+
+func (f fakeExport) StartJob(actor models.Actor, req models.ExportRequest) (*models.ExportJob, error) {
+    return nil, f.err
+}
+
+func (f fakeExport) GetJob(actor models.Actor, id primitive.ObjectID) (*models.ExportJob, error) {
+    return nil, f.err
+}
+
+type fakeComments struct {
+    services.CommentService
+    err error
+}
+
+func (f fakeComments) List(actor models.Actor, claimID primitive.ObjectID) ([]models.ClaimComment, error) {
+    return nil, f.err
+}
+
+func (f fakeComments) Create(actor models.Actor, claimID primitive.ObjectID, req models.CreateCommentRequest) (*models.ClaimComment, error) {
+    return nil, f.err
+}
+
+func (f fakeComments) Update(actor models.Actor, claimID, commentID primitive.ObjectID, req models.UpdateCommentRequest) (*models.ClaimComment, error) {
+    return nil, f.err
+}
+
+type fakeDocuments struct {
+    services.DocumentService
+    err error
+}
+
+func (fakeDocuments) Templates() []models.DocumentTemplate { return nil }
+
+func (f fakeDocuments) Generate(actor models.Actor, claimID primitive.ObjectID, req models.GenerateDocumentRequest) (*models.ClaimDocument, error) {
+    return nil, f.err
+}
+
+func (f fakeDocuments) Download(actor models.Actor, claimID, documentID primitive.ObjectID) (*models.ClaimDocument, []byte, error) {
+    return nil, nil, f.err
+}
+
+type fakeNotifications struct {
+    services.NotificationService
+    err error
+}
+
+func (f fakeNotifications) List(actor models.Actor, unreadOnly bool, page, limit int) ([]models.Notification, int64, error) {
+    return nil, 0, f.err
+}
+
+func (f fakeNotifications) UnreadCount(actor models.Actor) (int64, error) {
+    return 0, f.err
+}
+
+func (f fakeNotifications) MarkRead(actor models.Actor, id primitive.ObjectID) error {
+    return f.err
+}
+
+func (f fakeNotifications) Preferences(actor models.Actor) (*models.NotificationPreferences, error) {
+    return nil, f.err
+}
+
+func (f fakeNotifications) UpdatePreferences(actor models.Actor, req models.UpdateNotificationPreferencesRequest) (*models.NotificationPreferences, error) {
+    return nil, f.err
+}
+
+type fakeQueue struct {
+    services.QueueService
+    err error
+}
+
+func (f fakeQueue) Mine(actor models.Actor, page, limit int) ([]models.Claim, int64, error) {
+    return nil, 0, f.err
+}
+
+func (f fakeQueue) Checkout(actor models.Actor, claimID primitive.ObjectID) (*models.Claim, error) {
+    return &models.Claim{ID: claimID, Version: 3}, f.err
+}
+
+func (f fakeQueue) Release(actor models.Actor, claimID primitive.ObjectID) error {
+    return f.err
+}
+
+func (f fakeQueue) Reassign(actor models.Actor, claimID primitive.ObjectID, assigneeID primitive.ObjectID) error {
+    return f.err
+}
+
+type fakeDelegations struct {
+    services.DelegationService
+    err error
+}
+
+func (f fakeDelegations) List(actor models.Actor) ([]models.Delegation, error) {
+    return nil, f.err
+}
+
+func (f fakeDelegations) Create(actor models.Actor, req models.CreateDelegationRequest) (*models.Delegation, error) {
+    return nil, f.err
+}
+
+func (f fakeDelegations) Revoke(actor models.Actor, id primitive.ObjectID) error {
+    return f.err
+}
+
+type fakeSLA struct {
+    services.SLAService
+    err error
+}
+
+func (f fakeSLA) ListPolicies(actor models.Actor) ([]models.SLAPolicy, error) {
+    return nil, f.err
+}
+
+func (f fakeSLA) CreatePolicy(actor models.Actor, policy models.SLAPolicy) (*models.SLAPolicy, error) {
+    return nil, f.err
+}
+
+func (f fakeSLA) UpdatePolicy(actor models.Actor, id primitive.ObjectID, policy models.SLAPolicy) (*models.SLAPolicy, error) {
+    return nil, f.err
+}
+
+func (f fakeSLA) DeletePolicy(actor models.Actor, id primitive.ObjectID) error {
+    return f.err
+}
+
+func (f fakeSLA) GetCalendar(actor models.Actor) (*models.BusinessCalendar, error) {
+    return nil, f.err
+}
+
+func (f fakeSLA) UpdateCalendar(actor models.Actor, calendar models.BusinessCalendar) (*models.BusinessCalendar, error) {
+    return nil, f.err
+}
+
+type fakeReports struct {
+    services.ReportService
+    err error
+}
+
+func (f fakeReports) Summary(actor models.Actor, q models.ReportQuery) (*models.SummaryReport, error) {
+    return nil, f.err
+}
+
+func (f fakeReports) CycleTimes(actor models.Actor, q models.ReportQuery) (*models.CycleTimeReport, error) {
+    return nil, f.err
+}
+
+type fakeRules struct {
+    services.RuleService
+    err error
+}
+
+func (f fakeRules) List(actor models.Actor) ([]models.ClaimRule, error) {
+    return nil, f.err
+}
+
+func (f fakeRules) Versions(actor models.Actor, ruleID string) ([]models.ClaimRule, error) {
+    return nil, f.err
+}
+
+func (f fakeRules) Create(actor models.Actor, req models.ClaimRuleRequest) (*models.ClaimRule, error) {
+    return nil, f.err
+}
+
+func (f fakeRules) Update(actor models.Actor, ruleID string, req models.ClaimRuleRequest) (*models.ClaimRule, error) {
+    return nil, f.err
+}
+
+func (f fakeRules) Disable(actor models.Actor, ruleID string) (*models.ClaimRule, error) {
+    return nil, f.err
+}
+
+func (f fakeRules) Simulate(actor models.Actor, req models.SimulateRulesRequest) (*models.RuleOutcome, error) {
+    return nil, f.err
+}
+
+type fakeScheduler struct {
+    services.SchedulerService
+    err error
+}
+
+func (f fakeScheduler) Jobs() ([]models.JobInfo, error) {
+    return nil, f.err
+}
+
+func (f fakeScheduler) Trigger(name string) (*models.JobRun, error) {
+    return nil, f.err
+}
+
+func (f fakeScheduler) Runs(name string, limit int) ([]models.JobRun, error) {
+    return nil, f.err
+}
+
+// testServices backs every route with fakes failing with err.
+func testServices(err error) Services {
+    return Services{
+        Auth:          fakeAuth{err: err},
+        OIDC:          fakeOIDC{err: err},
+        Policy:        fakePolicy{err: err},
+        Tenants:       fakeTenants{err: err},
+        Audit:         fakeAudit{err: err},
+        Claims:        fakeClaims{err: err},
+        Import:        fakeImport{err: err},
+        Export:        fakeExport{err: err},
+        Comments:      fakeComments{err: err},
+        Documents:     fakeDocuments{err: err},
+        Notifications: fakeNotifications{err: err},
+        Queue:         fakeQueue{err: err},
+        Delegations:   fakeDelegations{err: err},
+        SLA:           fakeSLA{err: err},
+        Reports:       fakeReports{err: err},
+        Rules:         fakeRules{err: err},
+        Scheduler:     fakeScheduler{err: err},
+    }
+}
+
+func testRouter(s Services) *gin.Engine {
+    gin.SetMode(gin.TestMode)
+    r := gin.New()
+    RegisterRoutes(r, s)
+    return r
+}
@@ -0,0 +1,44 @@
+package handlers
+
+import (
+    "insurance-claims-api/internal/services"
+    "insurance-claims-api/internal/utils"
+    "io"
+    "net/http"
+    "path/filepath"
+    "strings"
+
+    "github.com/gin-gonic/gin"
+)
+
+// ImportClaims accepts the records either as a multipart "file" or as the raw
+// request body. The format comes from ?format=, the file extension or the
+// content type, defaulting to CSV.
+func ImportClaims(svc services.ImportService) gin.HandlerFunc {
+    return func(c *gin.Context) {
+        format := c.Query("format")
+        var body io.Reader = c.Request.Body
+        if file, err := c.FormFile("file"); err == nil {
+            f, err := file.Open()
+            if err != nil {
+                utils.ProblemResponse(c, err, http.StatusBadRequest)
+                return
+            }
+            defer f.Close()
+            body = f
+            if format == "" {
+                format = strings.TrimPrefix(filepath.Ext(file.Filename), ".")
+            }
+        }
+        if format == "" && strings.Contains(c.ContentType(), "ndjson") {
+            format = "ndjson"
+        }
+
+        report, err := svc.Import(currentActor(c), format, body, c.Query("dry_run") == "true")
+        if err != nil {
+            utils.ProblemResponse(c, err, http.StatusInternalServerError)
+            return
+        }
+        utils.SuccessResponse(c, report)
+    }
+}
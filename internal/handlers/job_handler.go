@@ -0,0 +1,48 @@
+package handlers
+
+import (
+    "insurance-claims-api/internal/models"
+    "insurance-claims-api/internal/services"
+    "insurance-claims-api/internal/utils"
+    "net/http"
+
+    "github.com/gin-gonic/gin"
+)
+
+func ListJobs(svc services.SchedulerService) gin.HandlerFunc {
+    return func(c *gin.Context) {
+        jobs, err := svc.Jobs()
+        if err != nil {
+            utils.ProblemResponse(c, err, http.StatusInternalServerError)
+            return
+        }
+        utils.SuccessResponse(c, jobs)
+    }
+}
+
+func RunJob(svc services.SchedulerService) gin.HandlerFunc {
+    return func(c *gin.Context) {
+        run, err := svc.Trigger(c.Param("name"))
+        if err != nil {
+            utils.ProblemResponse(c, err, http.StatusInternalServerError)
+            return
+        }
+        utils.SuccessResponse(c, run)
+    }
+}
+
+func ListJobRuns(svc services.SchedulerService) gin.HandlerFunc {
+    return func(c *gin.Context) {
+        var q models.JobRunsQuery
+        if err := c.ShouldBindQuery(&q); err != nil {
+            utils.ProblemResponse(c, utils.BindingError(err), http.StatusBadRequest)
+            return
+        }
+        runs, err := svc.Runs(c.Param("name"), q.Limit)
+        if err != nil {
+            utils.ProblemResponse(c, err, http.StatusInternalServerError)
+            return
+        }
+        utils.SuccessResponse(c, runs)
+    }
+}
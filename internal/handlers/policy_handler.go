@@ -0,0 +1,43 @@
+package handlers
+
+import (
+    "insurance-claims-api/internal/models"
+    "insurance-claims-api/internal/services"
+    "insurance-claims-api/internal/utils"
+    "net/http"
+
+    "github.com/gin-gonic/gin"
+)
+
+func GetMyPermissions() gin.HandlerFunc {
+    return func(c *gin.Context) {
+        utils.SuccessResponse(c, currentActor(c))
+    }
+}
+
+func ListRoleBindings(svc services.PolicyService) gin.HandlerFunc {
+    return func(c *gin.Context) {
+        bindings, err := svc.ListBindings(currentActor(c).TenantID)
+        if err != nil {
+            utils.ProblemResponse(c, err, http.StatusInternalServerError)
+            return
+        }
+        utils.SuccessResponse(c, bindings)
+    }
+}
+
+func UpdateRoleBinding(svc services.PolicyService) gin.HandlerFunc {
+    return func(c *gin.Context) {
+        var req models.UpdateRoleBindingRequest
+        if err := c.ShouldBindJSON(&req); err != nil {
+            utils.ProblemResponse(c, utils.BindingError(err), http.StatusBadRequest)
+            return
+        }
+        binding, err := svc.UpdateBinding(currentActor(c).TenantID, c.Param("role"), req.Grants)
+        if err != nil {
+            utils.ProblemResponse(c, err, http.StatusInternalServerError)
+            return
+        }
+        utils.SuccessResponse(c, binding)
+    }
+}
@@ -0,0 +1,74 @@
+package handlers
+
+import (
+    "insurance-claims-api/internal/models"
+    "insurance-claims-api/internal/services"
+    "insurance-claims-api/internal/utils"
+    "net/http"
+
+    "github.com/gin-gonic/gin"
+)
+
+func GetMyQueue(svc services.QueueService) gin.HandlerFunc {
+    return func(c *gin.Context) {
+        page, limit, ok := pageQuery(c)
+        if !ok {
+            return
+        }
+        claims, total, err := svc.Mine(currentActor(c), page, limit)
+        if err != nil {
+            utils.ProblemResponse(c, err, http.StatusInternalServerError)
+            return
+        }
+        utils.PaginatedResponse(c, claims, total, page, limit)
+    }
+}
+
+func CheckoutClaim(svc services.QueueService) gin.HandlerFunc {
+    return func(c *gin.Context) {
+        id, ok := objectIDParam(c, "id")
+        if !ok {
+            return
+        }
+        claim, err := svc.Checkout(currentActor(c), id)
+        if err != nil {
+            utils.ProblemResponse(c, err, http.StatusInternalServerError)
+            return
+        }
+        c.Header("ETag", claimETag(claim))
+        utils.SuccessResponse(c, claim)
+    }
+}
+
+func ReleaseClaim(svc services.QueueService) gin.HandlerFunc {
+    return func(c *gin.Context) {
+        id, ok := objectIDParam(c, "id")
+        if !ok {
+            return
+        }
+        if err := svc.Release(currentActor(c), id); err != nil {
+            utils.ProblemResponse(c, err, http.StatusInternalServerError)
+            return
+        }
+        utils.SuccessResponse(c, map[string]string{"message": "claim released"})
+    }
+}
+
+func AssignClaim(svc services.QueueService) gin.HandlerFunc {
+    return func(c *gin.Context) {
+        id, ok := objectIDParam(c, "id")
+        if !ok {
+            return
+        }
+        var req models.AssignClaimRequest
+        if err := c.ShouldBindJSON(&req); err != nil {
+            utils.ProblemResponse(c, utils.BindingError(err), http.StatusBadRequest)
+            return
+        }
+        if err := svc.Reassign(currentActor(c), id, req.AssigneeID); err != nil {
+            utils.ProblemResponse(c, err, http.StatusInternalServerError)
+            return
+        }
+        utils.SuccessResponse(c, map[string]string{"message": "claim assigned"})
+    }
+}
@@ -0,0 +1,42 @@
+package handlers
+
+import (
+    "insurance-claims-api/internal/models"
+    "insurance-claims-api/internal/services"
+    "insurance-claims-api/internal/utils"
+    "net/http"
+
+    "github.com/gin-gonic/gin"
+)
+
+func ReportSummary(svc services.ReportService) gin.HandlerFunc {
+    return func(c *gin.Context) {
+        var q models.ReportQuery
+        if err := c.ShouldBindQuery(&q); err != nil {
+            utils.ProblemResponse(c, utils.BindingError(err), http.StatusBadRequest)
+            return
+        }
+        report, err := svc.Summary(currentActor(c), q)
+        if err != nil {
+            utils.ProblemResponse(c, err, http.StatusInternalServerError)
+            return
+        }
+        utils.SuccessResponse(c, report)
+    }
+}
+
+func ReportCycleTimes(svc services.ReportService) gin.HandlerFunc {
+    return func(c *gin.Context) {
+        var q models.ReportQuery
+        if err := c.ShouldBindQuery(&q); err != nil {
+            utils.ProblemResponse(c, utils.BindingError(err), http.StatusBadRequest)
+            return
+        }
+        report, err := svc.CycleTimes(currentActor(c), q)
+        if err != nil {
+            utils.ProblemResponse(c, err, http.StatusInternalServerError)
+            return
+        }
+        utils.SuccessResponse(c, report)
+    }
+}
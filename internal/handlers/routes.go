@@ -0,0 +1,131 @@
+package handlers
+
+import (
+    "insurance-claims-api/internal/config"
+    "insurance-claims-api/internal/middleware"
+    "insurance-claims-api/internal/models"
+    "insurance-claims-api/internal/services"
+
+    "github.com/gin-gonic/gin"
+)
+
+// Services are what the API routes are served from. OIDC is nil when single
+// sign-on is not configured.
+type Services struct {
+    Auth          services.AuthService
+    OIDC          services.OIDCService
+    Policy        services.PolicyService
+    Tenants       services.TenantService
+    Audit         services.AuditService
+    Idempotency   services.IdempotencyService
+    Claims        services.ClaimService
+    Import        services.ImportService
+    Export        services.ExportService
+    Comments      services.CommentService
+    Documents     services.DocumentService
+    Notifications services.NotificationService
+    Queue         services.QueueService
+    Delegations   services.DelegationService
+    SLA           services.SLAService
+    Reports       services.ReportService
+    Rules         services.RuleService
+    Scheduler     services.SchedulerService
+}
+
+// RegisterRoutes mounts every API route on r. Everything under /api/v1 except
+// login needs a token and the permission named next to the route.
+func RegisterRoutes(r *gin.Engine, s Services) {
+    r.POST("/api/v1/login", Login(s.Auth))
+
+    if s.OIDC != nil {
+        r.GET("/api/v1/auth/oidc/login", OIDCLogin(s.OIDC))
+        r.GET("/api/v1/auth/oidc/callback", OIDCCallback(s.OIDC))
+    }
+
+    authRoutes := r.Group("/api/v1")
+    authRoutes.Use(middleware.AuthMiddleware(s.Policy, s.Tenants), middleware.Impersonation(s.Audit), middleware.Idempotency(s.Idempotency, config.AppConfig.IdempotencyMaxBody))
+    can := middleware.RequirePermission
+
+    authRoutes.GET("/me/permissions", can(models.PermSelf), GetMyPermissions())
+
+    authRoutes.POST("/claims", can(models.PermClaimCreate), CreateClaim(s.Claims))
+    authRoutes.GET("/claims", can(models.PermClaimReadOwn), GetMyClaims(s.Claims))
+    authRoutes.GET("/claims/all", can(models.PermClaimReadAny), GetAllClaims(s.Claims))
+    authRoutes.POST("/claims/bulk/review", can(models.PermClaimReview), BulkClaimAction(s.Claims, models.BulkReview))
+    authRoutes.POST("/claims/bulk/approve", can(models.PermClaimApprove), BulkClaimAction(s.Claims, models.BulkApprove))
+    authRoutes.POST("/claims/bulk/reject", can(models.PermClaimReject), BulkClaimAction(s.Claims, models.BulkReject))
+    authRoutes.POST("/claims/import", can(models.PermClaimImport), ImportClaims(s.Import))
+    authRoutes.GET("/claims/export", can(models.PermClaimRead), ExportClaims(s.Export))
+    authRoutes.POST("/claims/exports", can(models.PermClaimRead), CreateExportJob(s.Export))
+    authRoutes.GET("/claims/exports/:id", can(models.PermClaimRead), GetExportJob(s.Export))
+    authRoutes.GET("/claims/exports/:id/download", can(models.PermClaimRead), DownloadExport(s.Export))
+    authRoutes.GET("/claims/:id", can(models.PermClaimRead), GetClaimByID(s.Claims))
+    authRoutes.PATCH("/claims/:id", can(models.PermClaimUpdate), UpdateClaim(s.Claims))
+    authRoutes.DELETE("/claims/:id", can(models.PermClaimDeleteOwn), DeleteClaim(s.Claims))
+    authRoutes.PATCH("/claims/:id/submit", can(models.PermClaimSubmitOwn), SubmitClaim(s.Claims))
+    authRoutes.PATCH("/claims/:id/review", can(models.PermClaimReview), ReviewClaim(s.Claims))
+    authRoutes.PATCH("/claims/:id/approve", can(models.PermClaimApprove), ApproveClaim(s.Claims))
+    authRoutes.PATCH("/claims/:id/reject", can(models.PermClaimReject), RejectClaim(s.Claims))
+
+    authRoutes.GET("/claims/:id/comments", can(models.PermClaimRead), ListComments(s.Comments))
+    authRoutes.POST("/claims/:id/comments", can(models.PermClaimRead), CreateComment(s.Comments))
+    authRoutes.GET("/documents/templates", can(models.PermClaimDocumentGenerate), ListDocumentTemplates(s.Documents))
+    authRoutes.POST("/claims/:id/documents", can(models.PermClaimDocumentGenerate), GenerateDocument(s.Documents))
+    authRoutes.GET("/claims/:id/documents/:document_id", can(models.PermClaimRead), DownloadDocument(s.Documents))
+    authRoutes.PATCH("/claims/:id/comments/:comment_id", can(models.PermClaimRead), UpdateComment(s.Comments))
+
+    authRoutes.GET("/notifications", can(models.PermSelf), ListNotifications(s.Notifications))
+    authRoutes.GET("/notifications/unread-count", can(models.PermSelf), UnreadNotificationCount(s.Notifications))
+    authRoutes.GET("/me/notification-preferences", can(models.PermSelf), GetNotificationPreferences(s.Notifications))
+    authRoutes.PUT("/me/notification-preferences", can(models.PermSelf), UpdateNotificationPreferences(s.Notifications))
+    authRoutes.PATCH("/notifications/:id/read", can(models.PermSelf), MarkNotificationRead(s.Notifications))
+
+    authRoutes.PATCH("/claims/:id/withdraw", can(models.PermClaimWithdrawOwn), WithdrawClaim(s.Claims))
+    authRoutes.PATCH("/claims/:id/appeal", can(models.PermClaimAppealOwn), AppealClaim(s.Claims))
+    authRoutes.PATCH("/claims/:id/reopen", can(models.PermClaimReopen), ReopenClaim(s.Claims))
+    authRoutes.PATCH("/claims/:id/fraud-review", can(models.PermClaimFraudReview), ResolveFraudReview(s.Claims))
+    authRoutes.PATCH("/claims/:id/request-info", can(models.PermClaimRequestInfo), RequestClaimInfo(s.Claims))
+    authRoutes.PATCH("/claims/:id/provide-info", can(models.PermClaimUpdateOwn), ProvideClaimInfo(s.Claims))
+
+    authRoutes.GET("/queue/mine", can(models.PermQueueWork), GetMyQueue(s.Queue))
+    authRoutes.PATCH("/claims/:id/checkout", can(models.PermQueueWork), CheckoutClaim(s.Queue))
+    authRoutes.PATCH("/claims/:id/release", can(models.PermQueueWork), ReleaseClaim(s.Queue))
+    authRoutes.PATCH("/claims/:id/assign", can(models.PermClaimAssign), AssignClaim(s.Queue))
+
+    authRoutes.GET("/delegations", can(models.PermDelegationManageOwn), ListDelegations(s.Delegations))
+    authRoutes.POST("/delegations", can(models.PermDelegationManageOwn), CreateDelegation(s.Delegations))
+    authRoutes.DELETE("/delegations/:id", can(models.PermDelegationManageOwn), RevokeDelegation(s.Delegations))
+
+    authRoutes.POST("/impersonate", can(models.PermUserImpersonate), Impersonate(s.Auth))
+    authRoutes.GET("/audit-logs", can(models.PermAuditRead), ListAuditLogs(s.Audit))
+
+    authRoutes.GET("/sla/policies", can(models.PermSLAManage), ListSLAPolicies(s.SLA))
+    authRoutes.POST("/sla/policies", can(models.PermSLAManage), CreateSLAPolicy(s.SLA))
+    authRoutes.PUT("/sla/policies/:id", can(models.PermSLAManage), UpdateSLAPolicy(s.SLA))
+    authRoutes.DELETE("/sla/policies/:id", can(models.PermSLAManage), DeleteSLAPolicy(s.SLA))
+    authRoutes.GET("/sla/calendar", can(models.PermSLAManage), GetBusinessCalendar(s.SLA))
+    authRoutes.PUT("/sla/calendar", can(models.PermSLAManage), UpdateBusinessCalendar(s.SLA))
+
+    authRoutes.GET("/reports/summary", can(models.PermReportRead), ReportSummary(s.Reports))
+    authRoutes.GET("/reports/cycle-times", can(models.PermReportRead), ReportCycleTimes(s.Reports))
+
+    authRoutes.GET("/rules", can(models.PermRuleManage), ListRules(s.Rules))
+    authRoutes.POST("/rules", can(models.PermRuleManage), CreateRule(s.Rules))
+    authRoutes.POST("/rules/simulate", can(models.PermRuleManage), SimulateRules(s.Rules))
+    authRoutes.GET("/rules/:rule_id/versions", can(models.PermRuleManage), ListRuleVersions(s.Rules))
+    authRoutes.PUT("/rules/:rule_id", can(models.PermRuleManage), UpdateRule(s.Rules))
+    authRoutes.DELETE("/rules/:rule_id", can(models.PermRuleManage), DisableRule(s.Rules))
+
+    authRoutes.GET("/jobs", can(models.PermJobManage), ListJobs(s.Scheduler))
+    authRoutes.POST("/jobs/:name/run", can(models.PermJobManage), RunJob(s.Scheduler))
+    authRoutes.GET("/jobs/:name/runs", can(models.PermJobManage), ListJobRuns(s.Scheduler))
+
+    authRoutes.GET("/roles", can(models.PermRoleManage), ListRoleBindings(s.Policy))
+    authRoutes.PUT("/roles/:role", can(models.PermRoleManage), UpdateRoleBinding(s.Policy))
+
+    authRoutes.GET("/tenant", can(models.PermTenantManage), GetCurrentTenant(s.Tenants))
+    authRoutes.PATCH("/tenant", can(models.PermTenantManage), UpdateCurrentTenant(s.Tenants))
+    authRoutes.GET("/tenants", can(models.PermTenantCreate), ListTenants(s.Tenants))
+    authRoutes.POST("/tenants", can(models.PermTenantCreate), CreateTenant(s.Tenants))
+    authRoutes.PATCH("/tenants/:tenant", can(models.PermTenantCreate), UpdateTenant(s.Tenants))
+}
@@ -0,0 +1,91 @@
+package handlers
+
+import (
+    "insurance-claims-api/internal/models"
+    "insurance-claims-api/internal/services"
+    "insurance-claims-api/internal/utils"
+    "net/http"
+
+    "github.com/gin-gonic/gin"
+)
+
+func ListRules(svc services.RuleService) gin.HandlerFunc {
+    return func(c *gin.Context) {
+        rules, err := svc.List(currentActor(c))
+        if err != nil {
+            utils.ProblemResponse(c, err, http.StatusInternalServerError)
+            return
+        }
+        utils.SuccessResponse(c, rules)
+    }
+}
+
+func ListRuleVersions(svc services.RuleService) gin.HandlerFunc {
+    return func(c *gin.Context) {
+        rules, err := svc.Versions(currentActor(c), c.Param("rule_id"))
+        if err != nil {
+            utils.ProblemResponse(c, err, http.StatusInternalServerError)
+            return
+        }
+        utils.SuccessResponse(c, rules)
+    }
+}
+
+func CreateRule(svc services.RuleService) gin.HandlerFunc {
+    return func(c *gin.Context) {
+        var req models.ClaimRuleRequest
+        if err := c.ShouldBindJSON(&req); err != nil {
+            utils.ProblemResponse(c, utils.BindingError(err), http.StatusBadRequest)
+            return
+        }
+        rule, err := svc.Create(currentActor(c), req)
+        if err != nil {
+            utils.ProblemResponse(c, err, http.StatusInternalServerError)
+            return
+        }
+        utils.SuccessResponse(c, rule)
+    }
+}
+
+func UpdateRule(svc services.RuleService) gin.HandlerFunc {
+    return func(c *gin.Context) {
+        var req models.ClaimRuleRequest
+        if err := c.ShouldBindJSON(&req); err != nil {
+            utils.ProblemResponse(c, utils.BindingError(err), http.StatusBadRequest)
+            return
+        }
+        rule, err := svc.Update(currentActor(c), c.Param("rule_id"), req)
+        if err != nil {
+            utils.ProblemResponse(c, err, http.StatusInternalServerError)
+            return
+        }
+        utils.SuccessResponse(c, rule)
+    }
+}
+
+func DisableRule(svc services.RuleService) gin.HandlerFunc {
+    return func(c *gin.Context) {
+        rule, err := svc.Disable(currentActor(c), c.Param("rule_id"))
+        if err != nil {
+            utils.ProblemResponse(c, err, http.StatusInternalServerError)
+            return
+        }
+        utils.SuccessResponse(c, rule)
+    }
+}
+
+func SimulateRules(svc services.RuleService) gin.HandlerFunc {
+    return func(c *gin.Context) {
+        var req models.SimulateRulesRequest
+        if err := c.ShouldBindJSON(&req); err != nil {
+            utils.ProblemResponse(c, utils.BindingError(err), http.StatusBadRequest)
+            return
+        }
+        outcome, err := svc.Simulate(currentActor(c), req)
+        if err != nil {
+            utils.ProblemResponse(c, err, http.StatusInternalServerError)
+            return
+        }
+        utils.SuccessResponse(c, outcome)
+    }
+}
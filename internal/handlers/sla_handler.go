@@ -0,0 +1,136 @@
+package handlers
+
+import (
+    "insurance-claims-api/internal/models"
+    "insurance-claims-api/internal/services"
+    "insurance-claims-api/internal/utils"
+    "net/http"
+
+    "github.com/gin-gonic/gin"
+)
+
+func RequestClaimInfo(svc services.ClaimService) gin.HandlerFunc {
+    return func(c *gin.Context) {
+        id, ok := objectIDParam(c, "id")
+        if !ok {
+            return
+        }
+        version, ok := ifMatch(c, false)
+        if !ok {
+            return
+        }
+        note := c.PostForm("note")
+        if err := svc.RequestInfo(currentActor(c), id, version, note); err != nil {
+            utils.ProblemResponse(c, err, http.StatusInternalServerError)
+            return
+        }
+        utils.SuccessResponse(c, map[string]string{"message": "information requested"})
+    }
+}
+
+func ProvideClaimInfo(svc services.ClaimService) gin.HandlerFunc {
+    return func(c *gin.Context) {
+        id, ok := objectIDParam(c, "id")
+        if !ok {
+            return
+        }
+        version, ok := ifMatch(c, false)
+        if !ok {
+            return
+        }
+        note := c.PostForm("note")
+        if err := svc.ProvideInfo(currentActor(c), id, version, note); err != nil {
+            utils.ProblemResponse(c, err, http.StatusInternalServerError)
+            return
+        }
+        utils.SuccessResponse(c, map[string]string{"message": "information provided"})
+    }
+}
+
+func ListSLAPolicies(svc services.SLAService) gin.HandlerFunc {
+    return func(c *gin.Context) {
+        policies, err := svc.ListPolicies(currentActor(c))
+        if err != nil {
+            utils.ProblemResponse(c, err, http.StatusInternalServerError)
+            return
+        }
+        utils.SuccessResponse(c, policies)
+    }
+}
+
+func CreateSLAPolicy(svc services.SLAService) gin.HandlerFunc {
+    return func(c *gin.Context) {
+        var req models.SLAPolicy
+        if err := c.ShouldBindJSON(&req); err != nil {
+            utils.ProblemResponse(c, utils.BindingError(err), http.StatusBadRequest)
+            return
+        }
+        policy, err := svc.CreatePolicy(currentActor(c), req)
+        if err != nil {
+            utils.ProblemResponse(c, err, http.StatusInternalServerError)
+            return
+        }
+        utils.SuccessResponse(c, policy)
+    }
+}
+
+func UpdateSLAPolicy(svc services.SLAService) gin.HandlerFunc {
+    return func(c *gin.Context) {
+        id, ok := objectIDParam(c, "id")
+        if !ok {
+            return
+        }
+        var req models.SLAPolicy
+        if err := c.ShouldBindJSON(&req); err != nil {
+            utils.ProblemResponse(c, utils.BindingError(err), http.StatusBadRequest)
+            return
+        }
+        policy, err := svc.UpdatePolicy(currentActor(c), id, req)
+        if err != nil {
+            utils.ProblemResponse(c, err, http.StatusInternalServerError)
+            return
+        }
+        utils.SuccessResponse(c, policy)
+    }
+}
+
+func DeleteSLAPolicy(svc services.SLAService) gin.HandlerFunc {
+    return func(c *gin.Context) {
+        id, ok := objectIDParam(c, "id")
+        if !ok {
+            return
+        }
+        if err := svc.DeletePolicy(currentActor(c), id); err != nil {
+            utils.ProblemResponse(c, err, http.StatusInternalServerError)
+            return
+        }
+        utils.SuccessResponse(c, map[string]string{"message": "policy deleted"})
+    }
+}
+
+func GetBusinessCalendar(svc services.SLAService) gin.HandlerFunc {
+    return func(c *gin.Context) {
+        cal, err := svc.GetCalendar(currentActor(c))
+        if err != nil {
+            utils.ProblemResponse(c, err, http.StatusInternalServerError)
+            return
+        }
+        utils.SuccessResponse(c, cal)
+    }
+}
+
+func UpdateBusinessCalendar(svc services.SLAService) gin.HandlerFunc {
+    return func(c *gin.Context) {
+        var req models.BusinessCalendar
+        if err := c.ShouldBindJSON(&req); err != nil {
+            utils.ProblemResponse(c, utils.BindingError(err), http.StatusBadRequest)
+            return
+        }
+        cal, err := svc.UpdateCalendar(currentActor(c), req)
+        if err != nil {
+            utils.ProblemResponse(c, err, http.StatusInternalServerError)
+            return
+        }
+        utils.SuccessResponse(c, cal)
+    }
+}
@@ -0,0 +1,328 @@
+package handlers
+
+import (
+    "encoding/json"
+    "errors"
+    "insurance-claims-api/internal/config"
+    "insurance-claims-api/internal/middleware"
+    "insurance-claims-api/internal/models"
+    "insurance-claims-api/internal/services"
+    "insurance-claims-api/internal/utils"
+    "net/http"
+    "net/http/httptest"
+    "strings"
+    "testing"
+
+    "github.com/golang-jwt/jwt/v5"
+    "go.mongodb.org/mongo-driver/bson/primitive"
+    "go.mongodb.org/mongo-driver/mongo"
+)
+
+const testSecret = "test-secret"
+
+func testToken(t *testing.T, role string, impersonated bool) string {
+    t.Helper()
+    claims := middleware.Claims{UserID: primitive.NewObjectID(), TenantID: "t1", Role: role}
+    if impersonated {
+        support := primitive.NewObjectID()
+        claims.ImpersonatorID = &support
+    }
+    token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString([]byte(testSecret))
+    if err != nil {
+        t.Fatal(err)
+    }
+    return "Bearer " + token
+}
+
+func TestStatusMatrix(t *testing.T) {
+    config.AppConfig.JWTSecret = testSecret
+    claimID := primitive.NewObjectID().Hex()
+    user := testToken(t, models.RoleUser, false)
+    verifier := testToken(t, models.RoleVerifier, false)
+
+    tests := []struct {
+        name        string
+        claims      fakeClaims
+        queue       fakeQueue
+        method      string
+        path        string
+        auth        string
+        header      map[string]string
+        body        string
+        wantStatus  int
+        wantCode    string
+        wantNoLeaks string
+    }{
+        {name: "success", method: "GET", path: "/api/v1/claims", auth: user, wantStatus: 200},
+        {name: "malformed page", method: "GET", path: "/api/v1/claims?page=abc", auth: user, wantStatus: 400, wantCode: "bad_request"},
+        {name: "invalid id", method: "PATCH", path: "/api/v1/claims/abc/checkout", auth: verifier, wantStatus: 400, wantCode: "invalid_id"},
+        {name: "no token", method: "GET", path: "/api/v1/claims", wantStatus: 401, wantCode: "unauthorized"},
+        {name: "bad token", method: "GET", path: "/api/v1/claims", auth: "Bearer nope", wantStatus: 401, wantCode: "unauthorized"},
+        {name: "missing permission", method: "PATCH", path: "/api/v1/claims/" + claimID + "/checkout", auth: user, wantStatus: 403, wantCode: "forbidden"},
+        {name: "impersonated write", method: "PATCH", path: "/api/v1/claims/" + claimID + "/checkout", auth: testToken(t, models.RoleVerifier, true), wantStatus: 403, wantCode: "forbidden"},
+        {name: "not found", claims: fakeClaims{err: services.ErrClaimNotFound}, method: "GET", path: "/api/v1/claims/" + claimID, auth: user, wantStatus: 404, wantCode: "claim_not_found"},
+        {name: "locked", queue: fakeQueue{err: services.ErrClaimLocked}, method: "PATCH", path: "/api/v1/claims/" + claimID + "/checkout", auth: verifier, wantStatus: 409, wantCode: "claim_locked"},
+        {name: "malformed If-Match", method: "PATCH", path: "/api/v1/claims/" + claimID, auth: user,
+            header: map[string]string{"If-Match": `"x"`, "Content-Type": models.MergePatchContentType}, body: `{}`, wantStatus: 412, wantCode: "version_mismatch"},
+        {name: "stale version", claims: fakeClaims{err: services.ErrVersionMismatch}, method: "PATCH", path: "/api/v1/claims/" + claimID, auth: user,
+            header: map[string]string{"If-Match": `"1"`, "Content-Type": models.MergePatchContentType}, body: `{}`, wantStatus: 412, wantCode: "version_mismatch"},
+        {name: "unsupported patch type", method: "PATCH", path: "/api/v1/claims/" + claimID, auth: user,
+            header: map[string]string{"If-Match": `"1"`, "Content-Type": "text/plain"}, body: `x`, wantStatus: 415, wantCode: "unsupported_media_type"},
+        {name: "missing If-Match", method: "PATCH", path: "/api/v1/claims/" + claimID, auth: user,
+            header: map[string]string{"Content-Type": models.MergePatchContentType}, body: `{}`, wantStatus: 428, wantCode: "if_match_required"},
+        {name: "negative page", method: "GET", path: "/api/v1/claims?page=-1", auth: user, wantStatus: 422, wantCode: "validation_failed"},
+        {name: "zero limit", method: "GET", path: "/api/v1/claims?limit=0", auth: user, wantStatus: 422, wantCode: "validation_failed"},
+        {name: "database down", claims: fakeClaims{err: mongo.ErrClientDisconnected}, method: "GET", path: "/api/v1/claims", auth: user, wantStatus: 503, wantCode: "service_unavailable"},
+        {name: "unclassified error", claims: fakeClaims{err: errors.New("connection to 10.0.0.5 refused")}, method: "GET", path: "/api/v1/claims", auth: user,
+            wantStatus: 500, wantCode: "internal_error", wantNoLeaks: "10.0.0.5"},
+    }
+    for _, tt := range tests {
+        t.Run(tt.name, func(t *testing.T) {
+            req := httptest.NewRequest(tt.method, tt.path, strings.NewReader(tt.body))
+            if tt.auth != "" {
+                req.Header.Set("Authorization", tt.auth)
+            }
+            for k, v := range tt.header {
+                req.Header.Set(k, v)
+            }
+            s := testServices(nil)
+            s.Claims, s.Queue = tt.claims, tt.queue
+            w := httptest.NewRecorder()
+            testRouter(s).ServeHTTP(w, req)
+
+            if w.Code != tt.wantStatus {
+                t.Fatalf("status = %d, want %d: %s", w.Code, tt.wantStatus, w.Body)
+            }
+            if tt.wantCode == "" {
+                return
+            }
+            if ct := w.Header().Get("Content-Type"); ct != utils.ProblemContentType {
+                t.Errorf("Content-Type = %q", ct)
+            }
+            var problem utils.Problem
+            if err := json.Unmarshal(w.Body.Bytes(), &problem); err != nil {
+                t.Fatal(err)
+            }
+            if problem.Code != tt.wantCode || problem.Status != tt.wantStatus {
+                t.Errorf("problem = %+v, want code %s", problem, tt.wantCode)
+            }
+            if tt.wantNoLeaks != "" && strings.Contains(w.Body.String(), tt.wantNoLeaks) {
+                t.Errorf("response leaks %q: %s", tt.wantNoLeaks, w.Body)
+            }
+        })
+    }
+}
+
+func TestCheckoutSetsETag(t *testing.T) {
+    config.AppConfig.JWTSecret = testSecret
+    req := httptest.NewRequest("PATCH", "/api/v1/claims/"+primitive.NewObjectID().Hex()+"/checkout", nil)
+    req.Header.Set("Authorization", testToken(t, models.RoleVerifier, false))
+    w := httptest.NewRecorder()
+    testRouter(testServices(nil)).ServeHTTP(w, req)
+    if w.Code != http.StatusOK || w.Header().Get("ETag") != `"3"` {
+        t.Fatalf("status %d, ETag %q", w.Code, w.Header().Get("ETag"))
+    }
+}
+
+// routeRequest is a request that gets past a route's own parsing and
+// validation, so the service is reached. Routes the table misses fail the
+// matrix, which keeps new routes covered.
+type routeRequest struct {
+    query, body, contentType, cookie string
+    public                           bool // served without a token
+    noService                        bool // never reports a service error
+}
+
+var routeRequests = map[string]routeRequest{
+    "POST /api/v1/login":                            {body: `{"username": "u", "password": "p"}`, public: true},
+    "GET /api/v1/auth/oidc/login":                   {public: true, noService: true},
+    "GET /api/v1/auth/oidc/callback":                {query: "code=c&state=s", cookie: "oidc_state=s", public: true},
+    "GET /api/v1/me/permissions":                    {noService: true},
+    "POST /api/v1/claims":                           {body: `{"policy_number": "POL-000001", "claim_amount": 100, "description": "Kaca depan retak"}`},
+    "GET /api/v1/claims":                            {},
+    "GET /api/v1/claims/all":                        {},
+    "POST /api/v1/claims/bulk/review":               {body: `{"ids": ["` + matrixID + `"]}`},
+    "POST /api/v1/claims/bulk/approve":              {body: `{"ids": ["` + matrixID + `"]}`},
+    "POST /api/v1/claims/bulk/reject":               {body: `{"ids": ["` + matrixID + `"]}`},
+    "POST /api/v1/claims/import":                    {body: "external_reference\nOLD-1\n", contentType: "text/csv"},
+    "GET /api/v1/claims/export":                     {},
+    "POST /api/v1/claims/exports":                   {body: `{}`},
+    "GET /api/v1/claims/exports/:id":                {},
+    "GET /api/v1/claims/exports/:id/download":       {},
+    "GET /api/v1/claims/:id":                        {},
+    "PATCH /api/v1/claims/:id":                      {body: `{}`, contentType: models.MergePatchContentType},
+    "DELETE /api/v1/claims/:id":                     {},
+    "PATCH /api/v1/claims/:id/submit":               {},
+    "PATCH /api/v1/claims/:id/review":               {},
+    "PATCH /api/v1/claims/:id/approve":              {},
+    "PATCH /api/v1/claims/:id/reject":               {},
+    "GET /api/v1/claims/:id/comments":               {},
+    "POST /api/v1/claims/:id/comments":              {body: `{"body": "Mohon cek foto"}`},
+    "PATCH /api/v1/claims/:id/comments/:comment_id": {body: `{"body": "Mohon cek foto"}`},
+    "GET /api/v1/documents/templates":               {noService: true},
+    "POST /api/v1/claims/:id/documents":             {body: `{"kind": "claim_summary"}`},
+    "GET /api/v1/claims/:id/documents/:document_id": {},
+    "GET /api/v1/notifications":                     {},
+    "GET /api/v1/notifications/unread-count":        {},
+    "GET /api/v1/me/notification-preferences":       {},
+    "PUT /api/v1/me/notification-preferences":       {body: `{}`},
+    "PATCH /api/v1/notifications/:id/read":          {},
+    "PATCH /api/v1/claims/:id/withdraw":             {},
+    "PATCH /api/v1/claims/:id/appeal":               {body: `{"reason": "Foto baru", "documents": ["foto-2.jpg"]}`},
+    "PATCH /api/v1/claims/:id/reopen":               {},
+    "PATCH /api/v1/claims/:id/fraud-review":         {body: "decision=clear", contentType: "application/x-www-form-urlencoded"},
+    "PATCH /api/v1/claims/:id/request-info":         {},
+    "PATCH /api/v1/claims/:id/provide-info":         {},
+    "GET /api/v1/queue/mine":                        {},
+    "PATCH /api/v1/claims/:id/checkout":             {},
+    "PATCH /api/v1/claims/:id/release":              {},
+    "PATCH /api/v1/claims/:id/assign":               {body: `{"assignee_id": "` + matrixID + `"}`},
+    "GET /api/v1/delegations":                       {},
+    "POST /api/v1/delegations":                      {body: `{"delegate_id": "` + matrixID + `", "starts_at": "2026-01-01T00:00:00Z", "ends_at": "2026-01-02T00:00:00Z"}`},
+    "DELETE /api/v1/delegations/:id":                {},
+    "POST /api/v1/impersonate":                      {body: `{"user_id": "` + matrixID + `", "reason": "support"}`},
+    "GET /api/v1/audit-logs":                        {},
+    "GET /api/v1/sla/policies":                      {},
+    "POST /api/v1/sla/policies":                     {body: `{"name": "decision", "statuses": ["submitted"], "business_days": 14}`},
+    "PUT /api/v1/sla/policies/:id":                  {body: `{"name": "decision", "statuses": ["submitted"], "business_days": 14}`},
+    "DELETE /api/v1/sla/policies/:id":               {},
+    "GET /api/v1/sla/calendar":                      {},
+    "PUT /api/v1/sla/calendar":                      {body: `{"timezone": "Asia/Jakarta", "working_days": [1, 2, 3, 4, 5]}`},
+    "GET /api/v1/reports/summary":                   {},
+    "GET /api/v1/reports/cycle-times":               {},
+    "GET /api/v1/rules":                             {},
+    "POST /api/v1/rules":                            {body: `{"name": "kaca"}`},
+    "POST /api/v1/rules/simulate":                   {body: `{}`},
+    "GET /api/v1/rules/:rule_id/versions":           {},
+    "PUT /api/v1/rules/:rule_id":                    {body: `{"name": "kaca"}`},
+    "DELETE /api/v1/rules/:rule_id":                 {},
+    "GET /api/v1/jobs":                              {},
+    "POST /api/v1/jobs/:name/run":                   {},
+    "GET /api/v1/jobs/:name/runs":                   {},
+    "GET /api/v1/roles":                             {},
+    "PUT /api/v1/roles/:role":                       {body: `{"grants": [{"permission": "claim:read"}]}`},
+    "GET /api/v1/tenant":                            {},
+    "PATCH /api/v1/tenant":                          {body: `{}`},
+    "GET /api/v1/tenants":                           {},
+    "POST /api/v1/tenants":                          {body: `{"id": "acme", "name": "Acme"}`},
+    "PATCH /api/v1/tenants/:tenant":                 {body: `{}`},
+}
+
+var matrixID = primitive.NewObjectID().Hex()
+
+// matrixPath fills the route's path parameters with valid values.
+func matrixPath(route string) string {
+    parts := strings.Split(route, "/")
+    for i, p := range parts {
+        switch p {
+        case ":id", ":comment_id", ":document_id":
+            parts[i] = matrixID
+        case ":name":
+            parts[i] = "sla-evaluate"
+        case ":rule_id":
+            parts[i] = "rule-1"
+        case ":role":
+            parts[i] = models.RoleVerifier
+        case ":tenant":
+            parts[i] = "acme"
+        }
+    }
+    return strings.Join(parts, "/")
+}
+
+// TestRouteStatusMatrix checks every registered route: without a token it is
+// 401, without the permission 403, and errors from the service come back
+// with the status and code of their kind, unclassified ones as a bare 500.
+func TestRouteStatusMatrix(t *testing.T) {
+    config.AppConfig.JWTSecret = testSecret
+    if err := utils.RegisterValidations(`^[A-Z0-9][A-Z0-9/-]{5,29}$`); err != nil {
+        t.Fatal(err)
+    }
+    root := testToken(t, "root", false)
+    nobody := testToken(t, "nobody", false)
+
+    serviceErrors := []struct {
+        name       string
+        err        error
+        wantStatus int
+        wantCode   string
+    }{
+        {"not found", utils.NewError(utils.KindNotFound, "thing_not_found", "thing not found"), 404, "thing_not_found"},
+        {"conflict", utils.NewError(utils.KindConflict, "thing_conflict", "thing changed"), 409, "thing_conflict"},
+        {"validation", utils.ValidationError(utils.FieldError{Field: "x", Code: "invalid", Message: "is invalid"}), 422, utils.CodeValidation},
+        {"database down", mongo.ErrClientDisconnected, 503, utils.CodeUnavailable},
+        {"unclassified", errors.New("connection to 10.0.0.5 refused"), 500, utils.CodeInternal},
+    }
+
+    routes := testRouter(testServices(nil)).Routes()
+    if len(routes) != len(routeRequests) {
+        t.Errorf("%d routes registered, %d in the matrix", len(routes), len(routeRequests))
+    }
+    for _, route := range routes {
+        key := route.Method + " " + route.Path
+        rr, ok := routeRequests[key]
+        if !ok {
+            t.Errorf("%s is not in the status matrix", key)
+            continue
+        }
+        send := func(s Services, auth string) *httptest.ResponseRecorder {
+            target := matrixPath(route.Path)
+            if rr.query != "" {
+                target += "?" + rr.query
+            }
+            req := httptest.NewRequest(route.Method, target, strings.NewReader(rr.body))
+            if auth != "" {
+                req.Header.Set("Authorization", auth)
+            }
+            contentType := rr.contentType
+            if contentType == "" && rr.body != "" {
+                contentType = "application/json"
+            }
+            if contentType != "" {
+                req.Header.Set("Content-Type", contentType)
+            }
+            if rr.cookie != "" {
+                req.Header.Set("Cookie", rr.cookie)
+            }
+            req.Header.Set("If-Match", `"1"`)
+            w := httptest.NewRecorder()
+            testRouter(s).ServeHTTP(w, req)
+            return w
+        }
+        check := func(t *testing.T, w *httptest.ResponseRecorder, wantStatus int, wantCode string) {
+            t.Helper()
+            if w.Code != wantStatus {
+                t.Fatalf("status = %d, want %d: %s", w.Code, wantStatus, w.Body)
+            }
+            var problem utils.Problem
+            if err := json.Unmarshal(w.Body.Bytes(), &problem); err != nil {
+                t.Fatalf("not a problem response: %s", w.Body)
+            }
+            if problem.Code != wantCode {
+                t.Errorf("code = %s, want %s", problem.Code, wantCode)
+            }
+            if strings.Contains(w.Body.String(), "10.0.0.5") {
+                t.Errorf("response leaks the error: %s", w.Body)
+            }
+        }
+
+        t.Run(key, func(t *testing.T) {
+            if !rr.public {
+                t.Run("no token", func(t *testing.T) {
+                    check(t, send(testServices(nil), ""), 401, utils.CodeUnauthorized)
+                })
+                t.Run("missing permission", func(t *testing.T) {
+                    check(t, send(testServices(nil), nobody), 403, utils.CodeForbidden)
+                })
+            }
+            if rr.noService {
+                return
+            }
+            for _, se := range serviceErrors {
+                t.Run(se.name, func(t *testing.T) {
+                    check(t, send(testServices(se.err), root), se.wantStatus, se.wantCode)
+                })
+            }
+        })
+    }
+}
@@ -0,0 +1,80 @@
+package handlers
+
+import (
+    "insurance-claims-api/internal/models"
+    "insurance-claims-api/internal/services"
+    "insurance-claims-api/internal/utils"
+    "net/http"
+
+    "github.com/gin-gonic/gin"
+)
+
+func GetCurrentTenant(svc services.TenantService) gin.HandlerFunc {
+    return func(c *gin.Context) {
+        tenant, err := svc.Resolve(currentActor(c).TenantID)
+        if err != nil {
+            utils.ProblemResponse(c, err, http.StatusInternalServerError)
+            return
+        }
+        utils.SuccessResponse(c, tenant)
+    }
+}
+
+func UpdateCurrentTenant(svc services.TenantService) gin.HandlerFunc {
+    return func(c *gin.Context) {
+        var req models.UpdateTenantRequest
+        if err := c.ShouldBindJSON(&req); err != nil {
+            utils.ProblemResponse(c, utils.BindingError(err), http.StatusBadRequest)
+            return
+        }
+        tenant, err := svc.Update(currentActor(c), currentActor(c).TenantID, req)
+        if err != nil {
+            utils.ProblemResponse(c, err, http.StatusInternalServerError)
+            return
+        }
+        utils.SuccessResponse(c, tenant)
+    }
+}
+
+func ListTenants(svc services.TenantService) gin.HandlerFunc {
+    return func(c *gin.Context) {
+        tenants, err := svc.List()
+        if err != nil {
+            utils.ProblemResponse(c, err, http.StatusInternalServerError)
+            return
+        }
+        utils.SuccessResponse(c, tenants)
+    }
+}
+
+func CreateTenant(svc services.TenantService) gin.HandlerFunc {
+    return func(c *gin.Context) {
+        var req models.CreateTenantRequest
+        if err := c.ShouldBindJSON(&req); err != nil {
+            utils.ProblemResponse(c, utils.BindingError(err), http.StatusBadRequest)
+            return
+        }
+        tenant, err := svc.Create(req)
+        if err != nil {
+            utils.ProblemResponse(c, err, http.StatusInternalServerError)
+            return
+        }
+        utils.SuccessResponse(c, tenant)
+    }
+}
+
+func UpdateTenant(svc services.TenantService) gin.HandlerFunc {
+    return func(c *gin.Context) {
+        var req models.UpdateTenantRequest
+        if err := c.ShouldBindJSON(&req); err != nil {
+            utils.ProblemResponse(c, utils.BindingError(err), http.StatusBadRequest)
+            return
+        }
+        tenant, err := svc.Update(currentActor(c), c.Param("tenant"), req)
+        if err != nil {
+            utils.ProblemResponse(c, err, http.StatusInternalServerError)
+            return
+        }
+        utils.SuccessResponse(c, tenant)
+    }
+}
@@ -1,7 +1,10 @@
 package middleware
 
 import (
+    "errors"
     "insurance-claims-api/internal/config"
+    "insurance-claims-api/internal/models"
+    "insurance-claims-api/internal/services"
     "insurance-claims-api/internal/utils"
     "net/http"
     "strings"
@@ -12,21 +15,31 @@ import (
 )
 
 type Claims struct {
-    UserID primitive.ObjectID `json:"user_id"`
-    Role   string            `json:"role"`
+    UserID   primitive.ObjectID `json:"user_id"`
+    TenantID string             `json:"tenant_id"`
+    Role     string             `json:"role"`
+
+    ImpersonatorID *primitive.ObjectID `json:"impersonator_id,omitempty"`
     jwt.RegisteredClaims
 }
 
-func AuthMiddleware() gin.HandlerFunc {
+var (
+    errTenantInactive     = utils.NewError(utils.KindUnauthorized, "tenant_inactive", "tenant is inactive")
+    errAuthHeaderRequired = utils.NewError(utils.KindUnauthorized, utils.CodeUnauthorized, "Authorization header required")
+    errInvalidToken       = utils.NewError(utils.KindUnauthorized, utils.CodeUnauthorized, "Invalid token")
+    errMissingPermission  = utils.NewError(utils.KindForbidden, utils.CodeForbidden, "Forbidden: missing permission")
+)
+
+func AuthMiddleware(policy services.PolicyService, tenants services.TenantService) gin.HandlerFunc {
     return func(c *gin.Context) {
         authHeader := c.GetHeader("Authorization")
         if authHeader == "" {
-            utils.ErrorResponse(c, http.StatusUnauthorized, "Authorization header required")
+            utils.ProblemResponse(c, errAuthHeaderRequired, http.StatusUnauthorized)
             c.Abort()
             return
         }
 
-        tokenString := strings.Split(authHeader, " ")[1]
+        _, tokenString, _ := strings.Cut(authHeader, " ")
 
         claims := &Claims{}
         token, err := jwt.ParseWithClaims(tokenString, claims, func(t *jwt.Token) (interface{}, error) {
@@ -34,32 +47,58 @@ func AuthMiddleware() gin.HandlerFunc {
         })
 
         if err != nil || !token.Valid {
-            utils.ErrorResponse(c, http.StatusUnauthorized, "Invalid token")
+            utils.ProblemResponse(c, errInvalidToken, http.StatusUnauthorized)
+            c.Abort()
+            return
+        }
+
+        // Tokens issued before multi-tenancy belong to the default tenant.
+        if claims.TenantID == "" {
+            claims.TenantID = config.AppConfig.DefaultTenant
+        }
+
+        // Tokens outlive a tenant's deactivation, so check it on every request.
+        if err := tenants.CheckActive(claims.TenantID); err != nil {
+            if errors.Is(err, services.ErrTenantInactive) {
+                err = errTenantInactive
+            }
+            utils.ProblemResponse(c, err, http.StatusInternalServerError)
             c.Abort()
             return
         }
 
+        actor, err := policy.Actor(claims.TenantID, claims.UserID, claims.Role)
+        if e := utils.Classify(err); e != nil && e.Kind == utils.KindUnavailable {
+            utils.ProblemResponse(c, e, http.StatusServiceUnavailable)
+            c.Abort()
+            return
+        }
+        if err != nil {
+            utils.ProblemResponse(c, err, http.StatusInternalServerError)
+            c.Abort()
+            return
+        }
+        actor.ImpersonatorID = claims.ImpersonatorID
+
         c.Set("user_id", claims.UserID)
         c.Set("role", claims.Role)
+        c.Set("tenant_id", claims.TenantID)
+        c.Set("actor", actor)
         c.Next()
     }
 }
 
-func RoleRequired(roles ...string) gin.HandlerFunc {
+// RequirePermission rejects the request unless the caller holds perm in some
+// scope. Ownership and status conditions are checked later by the service.
+func RequirePermission(perm string) gin.HandlerFunc {
     return func(c *gin.Context) {
-        userRole, _ := c.Get("role")
-        allowed := false
-        for _, r := range roles {
-            if userRole == r {
-                allowed = true
-                break
-            }
-        }
-        if !allowed {
-            utils.ErrorResponse(c, http.StatusForbidden, "Forbidden: insufficient role")
+        c.Header("X-Permission-Required", perm)
+        actor := c.MustGet("actor").(models.Actor)
+        if !actor.Can(perm) {
+            utils.ProblemResponse(c, errMissingPermission.WithMessage("Forbidden: missing permission "+perm), http.StatusForbidden)
             c.Abort()
             return
         }
         c.Next()
     }
-}
\ No newline at end of file
+}
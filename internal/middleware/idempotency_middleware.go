@@ -0,0 +1,113 @@
+package middleware
+
+import (
+    "bytes"
+    "crypto/sha256"
+    "encoding/hex"
+    "errors"
+    "insurance-claims-api/internal/models"
+    "insurance-claims-api/internal/services"
+    "insurance-claims-api/internal/utils"
+    "io"
+    "net/http"
+
+    "github.com/gin-gonic/gin"
+)
+
+const maxIdempotencyKeyLength = 255
+
+// replayedHeaders are the response headers stored with an idempotent
+// response besides Content-Type, so a replay carries them too.
+var replayedHeaders = []string{"ETag", "Location"}
+
+var errIdempotencyKeyTooLong = utils.NewError(utils.KindBadRequest, utils.CodeBadRequest, "Idempotency-Key is too long")
+
+var errIdempotentBodyTooLarge = utils.NewError(utils.KindTooLarge, utils.CodeTooLarge, "request body is too large to be sent with an Idempotency-Key")
+
+type recordingWriter struct {
+    gin.ResponseWriter
+    body bytes.Buffer
+}
+
+func (w *recordingWriter) Write(b []byte) (int, error) {
+    w.body.Write(b)
+    return w.ResponseWriter.Write(b)
+}
+
+func (w *recordingWriter) WriteString(s string) (int, error) {
+    w.body.WriteString(s)
+    return w.ResponseWriter.WriteString(s)
+}
+
+// Idempotency makes POST, PATCH and DELETE requests carrying an
+// Idempotency-Key header safe to retry: the first response is stored per
+// user and key and replayed for retries with the same method, path and body.
+// Server errors are not stored, so they can be retried. The body has to be
+// buffered to be hashed, so it is capped at maxBody bytes.
+func Idempotency(svc services.IdempotencyService, maxBody int64) gin.HandlerFunc {
+    return func(c *gin.Context) {
+        key := c.GetHeader("Idempotency-Key")
+        switch c.Request.Method {
+        case http.MethodPost, http.MethodPatch, http.MethodDelete:
+        default:
+            c.Next()
+            return
+        }
+        if key == "" {
+            c.Next()
+            return
+        }
+        if len(key) > maxIdempotencyKeyLength {
+            utils.ProblemResponse(c, errIdempotencyKeyTooLong, http.StatusBadRequest)
+            c.Abort()
+            return
+        }
+
+        body, err := io.ReadAll(http.MaxBytesReader(c.Writer, c.Request.Body, maxBody))
+        if err != nil {
+            var tooLarge *http.MaxBytesError
+            if errors.As(err, &tooLarge) {
+                err = errIdempotentBodyTooLarge
+            }
+            utils.ProblemResponse(c, err, http.StatusBadRequest)
+            c.Abort()
+            return
+        }
+        c.Request.Body = io.NopCloser(bytes.NewReader(body))
+        hash := sha256.New()
+        io.WriteString(hash, c.Request.Method+" "+c.Request.URL.RequestURI()+"\n")
+        hash.Write(body)
+
+        actor := c.MustGet("actor").(models.Actor)
+        replay, err := svc.Begin(actor, key, hex.EncodeToString(hash.Sum(nil)))
+        if err != nil {
+            utils.ProblemResponse(c, err, http.StatusInternalServerError)
+            c.Abort()
+            return
+        }
+        if replay != nil {
+            for name, value := range replay.Headers {
+                c.Header(name, value)
+            }
+            c.Header("Idempotent-Replayed", "true")
+            c.Data(replay.StatusCode, replay.ContentType, replay.Body)
+            c.Abort()
+            return
+        }
+
+        w := &recordingWriter{ResponseWriter: c.Writer}
+        c.Writer = w
+        c.Next()
+        if w.Status() >= http.StatusInternalServerError {
+            svc.Release(actor, key)
+            return
+        }
+        headers := map[string]string{}
+        for _, name := range replayedHeaders {
+            if value := w.Header().Get(name); value != "" {
+                headers[name] = value
+            }
+        }
+        svc.Complete(actor, key, w.Status(), w.Header().Get("Content-Type"), headers, w.body.Bytes())
+    }
+}
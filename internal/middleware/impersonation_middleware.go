@@ -0,0 +1,42 @@
+package middleware
+
+import (
+    "insurance-claims-api/internal/models"
+    "insurance-claims-api/internal/services"
+    "insurance-claims-api/internal/utils"
+    "net/http"
+
+    "github.com/gin-gonic/gin"
+)
+
+var errImpersonationReadOnly = utils.NewError(utils.KindForbidden, utils.CodeForbidden, "impersonation sessions are read-only")
+
+// Impersonation makes impersonation tokens read-only and writes every request
+// made with one to the audit log, including the ones it rejects.
+func Impersonation(audit services.AuditService) gin.HandlerFunc {
+    return func(c *gin.Context) {
+        actor := c.MustGet("actor").(models.Actor)
+        if actor.ImpersonatorID == nil {
+            c.Next()
+            return
+        }
+
+        switch c.Request.Method {
+        case http.MethodGet, http.MethodHead, http.MethodOptions:
+            c.Next()
+        default:
+            utils.ProblemResponse(c, errImpersonationReadOnly, http.StatusForbidden)
+            c.Abort()
+        }
+
+        subjectID := actor.UserID
+        audit.Record(actor.TenantID, models.AuditLog{
+            Action:    models.AuditImpersonatedRequest,
+            ActorID:   *actor.ImpersonatorID,
+            SubjectID: &subjectID,
+            Method:    c.Request.Method,
+            Path:      c.Request.URL.RequestURI(),
+            Status:    c.Writer.Status(),
+        })
+    }
+}
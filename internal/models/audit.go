@@ -0,0 +1,32 @@
+package models
+
+import (
+    "time"
+
+    "go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type AuditLog struct {
+    ID        primitive.ObjectID     `bson:"_id,omitempty" json:"id"`
+    TenantID  string                 `bson:"tenant_id" json:"tenant_id"`
+    Action    string                 `bson:"action" json:"action"`
+    ActorID   primitive.ObjectID     `bson:"actor_id" json:"actor_id"`
+    SubjectID *primitive.ObjectID    `bson:"subject_id,omitempty" json:"subject_id,omitempty"`
+    Method    string                 `bson:"method,omitempty" json:"method,omitempty"`
+    Path      string                 `bson:"path,omitempty" json:"path,omitempty"`
+    Status    int                    `bson:"status,omitempty" json:"status,omitempty"`
+    Details   map[string]interface{} `bson:"details,omitempty" json:"details,omitempty"`
+    At        time.Time              `bson:"at" json:"at"`
+}
+
+const (
+    AuditImpersonationStarted = "impersonation.started"
+    AuditImpersonatedRequest  = "impersonation.request"
+    AuditClaimBulkAction      = "claim.bulk_action"
+)
+
+type AuditLogFilter struct {
+    Action    string `form:"action"`
+    ActorID   string `form:"actor_id"`
+    SubjectID string `form:"subject_id"`
+}
@@ -0,0 +1,35 @@
+package models
+
+import (
+    "go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const (
+    BulkReview  = "review"
+    BulkApprove = "approve"
+    BulkReject  = "reject"
+)
+
+// BulkActionRequest selects claims either by ID or with the GET /claims/all
+// filters. Note is the review note or the rejection reason.
+type BulkActionRequest struct {
+    IDs    []primitive.ObjectID `json:"ids" binding:"max=500"`
+    Filter *ClaimListQuery      `json:"filter"`
+    Note   string               `json:"note"`
+}
+
+type BulkItemResult struct {
+    ID        primitive.ObjectID `json:"id"`
+    Reference string             `json:"reference,omitempty"`
+    Success   bool               `json:"success"`
+    Code      string             `json:"code,omitempty"`
+    Error     string             `json:"error,omitempty"`
+}
+
+type BulkActionResult struct {
+    CorrelationID string           `json:"correlation_id"`
+    Action        string           `json:"action"`
+    Succeeded     int              `json:"succeeded"`
+    Failed        int              `json:"failed"`
+    Items         []BulkItemResult `json:"items"`
+}
@@ -8,11 +8,15 @@ import (
 type ClaimStatus string
 
 const (
-    Draft     ClaimStatus = "draft"
-    Submitted ClaimStatus = "submitted"
-    Reviewed  ClaimStatus = "reviewed"
-    Approved  ClaimStatus = "approved"
-    Rejected  ClaimStatus = "rejected"
+    Draft       ClaimStatus = "draft"
+    Submitted   ClaimStatus = "submitted"
+    Reviewed    ClaimStatus = "reviewed"
+    Approved    ClaimStatus = "approved"
+    Rejected    ClaimStatus = "rejected"
+    Expired     ClaimStatus = "expired"
+    FraudReview ClaimStatus = "fraud_review"
+    Withdrawn   ClaimStatus = "withdrawn"
+    Appealed    ClaimStatus = "appealed"
 )
 
 type ClaimHistory struct {
@@ -20,31 +24,129 @@ type ClaimHistory struct {
     ChangedBy primitive.ObjectID `bson:"changed_by" json:"changed_by"`
     ChangedAt time.Time          `bson:"changed_at" json:"changed_at"`
     Note      string             `bson:"note,omitempty" json:"note,omitempty"`
+    Event     string             `bson:"event,omitempty" json:"event,omitempty"`
+
+    // Set when ChangedBy acted under a delegation, e.g. "bob on behalf of alice".
+    OnBehalfOf *primitive.ObjectID `bson:"on_behalf_of,omitempty" json:"on_behalf_of,omitempty"`
+    Summary    string              `bson:"summary,omitempty" json:"summary,omitempty"`
+
+    // Set when the change was made automatically by a triage rule.
+    RuleID      string `bson:"rule_id,omitempty" json:"rule_id,omitempty"`
+    RuleVersion int    `bson:"rule_version,omitempty" json:"rule_version,omitempty"`
+
+    // Shared by every entry written by one bulk action.
+    CorrelationID string `bson:"correlation_id,omitempty" json:"correlation_id,omitempty"`
 }
 
 type Claim struct {
     ID           primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+    TenantID     string             `bson:"tenant_id" json:"tenant_id"`
+    Reference    string             `bson:"reference,omitempty" json:"reference,omitempty"`
+    ExternalRef  string             `bson:"external_reference,omitempty" json:"external_reference,omitempty"`
     UserID       primitive.ObjectID `bson:"user_id" json:"user_id"`
     PolicyNumber string             `bson:"policy_number" json:"policy_number" binding:"required"`
     ClaimAmount  float64            `bson:"claim_amount" json:"claim_amount" binding:"required"`
     Description  string             `bson:"description" json:"description" binding:"required"`
+    ClaimType    string             `bson:"claim_type,omitempty" json:"claim_type,omitempty"`
     Documents    []string           `bson:"documents,omitempty" json:"documents,omitempty"`
+    Generated    []ClaimDocument    `bson:"generated_documents,omitempty" json:"generated_documents,omitempty"`
     Status       ClaimStatus        `bson:"status" json:"status"`
     History      []ClaimHistory     `bson:"history" json:"history"`
+    Assignment   *ClaimAssignment   `bson:"assignment,omitempty" json:"assignment,omitempty"`
+    Priority     string             `bson:"priority,omitempty" json:"priority,omitempty"`
+    FraudFlagged bool               `bson:"fraud_flagged,omitempty" json:"fraud_flagged,omitempty"`
+    FraudScore   float64            `bson:"fraud_score" json:"fraud_score,omitempty"`
+    Fraud        *FraudAssessment   `bson:"fraud,omitempty" json:"fraud,omitempty"`
+    PolicyStart  *time.Time         `bson:"policy_start_date,omitempty" json:"policy_start_date,omitempty"`
+    IncidentDate *time.Time         `bson:"incident_date,omitempty" json:"incident_date,omitempty"`
+    Duplicates   []DuplicateLink    `bson:"duplicates,omitempty" json:"duplicates,omitempty"`
+    Appeal       *ClaimAppeal       `bson:"appeal,omitempty" json:"appeal,omitempty"`
+    SLA          []SLAState         `bson:"sla,omitempty" json:"sla,omitempty"`
+    SLAFlag      string             `bson:"sla_flag,omitempty" json:"sla_flag,omitempty"`
+    SLAPauses    []SLAPause         `bson:"sla_pauses,omitempty" json:"sla_pauses,omitempty"`
     CreatedAt    time.Time          `bson:"created_at" json:"created_at"`
     UpdatedAt    time.Time          `bson:"updated_at" json:"updated_at"`
+
+    // Version is incremented by every write and served as the claim's ETag.
+    // Claims stored before versioning have none and read as 0.
+    Version int64 `bson:"version" json:"version"`
+}
+
+// AnyVersion skips the If-Match check of a claim change.
+const AnyVersion int64 = -1
+
+// ClaimAssignment is the current owner of a claim in the work queue. Checkouts
+// expire so abandoned claims return to the pool; auto-assignments and
+// supervisor reassignments have no expiry.
+type ClaimAssignment struct {
+    AssigneeID primitive.ObjectID `bson:"assignee_id" json:"assignee_id"`
+    AssignedBy primitive.ObjectID `bson:"assigned_by" json:"assigned_by"`
+    AssignedAt time.Time          `bson:"assigned_at" json:"assigned_at"`
+    ExpiresAt  *time.Time         `bson:"expires_at,omitempty" json:"expires_at,omitempty"`
+}
+
+func (a *ClaimAssignment) ActiveAt(t time.Time) bool {
+    return a != nil && (a.ExpiresAt == nil || a.ExpiresAt.After(t))
+}
+
+// ClaimAppeal is the claimant's single appeal against a rejection. The
+// approver who rejected the claim may not decide the appeal.
+type ClaimAppeal struct {
+    Reason           string             `bson:"reason" json:"reason"`
+    Documents        []string           `bson:"documents" json:"documents"`
+    FiledAt          time.Time          `bson:"filed_at" json:"filed_at"`
+    OriginalApprover primitive.ObjectID `bson:"original_approver" json:"original_approver"`
+}
+
+type AppealClaimRequest struct {
+    Reason    string   `json:"reason" binding:"required"`
+    Documents []string `json:"documents" binding:"required,min=1"`
+}
+
+type AssignClaimRequest struct {
+    AssigneeID primitive.ObjectID `json:"assignee_id" binding:"required"`
 }
 
 type CreateClaimRequest struct {
-    PolicyNumber string   `json:"policy_number" binding:"required"`
-    ClaimAmount  float64  `json:"claim_amount" binding:"required"`
-    Description  string   `json:"description" binding:"required"`
-    Documents    []string `json:"documents,omitempty"`
-}
-
-type UpdateClaimRequest struct {
-    PolicyNumber string   `json:"policy_number,omitempty"`
-    ClaimAmount  float64  `json:"claim_amount,omitempty"`
-    Description  string   `json:"description,omitempty"`
-    Documents    []string `json:"documents,omitempty"`
-}
\ No newline at end of file
+    PolicyNumber string     `json:"policy_number" binding:"required,policy_number"`
+    ClaimAmount  float64    `json:"claim_amount" binding:"required,gt=0"`
+    Description  string     `json:"description" binding:"required,min=10,max=2000"`
+    ClaimType    string     `json:"claim_type,omitempty"`
+    Documents    []string   `json:"documents,omitempty"`
+    PolicyStart  *time.Time `json:"policy_start_date,omitempty"`
+    IncidentDate *time.Time `json:"incident_date,omitempty"`
+}
+
+// Media types accepted by PATCH /claims/:id. Plain application/json is
+// treated as a merge patch.
+const (
+    MergePatchContentType = "application/merge-patch+json"
+    JSONPatchContentType  = "application/json-patch+json"
+)
+
+// ClaimFields is the part of a claim PATCH /claims/:id can change. A patch is
+// applied to this document and the result is validated as a whole; null
+// clears a field. JSON names match the stored field names.
+type ClaimFields struct {
+    PolicyNumber string     `json:"policy_number" binding:"required,policy_number"`
+    ClaimAmount  float64    `json:"claim_amount" binding:"required,gt=0"`
+    Description  string     `json:"description" binding:"required,min=10,max=2000"`
+    ClaimType    string     `json:"claim_type"`
+    Documents    []string   `json:"documents"`
+    PolicyStart  *time.Time `json:"policy_start_date"`
+    IncidentDate *time.Time `json:"incident_date"`
+}
+
+// ClaimListQuery narrows and orders GET /claims/all. Reference matches by
+// prefix, e.g. "CLM-2026-". Status may be repeated;
+// Event matches claims whose history contains it, e.g. "reopened". Sort is a
+// field name, prefixed with "-" for descending.
+type ClaimListQuery struct {
+    Reference     string        `form:"reference" json:"reference,omitempty"`
+    Status        []ClaimStatus `form:"status" json:"status,omitempty"`
+    Event         string        `form:"event" json:"event,omitempty"`
+    MinFraudScore *float64      `form:"min_fraud_score" json:"min_fraud_score,omitempty"`
+    MaxFraudScore *float64      `form:"max_fraud_score" json:"max_fraud_score,omitempty"`
+    Duplicates    *bool         `form:"duplicates" json:"duplicates,omitempty"`
+    Sort          string        `form:"sort" json:"sort,omitempty" binding:"omitempty,oneof=created_at -created_at fraud_score -fraud_score claim_amount -claim_amount"`
+}
@@ -0,0 +1,44 @@
+package models
+
+import (
+    "time"
+
+    "go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// ClaimComment is one message in a claim's discussion. Internal comments are
+// only visible to staff, never to the claimant. Replies point at their
+// thread's first comment through ParentID.
+type ClaimComment struct {
+    ID          primitive.ObjectID   `bson:"_id,omitempty" json:"id"`
+    TenantID    string               `bson:"tenant_id" json:"tenant_id"`
+    ClaimID     primitive.ObjectID   `bson:"claim_id" json:"claim_id"`
+    ParentID    *primitive.ObjectID  `bson:"parent_id,omitempty" json:"parent_id,omitempty"`
+    AuthorID    primitive.ObjectID   `bson:"author_id" json:"author_id"`
+    Body        string               `bson:"body" json:"body"`
+    Internal    bool                 `bson:"internal" json:"internal"`
+    Mentions    []primitive.ObjectID `bson:"mentions,omitempty" json:"mentions,omitempty"`
+    Attachments []string             `bson:"attachments,omitempty" json:"attachments,omitempty"`
+    Edits       []CommentEdit        `bson:"edits,omitempty" json:"edits,omitempty"`
+    CreatedAt   time.Time            `bson:"created_at" json:"created_at"`
+    UpdatedAt   time.Time            `bson:"updated_at" json:"updated_at"`
+}
+
+// CommentEdit keeps a previous body of an edited comment.
+type CommentEdit struct {
+    Body     string    `bson:"body" json:"body"`
+    EditedAt time.Time `bson:"edited_at" json:"edited_at"`
+}
+
+// Attachments must be documents already attached to the claim.
+type CreateCommentRequest struct {
+    Body        string              `json:"body" binding:"required,max=5000"`
+    Internal    bool                `json:"internal"`
+    ParentID    *primitive.ObjectID `json:"parent_id,omitempty"`
+    Attachments []string            `json:"attachments,omitempty"`
+}
+
+type UpdateCommentRequest struct {
+    Body        string   `json:"body" binding:"required,max=5000"`
+    Attachments []string `json:"attachments,omitempty"`
+}
@@ -0,0 +1,38 @@
+package models
+
+import (
+    "time"
+
+    "go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// DelegablePermissions are the grants a user may hand over to a stand-in
+// while they are away.
+var DelegablePermissions = []string{PermClaimReadAny, PermClaimApprove, PermClaimReject}
+
+// Delegation lets DelegateID act with DelegatorID's approval authority
+// between StartsAt and EndsAt.
+type Delegation struct {
+    ID          primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+    TenantID    string             `bson:"tenant_id" json:"tenant_id"`
+    DelegatorID primitive.ObjectID `bson:"delegator_id" json:"delegator_id"`
+    DelegateID  primitive.ObjectID `bson:"delegate_id" json:"delegate_id"`
+    StartsAt    time.Time          `bson:"starts_at" json:"starts_at"`
+    EndsAt      time.Time          `bson:"ends_at" json:"ends_at"`
+    Reason      string             `bson:"reason,omitempty" json:"reason,omitempty"`
+    RevokedAt   *time.Time         `bson:"revoked_at,omitempty" json:"revoked_at,omitempty"`
+    CreatedAt   time.Time          `bson:"created_at" json:"created_at"`
+}
+
+type CreateDelegationRequest struct {
+    DelegateID primitive.ObjectID `json:"delegate_id" binding:"required"`
+    StartsAt   time.Time          `json:"starts_at" binding:"required"`
+    EndsAt     time.Time          `json:"ends_at" binding:"required,gtfield=StartsAt"`
+    Reason     string             `json:"reason,omitempty"`
+}
+
+// DelegatedGrants are the grants an actor currently holds on someone else's behalf.
+type DelegatedGrants struct {
+    DelegatorID primitive.ObjectID `json:"delegator_id"`
+    Grants      []Grant            `json:"grants"`
+}
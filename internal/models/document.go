@@ -0,0 +1,37 @@
+package models
+
+import (
+    "time"
+
+    "go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const (
+    DocClaimSummary   = "claim_summary"
+    DocDecisionLetter = "decision_letter"
+    DocPaymentAdvice  = "payment_advice"
+)
+
+// ClaimDocument is a PDF rendered from a template and stored in GridFS;
+// ID is the file ID. TemplateVersion records which revision produced it.
+type ClaimDocument struct {
+    ID              primitive.ObjectID `bson:"_id" json:"id"`
+    Kind            string             `bson:"kind" json:"kind"`
+    Language        string             `bson:"language" json:"language"`
+    TemplateVersion int                `bson:"template_version" json:"template_version"`
+    FileName        string             `bson:"file_name" json:"file_name"`
+    GeneratedBy     primitive.ObjectID `bson:"generated_by" json:"generated_by"`
+    GeneratedAt     time.Time          `bson:"generated_at" json:"generated_at"`
+}
+
+type GenerateDocumentRequest struct {
+    Kind     string `json:"kind" binding:"required,oneof=claim_summary decision_letter payment_advice"`
+    Language string `json:"language" binding:"omitempty,oneof=id en"`
+    Version  int    `json:"version"`
+}
+
+type DocumentTemplate struct {
+    Kind     string `json:"kind"`
+    Language string `json:"language"`
+    Version  int    `json:"version"`
+}
@@ -0,0 +1,11 @@
+package models
+
+import "go.mongodb.org/mongo-driver/bson/primitive"
+
+// DuplicateLink points at another claim by the same claimant that looks like
+// the same expense. Score is between 0 and 1.
+type DuplicateLink struct {
+    ClaimID primitive.ObjectID `bson:"claim_id" json:"claim_id"`
+    Score   float64            `bson:"score" json:"score"`
+    Reasons []string           `bson:"reasons" json:"reasons"`
+}
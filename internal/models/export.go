@@ -0,0 +1,40 @@
+package models
+
+import (
+    "time"
+
+    "go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const (
+    ExportPending = "pending"
+    ExportRunning = "running"
+    ExportDone    = "done"
+    ExportFailed  = "failed"
+)
+
+// ExportRequest accepts the GET /claims/all filters plus the output options.
+// Columns is a comma separated list; History writes one row per history
+// entry.
+type ExportRequest struct {
+    ClaimListQuery `bson:",inline"`
+    Format         string `form:"format" json:"format" binding:"omitempty,oneof=csv ndjson xlsx"`
+    Columns        string `form:"columns" json:"columns"`
+    History        bool   `form:"history" json:"history"`
+}
+
+// ExportJob is an export too large to stream in the request; the result is
+// kept in GridFS until downloaded.
+type ExportJob struct {
+    ID         primitive.ObjectID  `bson:"_id,omitempty" json:"id"`
+    TenantID   string              `bson:"tenant_id" json:"tenant_id"`
+    UserID     primitive.ObjectID  `bson:"user_id" json:"user_id"`
+    Status     string              `bson:"status" json:"status"`
+    Request    ExportRequest       `bson:"request" json:"request"`
+    Rows       int64               `bson:"rows" json:"rows"`
+    FileID     *primitive.ObjectID `bson:"file_id,omitempty" json:"-"`
+    FileName   string              `bson:"file_name" json:"file_name"`
+    Error      string              `bson:"error,omitempty" json:"error,omitempty"`
+    CreatedAt  time.Time           `bson:"created_at" json:"created_at"`
+    FinishedAt *time.Time          `bson:"finished_at,omitempty" json:"finished_at,omitempty"`
+}
@@ -0,0 +1,19 @@
+package models
+
+import "time"
+
+// FraudSignal is one scorer's contribution to a claim's fraud score, with a
+// reason a reviewer can act on.
+type FraudSignal struct {
+    Scorer string  `bson:"scorer" json:"scorer"`
+    Points float64 `bson:"points" json:"points"`
+    Reason string  `bson:"reason" json:"reason"`
+}
+
+// FraudAssessment is the result of the scoring pipeline. Score is capped at
+// 100.
+type FraudAssessment struct {
+    Score    float64       `bson:"score" json:"score"`
+    Signals  []FraudSignal `bson:"signals,omitempty" json:"signals,omitempty"`
+    ScoredAt time.Time     `bson:"scored_at" json:"scored_at"`
+}
@@ -0,0 +1,23 @@
+package models
+
+import (
+    "time"
+)
+
+// IdempotencyRecord remembers the first response to a request sent with an
+// Idempotency-Key so retries can be answered with it. ID is
+// "<tenant>/<user>/<key>".
+type IdempotencyRecord struct {
+    ID          string            `bson:"_id"`
+    TenantID    string            `bson:"tenant_id"`
+    UserID      string            `bson:"user_id"`
+    Key         string            `bson:"key"`
+    RequestHash string            `bson:"request_hash"`
+    Completed   bool              `bson:"completed"`
+    StatusCode  int               `bson:"status_code,omitempty"`
+    ContentType string            `bson:"content_type,omitempty"`
+    Headers     map[string]string `bson:"headers,omitempty"`
+    Body        []byte            `bson:"body,omitempty"`
+    CreatedAt   time.Time         `bson:"created_at"`
+    ExpiresAt   time.Time         `bson:"expires_at"`
+}
@@ -0,0 +1,55 @@
+package models
+
+import (
+    "time"
+)
+
+const (
+    ImportCreated = "created"
+    ImportValid   = "valid"
+    ImportSkipped = "skipped"
+    ImportInvalid = "invalid"
+)
+
+// ImportRecord is one claim of a bulk import. It is validated like
+// CreateClaimRequest; ExternalReference is the claim's ID in the source system
+// and makes re-running an import safe. Owner and ChangedBy are usernames and
+// default to the importing user.
+type ImportRecord struct {
+    CreateClaimRequest
+    ExternalReference string          `json:"external_reference" binding:"required"`
+    Owner             string          `json:"owner,omitempty"`
+    Status            ClaimStatus     `json:"status,omitempty" binding:"omitempty,oneof=draft submitted reviewed approved rejected expired fraud_review withdrawn appealed"`
+    CreatedAt         *time.Time      `json:"created_at,omitempty"`
+    History           []ImportHistory `json:"history,omitempty" binding:"dive"`
+}
+
+type ImportHistory struct {
+    Status    ClaimStatus `json:"status" binding:"required,oneof=draft submitted reviewed approved rejected expired fraud_review withdrawn appealed"`
+    ChangedBy string      `json:"changed_by,omitempty"`
+    ChangedAt time.Time   `json:"changed_at" binding:"required"`
+    Note      string      `json:"note,omitempty"`
+    Event     string      `json:"event,omitempty"`
+}
+
+// ImportRowResult reports the outcome of one input row; Row counts records
+// from 1, not counting the CSV header.
+type ImportRowResult struct {
+    Row               int    `json:"row"`
+    ExternalReference string `json:"external_reference,omitempty"`
+    Result            string `json:"result"`
+    ClaimID           string `json:"claim_id,omitempty"`
+    Reference         string `json:"reference,omitempty"`
+    Code              string `json:"code,omitempty"`
+    Error             string `json:"error,omitempty"`
+}
+
+type ImportReport struct {
+    DryRun  bool              `json:"dry_run"`
+    Total   int               `json:"total"`
+    Created int               `json:"created"`
+    Valid   int               `json:"valid"`
+    Skipped int               `json:"skipped"`
+    Invalid int               `json:"invalid"`
+    Rows    []ImportRowResult `json:"rows"`
+}
@@ -0,0 +1,41 @@
+package models
+
+import (
+    "time"
+
+    "go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const (
+    JobTriggerSchedule = "schedule"
+    JobTriggerManual   = "manual"
+
+    JobRunning   = "running"
+    JobSucceeded = "succeeded"
+    JobFailed    = "failed"
+)
+
+// JobRun records one execution of a background job on one replica.
+type JobRun struct {
+    ID         primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+    Job        string             `bson:"job" json:"job"`
+    Trigger    string             `bson:"trigger" json:"trigger"`
+    Instance   string             `bson:"instance" json:"instance"`
+    Status     string             `bson:"status" json:"status"`
+    Result     string             `bson:"result,omitempty" json:"result,omitempty"`
+    Error      string             `bson:"error,omitempty" json:"error,omitempty"`
+    StartedAt  time.Time          `bson:"started_at" json:"started_at"`
+    FinishedAt *time.Time         `bson:"finished_at,omitempty" json:"finished_at,omitempty"`
+}
+
+type JobInfo struct {
+    Name        string    `json:"name"`
+    Description string    `json:"description"`
+    Schedule    string    `json:"schedule"`
+    NextRunAt   time.Time `json:"next_run_at"`
+    LastRun     *JobRun   `json:"last_run,omitempty"`
+}
+
+type JobRunsQuery struct {
+    Limit int `form:"limit,default=20" json:"limit" binding:"min=1"`
+}
@@ -0,0 +1,91 @@
+package models
+
+import (
+    "time"
+
+    "go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const (
+    NotificationMention     = "mention"
+    NotificationClaimStatus = "claim_status"
+    NotificationSLA         = "sla_escalation"
+)
+
+// Claim events a claimant is notified about. Each has an email template per
+// language; see services/templates/email.
+const (
+    EventClaimSubmitted     = "submitted"
+    EventClaimReviewed      = "reviewed"
+    EventClaimApproved      = "approved"
+    EventClaimRejected      = "rejected"
+    EventClaimInfoRequested = "info_requested"
+    EventClaimAppealed      = "appealed"
+    EventClaimReopened      = "reopened"
+)
+
+// EventReportDigest is the periodic claims summary emailed to users who may
+// read reports. Like claim events it can be muted.
+const EventReportDigest = "report_digest"
+
+// EventSLAEscalated tells supervisors that a claim breached an SLA.
+const EventSLAEscalated = "sla_escalated"
+
+// Notification is an in-app message for one user.
+type Notification struct {
+    ID        primitive.ObjectID  `bson:"_id,omitempty" json:"id"`
+    TenantID  string              `bson:"tenant_id" json:"tenant_id"`
+    UserID    primitive.ObjectID  `bson:"user_id" json:"user_id"`
+    Type      string              `bson:"type" json:"type"`
+    ClaimID   *primitive.ObjectID `bson:"claim_id,omitempty" json:"claim_id,omitempty"`
+    CommentID *primitive.ObjectID `bson:"comment_id,omitempty" json:"comment_id,omitempty"`
+    Message   string              `bson:"message" json:"message"`
+    ReadAt    *time.Time          `bson:"read_at,omitempty" json:"read_at,omitempty"`
+    CreatedAt time.Time           `bson:"created_at" json:"created_at"`
+}
+
+// NotificationPreferences are stored per user; users without a document get
+// DefaultNotificationPreferences.
+type NotificationPreferences struct {
+    UserID   primitive.ObjectID `bson:"user_id" json:"user_id"`
+    TenantID string             `bson:"tenant_id" json:"tenant_id"`
+    Email    bool               `bson:"email" json:"email"`
+    InApp    bool               `bson:"in_app" json:"in_app"`
+    Language string             `bson:"language,omitempty" json:"language,omitempty"`
+    Muted    []string           `bson:"muted,omitempty" json:"muted,omitempty"`
+}
+
+func DefaultNotificationPreferences(userID primitive.ObjectID) NotificationPreferences {
+    return NotificationPreferences{UserID: userID, Email: true, InApp: true}
+}
+
+type UpdateNotificationPreferencesRequest struct {
+    Email    *bool    `json:"email"`
+    InApp    *bool    `json:"in_app"`
+    Language string   `json:"language" binding:"omitempty,oneof=id en"`
+    Muted    []string `json:"muted" binding:"omitempty,dive,oneof=submitted reviewed approved rejected info_requested appealed reopened report_digest sla_escalated"`
+}
+
+const (
+    EmailPending = "pending"
+    EmailSent    = "sent"
+    EmailFailed  = "failed"
+)
+
+// EmailMessage is an outbox entry. The dispatcher job sends pending messages
+// and retries failures with backoff until the attempt limit.
+type EmailMessage struct {
+    ID            primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+    TenantID      string             `bson:"tenant_id" json:"tenant_id"`
+    UserID        primitive.ObjectID `bson:"user_id" json:"user_id"`
+    To            string             `bson:"to" json:"to"`
+    Subject       string             `bson:"subject" json:"subject"`
+    Body          string             `bson:"body" json:"body"`
+    Event         string             `bson:"event" json:"event"`
+    Status        string             `bson:"status" json:"status"`
+    Attempts      int                `bson:"attempts" json:"attempts"`
+    NextAttemptAt time.Time          `bson:"next_attempt_at" json:"next_attempt_at"`
+    LastError     string             `bson:"last_error,omitempty" json:"last_error,omitempty"`
+    CreatedAt     time.Time          `bson:"created_at" json:"created_at"`
+    SentAt        *time.Time         `bson:"sent_at,omitempty" json:"sent_at,omitempty"`
+}
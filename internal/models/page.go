@@ -0,0 +1,7 @@
+package models
+
+// PageQuery is the page and page size of a paginated listing.
+type PageQuery struct {
+    Page  int `form:"page,default=1" json:"page" binding:"min=1"`
+    Limit int `form:"limit,default=10" json:"limit" binding:"min=1"`
+}
@@ -0,0 +1,153 @@
+package models
+
+import (
+    "strings"
+    "time"
+
+    "go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Permission names follow resource:action[:scope]. A scope of "own" limits the
+// grant to resources the caller owns; "any" lifts that restriction.
+const (
+    PermClaimCreate      = "claim:create"
+    PermClaimRead        = "claim:read"
+    PermClaimReadOwn     = "claim:read:own"
+    PermClaimReadAny     = "claim:read:any"
+    PermClaimUpdate      = "claim:update"
+    PermClaimUpdateOwn   = "claim:update:own"
+    PermClaimUpdateAny   = "claim:update:any"
+    PermClaimDeleteOwn   = "claim:delete:own"
+    PermClaimSubmitOwn   = "claim:submit:own"
+    PermClaimReview      = "claim:review"
+    PermClaimApprove     = "claim:approve"
+    PermClaimReject      = "claim:reject"
+    PermClaimAssign      = "claim:assign"
+    PermClaimRequestInfo = "claim:request-info"
+    PermClaimFraudReview = "claim:fraud-review"
+    PermClaimWithdrawOwn = "claim:withdraw:own"
+    PermClaimAppealOwn   = "claim:appeal:own"
+    PermClaimReopen      = "claim:reopen"
+    PermClaimImport      = "claim:import"
+
+    PermClaimDocumentGenerate = "claim:document:generate"
+
+    PermQueueWork           = "queue:work"
+    PermDelegationManageOwn = "delegation:manage:own"
+    PermSLAManage           = "sla:manage"
+
+    PermRoleManage      = "role:manage"
+    PermTenantManage    = "tenant:manage"
+    PermTenantCreate    = "tenant:create"
+    PermUserImpersonate = "user:impersonate"
+    PermAuditRead       = "audit:read"
+    PermJobManage       = "job:manage"
+    PermRuleManage      = "rule:manage"
+    PermReportRead      = "report:read"
+
+    // PermSelf covers the caller's own profile and notifications. Every
+    // authenticated actor holds it.
+    PermSelf = "self"
+)
+
+// KnownPermissions lists every permission a role binding may grant.
+var KnownPermissions = []string{
+    PermClaimCreate, PermClaimRead, PermClaimReadOwn, PermClaimReadAny,
+    PermClaimUpdate, PermClaimUpdateOwn, PermClaimUpdateAny, PermClaimDeleteOwn,
+    PermClaimSubmitOwn, PermClaimReview, PermClaimApprove, PermClaimReject,
+    PermClaimAssign, PermClaimRequestInfo, PermClaimFraudReview, PermClaimWithdrawOwn,
+    PermClaimAppealOwn, PermClaimReopen, PermClaimImport, PermClaimDocumentGenerate,
+    PermQueueWork, PermDelegationManageOwn, PermSLAManage,
+    PermRoleManage, PermTenantManage, PermUserImpersonate, PermAuditRead,
+    PermJobManage, PermRuleManage, PermReportRead, PermSelf,
+}
+
+// PlatformPermissions act across tenants, so they are never part of a
+// tenant's role bindings. Admins of the platform tenant hold them implicitly.
+var PlatformPermissions = []string{PermTenantCreate, PermJobManage}
+
+// IsKnownPermission reports whether perm may appear in a role binding.
+func IsKnownPermission(perm string) bool {
+    for _, p := range KnownPermissions {
+        if p == perm {
+            return true
+        }
+    }
+    return false
+}
+
+// IsPlatformPermission reports whether perm is, or covers, a platform
+// permission.
+func IsPlatformPermission(perm string) bool {
+    for _, p := range PlatformPermissions {
+        if (Grant{Permission: perm}).Satisfies(p) {
+            return true
+        }
+    }
+    return false
+}
+
+// Grant binds a permission to optional attribute conditions.
+type Grant struct {
+    Permission string        `bson:"permission" json:"permission" binding:"required"`
+    Statuses   []ClaimStatus `bson:"statuses,omitempty" json:"statuses,omitempty"`
+}
+
+// OwnOnly reports whether the grant only applies to the caller's own resources.
+func (g Grant) OwnOnly() bool {
+    return strings.HasSuffix(g.Permission, ":own")
+}
+
+// Satisfies reports whether the grant covers perm, e.g. "claim:read:any"
+// satisfies both "claim:read:any" and "claim:read".
+func (g Grant) Satisfies(perm string) bool {
+    return g.Permission == perm || strings.HasPrefix(g.Permission, perm+":")
+}
+
+type RoleBinding struct {
+    ID        primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+    TenantID  string             `bson:"tenant_id" json:"tenant_id"`
+    Role      string             `bson:"role" json:"role"`
+    Grants    []Grant            `bson:"grants" json:"grants"`
+    UpdatedAt time.Time          `bson:"updated_at" json:"updated_at"`
+}
+
+type UpdateRoleBindingRequest struct {
+    Grants []Grant `json:"grants" binding:"required,dive"`
+}
+
+// Actor is the authenticated caller together with its effective grants.
+type Actor struct {
+    UserID   primitive.ObjectID `json:"user_id"`
+    TenantID string             `json:"tenant_id"`
+    Role     string             `json:"role"`
+    Grants   []Grant            `json:"grants"`
+
+    Delegated []DelegatedGrants `json:"delegated,omitempty"`
+
+    // ImpersonatorID is the support user behind a read-only impersonation token.
+    ImpersonatorID *primitive.ObjectID `json:"impersonator_id,omitempty"`
+}
+
+// Can reports whether the actor holds perm, either directly or through an
+// active delegation.
+func (a Actor) Can(perm string) bool {
+    for _, g := range a.Grants {
+        if g.Satisfies(perm) {
+            return true
+        }
+    }
+    for _, d := range a.Delegated {
+        for _, g := range d.Grants {
+            if g.Satisfies(perm) {
+                return true
+            }
+        }
+    }
+    return false
+}
+
+// OwnCan is Can without delegated grants; authority cannot be re-delegated.
+func (a Actor) OwnCan(perm string) bool {
+    return Actor{Grants: a.Grants}.Can(perm)
+}
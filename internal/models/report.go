@@ -0,0 +1,80 @@
+package models
+
+import (
+    "time"
+
+    "go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// ReportQuery filters reports by claim creation date (summary) or by the date
+// of the status change (throughput, cycle times). To is inclusive.
+type ReportQuery struct {
+    From      *time.Time `form:"from" time_format:"2006-01-02"`
+    To        *time.Time `form:"to" time_format:"2006-01-02"`
+    GroupBy   string     `form:"group_by" binding:"omitempty,oneof=day week month"`
+    ClaimType string     `form:"claim_type"`
+}
+
+type StatusTotal struct {
+    Status ClaimStatus `bson:"status" json:"status"`
+    Count  int64       `bson:"count" json:"count"`
+    Amount float64     `bson:"amount" json:"amount"`
+}
+
+type PeriodStatusTotal struct {
+    Period time.Time   `bson:"period" json:"period"`
+    Status ClaimStatus `bson:"status" json:"status"`
+    Count  int64       `bson:"count" json:"count"`
+    Amount float64     `bson:"amount" json:"amount"`
+}
+
+type DecisionRatio struct {
+    ClaimType     string  `bson:"claim_type" json:"claim_type"`
+    Approved      int64   `bson:"approved" json:"approved"`
+    Rejected      int64   `bson:"rejected" json:"rejected"`
+    ApprovalRate  float64 `bson:"-" json:"approval_rate"`
+    RejectionRate float64 `bson:"-" json:"rejection_rate"`
+}
+
+// Throughput counts the status changes one person made in a period.
+type Throughput struct {
+    Period   time.Time          `bson:"period" json:"period"`
+    UserID   primitive.ObjectID `bson:"user_id" json:"user_id"`
+    Username string             `bson:"username" json:"username"`
+    Status   ClaimStatus        `bson:"status" json:"status"`
+    Count    int64              `bson:"count" json:"count"`
+}
+
+type SummaryReport struct {
+    ByStatus   []StatusTotal       `json:"by_status"`
+    ByPeriod   []PeriodStatusTotal `json:"by_period"`
+    Ratios     []DecisionRatio     `json:"ratios"`
+    Throughput []Throughput        `json:"throughput"`
+}
+
+// DurationStats summarises durations in hours.
+type DurationStats struct {
+    Claims int     `json:"claims"`
+    Avg    float64 `json:"avg_hours"`
+    P50    float64 `json:"p50_hours"`
+    P90    float64 `json:"p90_hours"`
+    P95    float64 `json:"p95_hours"`
+}
+
+type StageDuration struct {
+    Status ClaimStatus `json:"status"`
+    DurationStats
+}
+
+type PeriodDuration struct {
+    Period time.Time `json:"period"`
+    DurationStats
+}
+
+// CycleTimeReport covers time spent in each status and the time from first
+// submission to the final approval or rejection.
+type CycleTimeReport struct {
+    Stages           []StageDuration  `json:"stages"`
+    Decision         DurationStats    `json:"decision"`
+    DecisionByPeriod []PeriodDuration `json:"decision_by_period"`
+}
@@ -0,0 +1,97 @@
+package models
+
+import (
+    "time"
+
+    "go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const (
+    RuleAutoReview  = "auto_review"
+    RuleAutoApprove = "auto_approve"
+
+    PriorityLow    = "low"
+    PriorityNormal = "normal"
+    PriorityHigh   = "high"
+    PriorityUrgent = "urgent"
+)
+
+// ClaimRule is one version of a triage rule evaluated when a claim is
+// submitted. Editing a rule stores a new version; only the Current version of
+// each RuleID is evaluated.
+type ClaimRule struct {
+    ID         primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+    TenantID   string             `bson:"tenant_id" json:"tenant_id"`
+    RuleID     string             `bson:"rule_id" json:"rule_id"`
+    Version    int                `bson:"version" json:"version"`
+    Current    bool               `bson:"current" json:"current"`
+    Name       string             `bson:"name" json:"name"`
+    Priority   int                `bson:"priority" json:"priority"`
+    Enabled    bool               `bson:"enabled" json:"enabled"`
+    Conditions RuleConditions     `bson:"conditions" json:"conditions"`
+    Actions    RuleActions        `bson:"actions" json:"actions"`
+    CreatedBy  primitive.ObjectID `bson:"created_by" json:"created_by"`
+    CreatedAt  time.Time          `bson:"created_at" json:"created_at"`
+}
+
+// RuleConditions must all hold for a rule to match. Unset fields are ignored.
+// Claimant history counts the claimant's other non-draft claims created in
+// the last HistoryDays days (default 365).
+type RuleConditions struct {
+    MinAmount       *float64 `bson:"min_amount,omitempty" json:"min_amount,omitempty"`
+    MaxAmount       *float64 `bson:"max_amount,omitempty" json:"max_amount,omitempty"`
+    ClaimTypes      []string `bson:"claim_types,omitempty" json:"claim_types,omitempty"`
+    MinDocuments    *int     `bson:"min_documents,omitempty" json:"min_documents,omitempty"`
+    MaxDocuments    *int     `bson:"max_documents,omitempty" json:"max_documents,omitempty"`
+    MaxPriorClaims  *int     `bson:"max_prior_claims,omitempty" json:"max_prior_claims,omitempty"`
+    MaxPriorRejects *int     `bson:"max_prior_rejects,omitempty" json:"max_prior_rejects,omitempty"`
+    HistoryDays     int      `bson:"history_days,omitempty" json:"history_days,omitempty" binding:"omitempty,gt=0"`
+}
+
+type RuleActions struct {
+    Decision  string              `bson:"decision,omitempty" json:"decision,omitempty" binding:"omitempty,oneof=auto_review auto_approve"`
+    AssignTo  *primitive.ObjectID `bson:"assign_to,omitempty" json:"assign_to,omitempty"`
+    FlagFraud bool                `bson:"flag_fraud,omitempty" json:"flag_fraud,omitempty"`
+    Priority  string              `bson:"priority,omitempty" json:"priority,omitempty" binding:"omitempty,oneof=low normal high urgent"`
+}
+
+type ClaimRuleRequest struct {
+    RuleID     string         `json:"rule_id,omitempty"`
+    Name       string         `json:"name" binding:"required"`
+    Priority   int            `json:"priority"`
+    Enabled    *bool          `json:"enabled,omitempty"`
+    Conditions RuleConditions `json:"conditions"`
+    Actions    RuleActions    `json:"actions"`
+}
+
+// SimulateRulesRequest evaluates rules against a stored claim (ClaimID) or a
+// hypothetical one (Claim, filed by UserID) without changing anything. When
+// Rules is set those candidate rules are used instead of the stored ones.
+type SimulateRulesRequest struct {
+    ClaimID *primitive.ObjectID `json:"claim_id,omitempty"`
+    Claim   *CreateClaimRequest `json:"claim,omitempty"`
+    UserID  *primitive.ObjectID `json:"user_id,omitempty"`
+    Rules   []ClaimRuleRequest  `json:"rules,omitempty" binding:"dive"`
+}
+
+type RuleMatch struct {
+    RuleID  string `json:"rule_id"`
+    Version int    `json:"version"`
+    Name    string `json:"name"`
+}
+
+// RuleOutcome is the combined effect of every matching rule. For each action
+// the highest-priority matching rule wins; a fraud flag sends the claim to
+// fraud review instead of any automatic decision.
+type RuleOutcome struct {
+    Matched      []RuleMatch         `json:"matched"`
+    Status       ClaimStatus         `json:"status"`
+    Decision     string              `json:"decision,omitempty"`
+    DecisionRule *RuleMatch          `json:"decision_rule,omitempty"`
+    AssignTo     *primitive.ObjectID `json:"assign_to,omitempty"`
+    AssignRule   *RuleMatch          `json:"assign_rule,omitempty"`
+    FlagFraud    bool                `json:"flag_fraud"`
+    FraudRule    *RuleMatch          `json:"fraud_rule,omitempty"`
+    Priority     string              `json:"priority,omitempty"`
+    PriorityRule *RuleMatch          `json:"priority_rule,omitempty"`
+}
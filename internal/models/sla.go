@@ -0,0 +1,58 @@
+package models
+
+import (
+    "time"
+
+    "go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const (
+    SLAOnTrack  = "on_track"
+    SLAAtRisk   = "at_risk"
+    SLABreached = "breached"
+)
+
+// SLAPolicy sets a deadline, in business days, for the time a claim spends in
+// Statuses. A ClaimType of "" applies to every claim type; a more specific
+// policy with the same name takes precedence.
+type SLAPolicy struct {
+    ID           primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+    TenantID     string             `bson:"tenant_id" json:"tenant_id"`
+    Name         string             `bson:"name" json:"name" binding:"required"`
+    Statuses     []ClaimStatus      `bson:"statuses" json:"statuses" binding:"required,min=1"`
+    ClaimType    string             `bson:"claim_type,omitempty" json:"claim_type,omitempty"`
+    BusinessDays float64            `bson:"business_days" json:"business_days" binding:"required,gt=0"`
+    AtRiskRatio  float64            `bson:"at_risk_ratio,omitempty" json:"at_risk_ratio,omitempty" binding:"omitempty,gt=0,lt=1"`
+    CreatedAt    time.Time          `bson:"created_at" json:"created_at"`
+    UpdatedAt    time.Time          `bson:"updated_at" json:"updated_at"`
+}
+
+// BusinessCalendar defines which days count towards SLA deadlines.
+type BusinessCalendar struct {
+    TenantID    string         `bson:"tenant_id" json:"tenant_id"`
+    Timezone    string         `bson:"timezone" json:"timezone" binding:"required"`
+    WorkingDays []time.Weekday `bson:"working_days" json:"working_days" binding:"required,min=1,dive,min=0,max=6"`
+    Holidays    []string       `bson:"holidays" json:"holidays" binding:"dive,datetime=2006-01-02"`
+    UpdatedAt   time.Time      `bson:"updated_at" json:"updated_at"`
+}
+
+// SLAState is the last evaluation of one policy against a claim.
+type SLAState struct {
+    PolicyID    primitive.ObjectID   `bson:"policy_id" json:"policy_id"`
+    Name        string               `bson:"name" json:"name"`
+    State       string               `bson:"state" json:"state"`
+    StartedAt   time.Time            `bson:"started_at" json:"started_at"`
+    DueAt       time.Time            `bson:"due_at" json:"due_at"`
+    ElapsedDays float64              `bson:"elapsed_days" json:"elapsed_days"`
+    EvaluatedAt time.Time            `bson:"evaluated_at" json:"evaluated_at"`
+    EscalatedAt *time.Time           `bson:"escalated_at,omitempty" json:"escalated_at,omitempty"`
+    EscalatedTo []primitive.ObjectID `bson:"escalated_to,omitempty" json:"escalated_to,omitempty"`
+}
+
+// SLAPause is a period while the claim waits on the claimant; it does not
+// count towards any deadline. An open pause has no EndedAt.
+type SLAPause struct {
+    StartedAt time.Time  `bson:"started_at" json:"started_at"`
+    EndedAt   *time.Time `bson:"ended_at,omitempty" json:"ended_at,omitempty"`
+    Reason    string     `bson:"reason,omitempty" json:"reason,omitempty"`
+}
@@ -0,0 +1,35 @@
+package models
+
+import "time"
+
+// Tenant is an insurer sharing this deployment. Its ID is the short code
+// carried in JWTs and stored as tenant_id on every tenant-owned document.
+type Tenant struct {
+    ID        string         `bson:"_id" json:"id"`
+    Name      string         `bson:"name" json:"name"`
+    Active    bool           `bson:"active" json:"active"`
+    Settings  TenantSettings `bson:"settings" json:"settings"`
+    CreatedAt time.Time      `bson:"created_at" json:"created_at"`
+    UpdatedAt time.Time      `bson:"updated_at" json:"updated_at"`
+}
+
+// TenantSettings override deployment-wide configuration for one tenant.
+// Zero values fall back to the environment setting of the same name.
+type TenantSettings struct {
+    AssignmentStrategy   string  `bson:"assignment_strategy,omitempty" json:"assignment_strategy,omitempty" binding:"omitempty,oneof=manual round_robin least_workload"`
+    FraudReviewThreshold float64 `bson:"fraud_review_threshold,omitempty" json:"fraud_review_threshold,omitempty" binding:"omitempty,gt=0,lte=100"`
+    AppealWindowDays     int     `bson:"appeal_window_days,omitempty" json:"appeal_window_days,omitempty" binding:"omitempty,min=1,max=365"`
+    DuplicateHardBlock   *bool   `bson:"duplicate_hard_block,omitempty" json:"duplicate_hard_block,omitempty"`
+}
+
+type CreateTenantRequest struct {
+    ID       string         `json:"id" binding:"required,alphanum,lowercase,max=32"`
+    Name     string         `json:"name" binding:"required"`
+    Settings TenantSettings `json:"settings"`
+}
+
+type UpdateTenantRequest struct {
+    Name     string          `json:"name,omitempty"`
+    Active   *bool           `json:"active,omitempty"`
+    Settings *TenantSettings `json:"settings,omitempty"`
+}
@@ -2,14 +2,29 @@ package models
 
 import "go.mongodb.org/mongo-driver/bson/primitive"
 
+const (
+    RoleUser       = "user"
+    RoleVerifier   = "verifier"
+    RoleApprover   = "approver"
+    RoleSupervisor = "supervisor"
+    RoleAdmin      = "admin"
+)
+
 type User struct {
     ID       primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+    TenantID string `bson:"tenant_id" json:"tenant_id"`
     Username string `bson:"username" json:"username"`
     Password string `bson:"password" json:"-"`
-    Role     string `bson:"role" json:"role"` // user, verifier, approver
+    Role     string `bson:"role" json:"role"` // user, verifier, approver, supervisor, admin
+
+    // Set for users provisioned through single sign-on; such users have no local password.
+    Provider string `bson:"provider,omitempty" json:"provider,omitempty"`
+    Subject  string `bson:"subject,omitempty" json:"-"`
+    Email    string `bson:"email,omitempty" json:"email,omitempty"`
 }
 
 type LoginRequest struct {
+    Tenant   string `json:"tenant"` // defaults to DEFAULT_TENANT
     Username string `json:"username" binding:"required"`
     Password string `json:"password" binding:"required"`
 }
@@ -18,7 +33,19 @@ type LoginResponse struct {
     Token string `json:"token"`
     User  struct {
         ID   primitive.ObjectID `json:"id"`
+        TenantID string `json:"tenant_id"`
         Username string `json:"username"`
         Role string `json:"role"`
     } `json:"user"`
-}
\ No newline at end of file
+    ImpersonatedBy *primitive.ObjectID `json:"impersonated_by,omitempty"`
+}
+
+type ImpersonateRequest struct {
+    UserID primitive.ObjectID `json:"user_id" binding:"required"`
+    Reason string             `json:"reason" binding:"required"`
+}
+
+type OIDCCallbackRequest struct {
+    Code  string `form:"code" binding:"required"`
+    State string `form:"state" binding:"required"`
+}
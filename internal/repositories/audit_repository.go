@@ -0,0 +1,62 @@
+package repositories
+
+import (
+    "context"
+    "insurance-claims-api/internal/models"
+    "time"
+
+    "go.mongodb.org/mongo-driver/bson"
+    "go.mongodb.org/mongo-driver/bson/primitive"
+    "go.mongodb.org/mongo-driver/mongo"
+    "go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type AuditRepository interface {
+    Create(tenantID string, entry *models.AuditLog) error
+    FindAll(tenantID string, filter bson.M, page, limit int) ([]models.AuditLog, int64, error)
+}
+
+type auditRepository struct {
+    dbs     TenantDatabases
+    indexes *indexSet
+}
+
+func NewAuditRepository(dbs TenantDatabases) AuditRepository {
+    return &auditRepository{dbs, newIndexSet(
+        mongo.IndexModel{Keys: bson.D{{Key: "tenant_id", Value: 1}, {Key: "at", Value: -1}}},
+        mongo.IndexModel{Keys: bson.D{{Key: "tenant_id", Value: 1}, {Key: "actor_id", Value: 1}, {Key: "at", Value: -1}}},
+        mongo.IndexModel{Keys: bson.D{{Key: "tenant_id", Value: 1}, {Key: "subject_id", Value: 1}, {Key: "at", Value: -1}}},
+    )}
+}
+
+func (r *auditRepository) collection(tenantID string) *mongo.Collection {
+    return r.indexes.ensure(r.dbs.Database(tenantID).Collection("audit_logs"))
+}
+
+func (r *auditRepository) Create(tenantID string, entry *models.AuditLog) error {
+    entry.ID = primitive.NewObjectID()
+    entry.TenantID = tenantID
+    if entry.At.IsZero() {
+        entry.At = time.Now()
+    }
+    _, err := r.collection(tenantID).InsertOne(context.TODO(), entry)
+    return err
+}
+
+func (r *auditRepository) FindAll(tenantID string, filter bson.M, page, limit int) ([]models.AuditLog, int64, error) {
+    filter = scoped(tenantID, filter)
+    skip := (page - 1) * limit
+    opts := options.Find().SetSkip(int64(skip)).SetLimit(int64(limit)).SetSort(bson.M{"at": -1})
+    cursor, err := r.collection(tenantID).Find(context.TODO(), filter, opts)
+    if err != nil {
+        return nil, 0, err
+    }
+    defer cursor.Close(context.TODO())
+
+    var entries []models.AuditLog
+    if err = cursor.All(context.TODO(), &entries); err != nil {
+        return nil, 0, err
+    }
+    total, _ := r.collection(tenantID).CountDocuments(context.TODO(), filter)
+    return entries, total, nil
+}
@@ -12,66 +12,118 @@ import (
 )
 
 type ClaimRepository interface {
-    Create(claim *models.Claim) error
-    FindByID(id primitive.ObjectID) (*models.Claim, error)
-    FindByUserID(userID primitive.ObjectID, page, limit int) ([]models.Claim, int64, error)
-    FindAll(filter bson.M, page, limit int) ([]models.Claim, int64, error)
-    Update(claim *models.Claim) error
-    Delete(id primitive.ObjectID) error
-    AddHistory(id primitive.ObjectID, history models.ClaimHistory) error
-    UpdateWithPush(id primitive.ObjectID, update bson.M) error
+    Create(tenantID string, claim *models.Claim) error
+    Import(tenantID string, claim *models.Claim) error
+    FindByID(tenantID string, id primitive.ObjectID) (*models.Claim, error)
+    FindByReference(tenantID, reference string) (*models.Claim, error)
+    FindByExternalRef(tenantID, externalRef string) (*models.Claim, error)
+    FindByUserID(tenantID string, userID primitive.ObjectID, page, limit int) ([]models.Claim, int64, error)
+    FindAll(tenantID string, filter bson.M, page, limit int) ([]models.Claim, int64, error)
+    FindSorted(tenantID string, filter bson.M, sort bson.D, page, limit int) ([]models.Claim, int64, error)
+    Update(tenantID string, claim *models.Claim) error
+    Delete(tenantID string, id primitive.ObjectID) error
+    AddHistory(tenantID string, id primitive.ObjectID, history models.ClaimHistory) error
+    UpdateWithPush(tenantID string, id primitive.ObjectID, update bson.M) error
+    UpdateIf(tenantID string, id primitive.ObjectID, cond bson.M, update bson.M) (bool, error)
+    UpdateMany(ctx context.Context, tenantID string, filter bson.M, update bson.M) (int64, error)
+    UpdateSystem(ctx context.Context, tenantID string, id primitive.ObjectID, cond bson.M, update bson.M) (bool, error)
+    UpdateSystemMany(ctx context.Context, tenantID string, filter bson.M, update bson.M) (int64, error)
+    Count(tenantID string, filter bson.M) (int64, error)
+    ForEach(ctx context.Context, tenantID string, filter bson.M, fn func(claim *models.Claim) error) error
+    ForEachSorted(tenantID string, filter bson.M, sort bson.D, fn func(claim *models.Claim) error) error
+    Aggregate(tenantID string, pipeline []bson.M, out interface{}) error
 }
 
 type claimRepository struct {
-    collection *mongo.Collection
+    dbs     TenantDatabases
+    indexes *indexSet
 }
 
-func NewClaimRepository(client *mongo.Client) ClaimRepository {
-    collection := client.Database("insurance").Collection("claims")
-    return &claimRepository{collection}
+func NewClaimRepository(dbs TenantDatabases) ClaimRepository {
+    return &claimRepository{dbs, newIndexSet(
+        mongo.IndexModel{Keys: bson.D{{Key: "tenant_id", Value: 1}, {Key: "user_id", Value: 1}, {Key: "created_at", Value: -1}}},
+        mongo.IndexModel{Keys: bson.D{{Key: "tenant_id", Value: 1}, {Key: "status", Value: 1}, {Key: "created_at", Value: -1}}},
+        mongo.IndexModel{Keys: bson.D{{Key: "tenant_id", Value: 1}, {Key: "policy_number", Value: 1}, {Key: "created_at", Value: -1}}},
+        mongo.IndexModel{
+            Keys:    bson.D{{Key: "tenant_id", Value: 1}, {Key: "reference", Value: 1}},
+            Options: options.Index().SetUnique(true).SetPartialFilterExpression(bson.M{"reference": bson.M{"$exists": true}}),
+        },
+        // Partial rather than sparse: tenant_id is always present, so a
+        // sparse compound index would still index claims without the field.
+        mongo.IndexModel{
+            Keys:    bson.D{{Key: "tenant_id", Value: 1}, {Key: "external_reference", Value: 1}},
+            Options: options.Index().SetUnique(true).SetPartialFilterExpression(bson.M{"external_reference": bson.M{"$exists": true}}),
+        },
+    )}
 }
 
-func (r *claimRepository) Create(claim *models.Claim) error {
+func (r *claimRepository) collection(tenantID string) *mongo.Collection {
+    return r.indexes.ensure(r.dbs.Database(tenantID).Collection("claims"))
+}
+
+func (r *claimRepository) Create(tenantID string, claim *models.Claim) error {
+    claim.TenantID = tenantID
     claim.CreatedAt = time.Now()
     claim.UpdatedAt = time.Now()
+    claim.Version = 1
     claim.Status = models.Draft
     claim.History = []models.ClaimHistory{
         {Status: models.Draft, ChangedBy: claim.UserID, ChangedAt: time.Now()},
     }
-    _, err := r.collection.InsertOne(context.TODO(), claim)
+    _, err := r.collection(tenantID).InsertOne(context.TODO(), claim)
     return err
 }
 
-func (r *claimRepository) FindByID(id primitive.ObjectID) (*models.Claim, error) {
+// Import inserts a claim migrated from another system as-is, keeping its
+// status, history and creation time.
+func (r *claimRepository) Import(tenantID string, claim *models.Claim) error {
+    claim.TenantID = tenantID
+    claim.UpdatedAt = time.Now()
+    claim.Version = 1
+    _, err := r.collection(tenantID).InsertOne(context.TODO(), claim)
+    return err
+}
+
+func (r *claimRepository) FindByID(tenantID string, id primitive.ObjectID) (*models.Claim, error) {
     var claim models.Claim
-    err := r.collection.FindOne(context.TODO(), bson.M{"_id": id}).Decode(&claim)
+    err := r.collection(tenantID).FindOne(context.TODO(), scoped(tenantID, bson.M{"_id": id})).Decode(&claim)
     if err != nil {
         return nil, err
     }
     return &claim, nil
 }
 
-func (r *claimRepository) FindByUserID(userID primitive.ObjectID, page, limit int) ([]models.Claim, int64, error) {
-    skip := (page - 1) * limit
-    opts := options.Find().SetSkip(int64(skip)).SetLimit(int64(limit)).SetSort(bson.M{"created_at": -1})
-    cursor, err := r.collection.Find(context.TODO(), bson.M{"user_id": userID}, opts)
+func (r *claimRepository) FindByReference(tenantID, reference string) (*models.Claim, error) {
+    var claim models.Claim
+    err := r.collection(tenantID).FindOne(context.TODO(), scoped(tenantID, bson.M{"reference": reference})).Decode(&claim)
     if err != nil {
-        return nil, 0, err
+        return nil, err
     }
-    defer cursor.Close(context.TODO())
+    return &claim, nil
+}
 
-    var claims []models.Claim
-    if err = cursor.All(context.TODO(), &claims); err != nil {
-        return nil, 0, err
+func (r *claimRepository) FindByExternalRef(tenantID, externalRef string) (*models.Claim, error) {
+    var claim models.Claim
+    err := r.collection(tenantID).FindOne(context.TODO(), scoped(tenantID, bson.M{"external_reference": externalRef})).Decode(&claim)
+    if err != nil {
+        return nil, err
     }
-    total, _ := r.collection.CountDocuments(context.TODO(), bson.M{"user_id": userID})
-    return claims, total, nil
+    return &claim, nil
+}
+
+func (r *claimRepository) FindByUserID(tenantID string, userID primitive.ObjectID, page, limit int) ([]models.Claim, int64, error) {
+    return r.FindAll(tenantID, bson.M{"user_id": userID}, page, limit)
 }
 
-func (r *claimRepository) FindAll(filter bson.M, page, limit int) ([]models.Claim, int64, error) {
+func (r *claimRepository) FindAll(tenantID string, filter bson.M, page, limit int) ([]models.Claim, int64, error) {
+    return r.FindSorted(tenantID, filter, bson.D{{Key: "created_at", Value: -1}}, page, limit)
+}
+
+func (r *claimRepository) FindSorted(tenantID string, filter bson.M, sort bson.D, page, limit int) ([]models.Claim, int64, error) {
+    filter = scoped(tenantID, filter)
     skip := (page - 1) * limit
-    opts := options.Find().SetSkip(int64(skip)).SetLimit(int64(limit)).SetSort(bson.M{"created_at": -1})
-    cursor, err := r.collection.Find(context.TODO(), filter, opts)
+    opts := options.Find().SetSkip(int64(skip)).SetLimit(int64(limit)).SetSort(sort)
+    cursor, err := r.collection(tenantID).Find(context.TODO(), filter, opts)
     if err != nil {
         return nil, 0, err
     }
@@ -81,38 +133,151 @@ func (r *claimRepository) FindAll(filter bson.M, page, limit int) ([]models.Clai
     if err = cursor.All(context.TODO(), &claims); err != nil {
         return nil, 0, err
     }
-    total, _ := r.collection.CountDocuments(context.TODO(), filter)
+    total, _ := r.collection(tenantID).CountDocuments(context.TODO(), filter)
     return claims, total, nil
 }
 
-func (r *claimRepository) Update(claim *models.Claim) error {
+func (r *claimRepository) Update(tenantID string, claim *models.Claim) error {
+    claim.TenantID = tenantID
     claim.UpdatedAt = time.Now()
-    _, err := r.collection.UpdateOne(context.TODO(), bson.M{"_id": claim.ID},
+    claim.Version++
+    _, err := r.collection(tenantID).UpdateOne(context.TODO(), scoped(tenantID, bson.M{"_id": claim.ID}),
         bson.M{"$set": claim})
     return err
 }
 
-func (r *claimRepository) Delete(id primitive.ObjectID) error {
-    _, err := r.collection.DeleteOne(context.TODO(), bson.M{"_id": id})
+func (r *claimRepository) Delete(tenantID string, id primitive.ObjectID) error {
+    _, err := r.collection(tenantID).DeleteOne(context.TODO(), scoped(tenantID, bson.M{"_id": id}))
     return err
 }
 
-func (r *claimRepository) AddHistory(id primitive.ObjectID, history models.ClaimHistory) error {
-    _, err := r.collection.UpdateOne(context.TODO(),
-        bson.M{"_id": id},
-        bson.M{
+func (r *claimRepository) AddHistory(tenantID string, id primitive.ObjectID, history models.ClaimHistory) error {
+    _, err := r.collection(tenantID).UpdateOne(context.TODO(),
+        scoped(tenantID, bson.M{"_id": id}),
+        withVersionBump(bson.M{
             "$push": bson.M{"history": history},
             "$set":  bson.M{"updated_at": time.Now()},
-        },
+        }),
     )
     return err
 }
 
-func (r *claimRepository) UpdateWithPush(id primitive.ObjectID, update bson.M) error {
-    _, err := r.collection.UpdateOne(
+func (r *claimRepository) UpdateWithPush(tenantID string, id primitive.ObjectID, update bson.M) error {
+    _, err := r.collection(tenantID).UpdateOne(
         context.TODO(),
-        bson.M{"_id": id},
-        update,
+        scoped(tenantID, bson.M{"_id": id}),
+        withVersionBump(update),
     )
     return err
-}
\ No newline at end of file
+}
+
+// UpdateIf applies update only while the claim still matches cond, and
+// reports whether it did.
+func (r *claimRepository) UpdateIf(tenantID string, id primitive.ObjectID, cond bson.M, update bson.M) (bool, error) {
+    filter := scoped(tenantID, cond)
+    filter["_id"] = id
+    res, err := r.collection(tenantID).UpdateOne(context.TODO(), filter, withVersionBump(update))
+    if err != nil {
+        return false, err
+    }
+    return res.MatchedCount > 0, nil
+}
+
+// UpdateMany applies update to every matching claim and bumps each one's
+// version, for jobs that change what clients see (such as status).
+func (r *claimRepository) UpdateMany(ctx context.Context, tenantID string, filter bson.M, update bson.M) (int64, error) {
+    res, err := r.collection(tenantID).UpdateMany(ctx, scoped(tenantID, filter), withVersionBump(update))
+    if err != nil {
+        return 0, err
+    }
+    return res.ModifiedCount, nil
+}
+
+// withVersionBump adds a version increment to update, so that every change
+// to a claim invalidates the ETags handed out for it.
+func withVersionBump(update bson.M) bson.M {
+    inc := bson.M{"version": 1}
+    if existing, ok := update["$inc"].(bson.M); ok {
+        for k, v := range existing {
+            inc[k] = v
+        }
+    }
+    out := bson.M{"$inc": inc}
+    for k, v := range update {
+        if k != "$inc" {
+            out[k] = v
+        }
+    }
+    return out
+}
+
+// UpdateSystem is UpdateIf for system bookkeeping (assignment locks, SLA
+// state): it leaves version alone, so ETags held by clients stay valid.
+func (r *claimRepository) UpdateSystem(ctx context.Context, tenantID string, id primitive.ObjectID, cond bson.M, update bson.M) (bool, error) {
+    filter := scoped(tenantID, cond)
+    filter["_id"] = id
+    res, err := r.collection(tenantID).UpdateOne(ctx, filter, update)
+    if err != nil {
+        return false, err
+    }
+    return res.MatchedCount > 0, nil
+}
+
+// UpdateSystemMany applies a maintenance job's update to every matching
+// claim without bumping version.
+func (r *claimRepository) UpdateSystemMany(ctx context.Context, tenantID string, filter bson.M, update bson.M) (int64, error) {
+    res, err := r.collection(tenantID).UpdateMany(ctx, scoped(tenantID, filter), update)
+    if err != nil {
+        return 0, err
+    }
+    return res.ModifiedCount, nil
+}
+
+func (r *claimRepository) Count(tenantID string, filter bson.M) (int64, error) {
+    return r.collection(tenantID).CountDocuments(context.TODO(), scoped(tenantID, filter))
+}
+
+// ForEach streams every matching claim to fn without loading them all into
+// memory, stopping at the first error.
+func (r *claimRepository) ForEach(ctx context.Context, tenantID string, filter bson.M, fn func(claim *models.Claim) error) error {
+    return r.forEach(ctx, tenantID, filter, nil, fn)
+}
+
+func (r *claimRepository) ForEachSorted(tenantID string, filter bson.M, sort bson.D, fn func(claim *models.Claim) error) error {
+    return r.forEach(context.TODO(), tenantID, filter, sort, fn)
+}
+
+func (r *claimRepository) forEach(ctx context.Context, tenantID string, filter bson.M, sort bson.D, fn func(claim *models.Claim) error) error {
+    opts := options.Find()
+    if sort != nil {
+        opts.SetSort(sort)
+    }
+    cursor, err := r.collection(tenantID).Find(ctx, scoped(tenantID, filter), opts)
+    if err != nil {
+        return err
+    }
+    defer cursor.Close(context.TODO())
+
+    for cursor.Next(ctx) {
+        var claim models.Claim
+        if err := cursor.Decode(&claim); err != nil {
+            return err
+        }
+        if err := fn(&claim); err != nil {
+            return err
+        }
+    }
+    return cursor.Err()
+}
+
+// Aggregate runs pipeline over the tenant's claims and decodes every result
+// into out, which must be a pointer to a slice.
+func (r *claimRepository) Aggregate(tenantID string, pipeline []bson.M, out interface{}) error {
+    pipeline = append([]bson.M{{"$match": scoped(tenantID, bson.M{})}}, pipeline...)
+    cursor, err := r.collection(tenantID).Aggregate(context.TODO(), pipeline)
+    if err != nil {
+        return err
+    }
+    defer cursor.Close(context.TODO())
+    return cursor.All(context.TODO(), out)
+}
@@ -0,0 +1,87 @@
+package repositories
+
+import (
+    "context"
+    "insurance-claims-api/internal/models"
+    "time"
+
+    "go.mongodb.org/mongo-driver/bson"
+    "go.mongodb.org/mongo-driver/bson/primitive"
+    "go.mongodb.org/mongo-driver/mongo"
+    "go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type CommentRepository interface {
+    Create(tenantID string, comment *models.ClaimComment) error
+    FindByID(tenantID string, id primitive.ObjectID) (*models.ClaimComment, error)
+    FindByClaim(tenantID string, claimID primitive.ObjectID, includeInternal bool) ([]models.ClaimComment, error)
+    Edit(tenantID string, comment *models.ClaimComment, edit models.CommentEdit) error
+}
+
+type commentRepository struct {
+    dbs TenantDatabases
+}
+
+func NewCommentRepository(dbs TenantDatabases) CommentRepository {
+    return &commentRepository{dbs}
+}
+
+func (r *commentRepository) collection(tenantID string) *mongo.Collection {
+    return r.dbs.Database(tenantID).Collection("claim_comments")
+}
+
+func (r *commentRepository) Create(tenantID string, comment *models.ClaimComment) error {
+    comment.ID = primitive.NewObjectID()
+    comment.TenantID = tenantID
+    comment.CreatedAt = time.Now()
+    comment.UpdatedAt = comment.CreatedAt
+    _, err := r.collection(tenantID).InsertOne(context.TODO(), comment)
+    return err
+}
+
+func (r *commentRepository) FindByID(tenantID string, id primitive.ObjectID) (*models.ClaimComment, error) {
+    var comment models.ClaimComment
+    err := r.collection(tenantID).FindOne(context.TODO(), scoped(tenantID, bson.M{"_id": id})).Decode(&comment)
+    if err != nil {
+        return nil, err
+    }
+    return &comment, nil
+}
+
+func (r *commentRepository) FindByClaim(tenantID string, claimID primitive.ObjectID, includeInternal bool) ([]models.ClaimComment, error) {
+    filter := bson.M{"claim_id": claimID}
+    if !includeInternal {
+        filter["internal"] = false
+    }
+    opts := options.Find().SetSort(bson.M{"created_at": 1})
+    cursor, err := r.collection(tenantID).Find(context.TODO(), scoped(tenantID, filter), opts)
+    if err != nil {
+        return nil, err
+    }
+    defer cursor.Close(context.TODO())
+
+    comments := []models.ClaimComment{}
+    if err = cursor.All(context.TODO(), &comments); err != nil {
+        return nil, err
+    }
+    return comments, nil
+}
+
+// Edit saves the comment's new body and attachments and appends the previous
+// body to its edit history.
+func (r *commentRepository) Edit(tenantID string, comment *models.ClaimComment, edit models.CommentEdit) error {
+    comment.UpdatedAt = time.Now()
+    _, err := r.collection(tenantID).UpdateOne(context.TODO(),
+        scoped(tenantID, bson.M{"_id": comment.ID}),
+        bson.M{
+            "$set": bson.M{
+                "body":        comment.Body,
+                "attachments": comment.Attachments,
+                "mentions":    comment.Mentions,
+                "updated_at":  comment.UpdatedAt,
+            },
+            "$push": bson.M{"edits": edit},
+        },
+    )
+    return err
+}
@@ -0,0 +1,44 @@
+package repositories
+
+import (
+    "context"
+
+    "go.mongodb.org/mongo-driver/bson"
+    "go.mongodb.org/mongo-driver/mongo"
+    "go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// CounterRepository hands out increasing sequence numbers per tenant. They
+// are unique but not gap-free: a number taken by a write that then fails is
+// never handed out again.
+type CounterRepository interface {
+    Next(tenantID, name string) (int64, error)
+}
+
+type counterRepository struct {
+    dbs TenantDatabases
+}
+
+func NewCounterRepository(dbs TenantDatabases) CounterRepository {
+    return &counterRepository{dbs}
+}
+
+func (r *counterRepository) collection(tenantID string) *mongo.Collection {
+    return r.dbs.Database(tenantID).Collection("counters")
+}
+
+// Next atomically increments the named counter, creating it at 1. Keying the
+// document by _id lets MongoDB retry racing first upserts instead of creating
+// two counters.
+func (r *counterRepository) Next(tenantID, name string) (int64, error) {
+    var counter struct {
+        Seq int64 `bson:"seq"`
+    }
+    opts := options.FindOneAndUpdate().SetUpsert(true).SetReturnDocument(options.After)
+    err := r.collection(tenantID).FindOneAndUpdate(context.TODO(),
+        bson.M{"_id": tenantID + "/" + name},
+        bson.M{"$inc": bson.M{"seq": 1}, "$setOnInsert": bson.M{"tenant_id": tenantID, "name": name}},
+        opts,
+    ).Decode(&counter)
+    return counter.Seq, err
+}
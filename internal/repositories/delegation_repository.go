@@ -0,0 +1,100 @@
+package repositories
+
+import (
+    "context"
+    "insurance-claims-api/internal/models"
+    "time"
+
+    "go.mongodb.org/mongo-driver/bson"
+    "go.mongodb.org/mongo-driver/bson/primitive"
+    "go.mongodb.org/mongo-driver/mongo"
+    "go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type DelegationRepository interface {
+    Create(tenantID string, delegation *models.Delegation) error
+    FindByID(tenantID string, id primitive.ObjectID) (*models.Delegation, error)
+    FindByUser(tenantID string, userID primitive.ObjectID) ([]models.Delegation, error)
+    FindActiveForDelegate(tenantID string, delegateID primitive.ObjectID, at time.Time) ([]models.Delegation, error)
+    FindOverlapping(tenantID string, delegatorID primitive.ObjectID, from, to time.Time) ([]models.Delegation, error)
+    Revoke(tenantID string, id primitive.ObjectID, at time.Time) error
+}
+
+type delegationRepository struct {
+    dbs TenantDatabases
+}
+
+func NewDelegationRepository(dbs TenantDatabases) DelegationRepository {
+    return &delegationRepository{dbs}
+}
+
+func (r *delegationRepository) collection(tenantID string) *mongo.Collection {
+    return r.dbs.Database(tenantID).Collection("delegations")
+}
+
+func (r *delegationRepository) Create(tenantID string, delegation *models.Delegation) error {
+    delegation.ID = primitive.NewObjectID()
+    delegation.TenantID = tenantID
+    delegation.CreatedAt = time.Now()
+    _, err := r.collection(tenantID).InsertOne(context.TODO(), delegation)
+    return err
+}
+
+func (r *delegationRepository) FindByID(tenantID string, id primitive.ObjectID) (*models.Delegation, error) {
+    var delegation models.Delegation
+    err := r.collection(tenantID).FindOne(context.TODO(), scoped(tenantID, bson.M{"_id": id})).Decode(&delegation)
+    if err != nil {
+        return nil, err
+    }
+    return &delegation, nil
+}
+
+// FindByUser returns delegations the user has given or received.
+func (r *delegationRepository) FindByUser(tenantID string, userID primitive.ObjectID) ([]models.Delegation, error) {
+    filter := scoped(tenantID, bson.M{"$or": []bson.M{{"delegator_id": userID}, {"delegate_id": userID}}})
+    return r.find(tenantID, filter)
+}
+
+func (r *delegationRepository) FindActiveForDelegate(tenantID string, delegateID primitive.ObjectID, at time.Time) ([]models.Delegation, error) {
+    filter := scoped(tenantID, bson.M{
+        "delegate_id": delegateID,
+        "starts_at":   bson.M{"$lte": at},
+        "ends_at":     bson.M{"$gt": at},
+        "revoked_at":  bson.M{"$exists": false},
+    })
+    return r.find(tenantID, filter)
+}
+
+// FindOverlapping returns the delegator's unrevoked delegations whose period
+// intersects [from, to).
+func (r *delegationRepository) FindOverlapping(tenantID string, delegatorID primitive.ObjectID, from, to time.Time) ([]models.Delegation, error) {
+    filter := scoped(tenantID, bson.M{
+        "delegator_id": delegatorID,
+        "starts_at":    bson.M{"$lt": to},
+        "ends_at":      bson.M{"$gt": from},
+        "revoked_at":   bson.M{"$exists": false},
+    })
+    return r.find(tenantID, filter)
+}
+
+func (r *delegationRepository) Revoke(tenantID string, id primitive.ObjectID, at time.Time) error {
+    _, err := r.collection(tenantID).UpdateOne(context.TODO(),
+        scoped(tenantID, bson.M{"_id": id}),
+        bson.M{"$set": bson.M{"revoked_at": at}},
+    )
+    return err
+}
+
+func (r *delegationRepository) find(tenantID string, filter bson.M) ([]models.Delegation, error) {
+    cursor, err := r.collection(tenantID).Find(context.TODO(), filter, options.Find().SetSort(bson.M{"starts_at": -1}))
+    if err != nil {
+        return nil, err
+    }
+    defer cursor.Close(context.TODO())
+
+    var delegations []models.Delegation
+    if err = cursor.All(context.TODO(), &delegations); err != nil {
+        return nil, err
+    }
+    return delegations, nil
+}
@@ -0,0 +1,48 @@
+package repositories
+
+import (
+    "bytes"
+    "io"
+
+    "go.mongodb.org/mongo-driver/bson"
+    "go.mongodb.org/mongo-driver/bson/primitive"
+    "go.mongodb.org/mongo-driver/mongo/gridfs"
+    "go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// DocumentRepository stores generated claim documents in the GridFS bucket
+// "documents".
+type DocumentRepository interface {
+    Upload(tenantID string, id primitive.ObjectID, fileName string, data []byte) error
+    Download(tenantID string, id primitive.ObjectID, w io.Writer) error
+}
+
+type documentRepository struct {
+    dbs TenantDatabases
+}
+
+func NewDocumentRepository(dbs TenantDatabases) DocumentRepository {
+    return &documentRepository{dbs}
+}
+
+func (r *documentRepository) bucket(tenantID string) (*gridfs.Bucket, error) {
+    return gridfs.NewBucket(r.dbs.Database(tenantID), options.GridFSBucket().SetName("documents"))
+}
+
+func (r *documentRepository) Upload(tenantID string, id primitive.ObjectID, fileName string, data []byte) error {
+    bucket, err := r.bucket(tenantID)
+    if err != nil {
+        return err
+    }
+    return bucket.UploadFromStreamWithID(id, fileName, bytes.NewReader(data),
+        options.GridFSUpload().SetMetadata(bson.M{"tenant_id": tenantID}))
+}
+
+func (r *documentRepository) Download(tenantID string, id primitive.ObjectID, w io.Writer) error {
+    bucket, err := r.bucket(tenantID)
+    if err != nil {
+        return err
+    }
+    _, err = bucket.DownloadToStream(id, w)
+    return err
+}
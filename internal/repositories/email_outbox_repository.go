@@ -0,0 +1,62 @@
+package repositories
+
+import (
+    "context"
+    "insurance-claims-api/internal/models"
+    "time"
+
+    "go.mongodb.org/mongo-driver/bson"
+    "go.mongodb.org/mongo-driver/bson/primitive"
+    "go.mongodb.org/mongo-driver/mongo"
+    "go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type EmailOutboxRepository interface {
+    Create(tenantID string, msg *models.EmailMessage) error
+    FindDue(ctx context.Context, tenantID string, now time.Time, limit int) ([]models.EmailMessage, error)
+    Update(ctx context.Context, tenantID string, msg *models.EmailMessage) error
+}
+
+type emailOutboxRepository struct {
+    dbs TenantDatabases
+}
+
+func NewEmailOutboxRepository(dbs TenantDatabases) EmailOutboxRepository {
+    return &emailOutboxRepository{dbs}
+}
+
+func (r *emailOutboxRepository) collection(tenantID string) *mongo.Collection {
+    return r.dbs.Database(tenantID).Collection("email_outbox")
+}
+
+func (r *emailOutboxRepository) Create(tenantID string, msg *models.EmailMessage) error {
+    msg.TenantID = tenantID
+    msg.CreatedAt = time.Now()
+    res, err := r.collection(tenantID).InsertOne(context.TODO(), msg)
+    if err == nil {
+        msg.ID = res.InsertedID.(primitive.ObjectID)
+    }
+    return err
+}
+
+// FindDue returns pending messages whose next attempt is due, oldest first.
+func (r *emailOutboxRepository) FindDue(ctx context.Context, tenantID string, now time.Time, limit int) ([]models.EmailMessage, error) {
+    filter := scoped(tenantID, bson.M{"status": models.EmailPending, "next_attempt_at": bson.M{"$lte": now}})
+    opts := options.Find().SetSort(bson.M{"next_attempt_at": 1}).SetLimit(int64(limit))
+    cursor, err := r.collection(tenantID).Find(ctx, filter, opts)
+    if err != nil {
+        return nil, err
+    }
+    defer cursor.Close(context.TODO())
+
+    var msgs []models.EmailMessage
+    if err = cursor.All(ctx, &msgs); err != nil {
+        return nil, err
+    }
+    return msgs, nil
+}
+
+func (r *emailOutboxRepository) Update(ctx context.Context, tenantID string, msg *models.EmailMessage) error {
+    _, err := r.collection(tenantID).UpdateOne(ctx, scoped(tenantID, bson.M{"_id": msg.ID}), bson.M{"$set": msg})
+    return err
+}
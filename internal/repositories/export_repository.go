@@ -0,0 +1,79 @@
+package repositories
+
+import (
+    "context"
+    "insurance-claims-api/internal/models"
+    "io"
+    "time"
+
+    "go.mongodb.org/mongo-driver/bson"
+    "go.mongodb.org/mongo-driver/bson/primitive"
+    "go.mongodb.org/mongo-driver/mongo"
+    "go.mongodb.org/mongo-driver/mongo/gridfs"
+    "go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type ExportRepository interface {
+    Create(tenantID string, job *models.ExportJob) error
+    FindByID(tenantID string, id primitive.ObjectID) (*models.ExportJob, error)
+    Update(tenantID string, job *models.ExportJob) error
+    OpenUpload(tenantID, fileName string) (*gridfs.UploadStream, error)
+    Download(tenantID string, fileID primitive.ObjectID, w io.Writer) error
+}
+
+type exportRepository struct {
+    dbs TenantDatabases
+}
+
+func NewExportRepository(dbs TenantDatabases) ExportRepository {
+    return &exportRepository{dbs}
+}
+
+func (r *exportRepository) collection(tenantID string) *mongo.Collection {
+    return r.dbs.Database(tenantID).Collection("export_jobs")
+}
+
+func (r *exportRepository) bucket(tenantID string) (*gridfs.Bucket, error) {
+    return gridfs.NewBucket(r.dbs.Database(tenantID), options.GridFSBucket().SetName("exports"))
+}
+
+func (r *exportRepository) Create(tenantID string, job *models.ExportJob) error {
+    job.ID = primitive.NewObjectID()
+    job.TenantID = tenantID
+    job.CreatedAt = time.Now()
+    _, err := r.collection(tenantID).InsertOne(context.TODO(), job)
+    return err
+}
+
+func (r *exportRepository) FindByID(tenantID string, id primitive.ObjectID) (*models.ExportJob, error) {
+    var job models.ExportJob
+    err := r.collection(tenantID).FindOne(context.TODO(), scoped(tenantID, bson.M{"_id": id})).Decode(&job)
+    if err != nil {
+        return nil, err
+    }
+    return &job, nil
+}
+
+func (r *exportRepository) Update(tenantID string, job *models.ExportJob) error {
+    _, err := r.collection(tenantID).UpdateOne(context.TODO(), scoped(tenantID, bson.M{"_id": job.ID}), bson.M{"$set": job})
+    return err
+}
+
+// OpenUpload starts a GridFS file. The caller writes the export into it and
+// must Close it to commit the file, or Abort on failure.
+func (r *exportRepository) OpenUpload(tenantID, fileName string) (*gridfs.UploadStream, error) {
+    bucket, err := r.bucket(tenantID)
+    if err != nil {
+        return nil, err
+    }
+    return bucket.OpenUploadStream(fileName, options.GridFSUpload().SetMetadata(bson.M{"tenant_id": tenantID}))
+}
+
+func (r *exportRepository) Download(tenantID string, fileID primitive.ObjectID, w io.Writer) error {
+    bucket, err := r.bucket(tenantID)
+    if err != nil {
+        return err
+    }
+    _, err = bucket.DownloadToStream(fileID, w)
+    return err
+}
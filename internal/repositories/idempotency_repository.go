@@ -0,0 +1,79 @@
+package repositories
+
+import (
+    "context"
+    "insurance-claims-api/internal/models"
+    "sync"
+
+    "go.mongodb.org/mongo-driver/bson"
+    "go.mongodb.org/mongo-driver/mongo"
+    "go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type IdempotencyRepository interface {
+    // Insert returns false if a record with the same ID already exists.
+    Insert(tenantID string, rec *models.IdempotencyRecord) (bool, error)
+    Find(tenantID, id string) (*models.IdempotencyRecord, error)
+    Complete(tenantID string, rec *models.IdempotencyRecord) error
+    Delete(tenantID, id string) error
+}
+
+type idempotencyRepository struct {
+    dbs     TenantDatabases
+    indexed sync.Map
+}
+
+func NewIdempotencyRepository(dbs TenantDatabases) IdempotencyRepository {
+    return &idempotencyRepository{dbs: dbs}
+}
+
+// collection also creates the TTL index that lets MongoDB drop expired keys,
+// once per database.
+func (r *idempotencyRepository) collection(tenantID string) *mongo.Collection {
+    db := r.dbs.Database(tenantID)
+    coll := db.Collection("idempotency_keys")
+    if _, done := r.indexed.LoadOrStore(db.Name(), true); !done {
+        _, err := coll.Indexes().CreateOne(context.TODO(), mongo.IndexModel{
+            Keys:    bson.M{"expires_at": 1},
+            Options: options.Index().SetExpireAfterSeconds(0),
+        })
+        if err != nil {
+            r.indexed.Delete(db.Name())
+        }
+    }
+    return coll
+}
+
+func (r *idempotencyRepository) Insert(tenantID string, rec *models.IdempotencyRecord) (bool, error) {
+    rec.TenantID = tenantID
+    _, err := r.collection(tenantID).InsertOne(context.TODO(), rec)
+    if mongo.IsDuplicateKeyError(err) {
+        return false, nil
+    }
+    return err == nil, err
+}
+
+func (r *idempotencyRepository) Find(tenantID, id string) (*models.IdempotencyRecord, error) {
+    var rec models.IdempotencyRecord
+    err := r.collection(tenantID).FindOne(context.TODO(), scoped(tenantID, bson.M{"_id": id})).Decode(&rec)
+    if err != nil {
+        return nil, err
+    }
+    return &rec, nil
+}
+
+func (r *idempotencyRepository) Complete(tenantID string, rec *models.IdempotencyRecord) error {
+    _, err := r.collection(tenantID).UpdateOne(context.TODO(), scoped(tenantID, bson.M{"_id": rec.ID}), bson.M{"$set": bson.M{
+        "completed":    true,
+        "status_code":  rec.StatusCode,
+        "content_type": rec.ContentType,
+        "headers":      rec.Headers,
+        "body":         rec.Body,
+    }})
+    return err
+}
+
+func (r *idempotencyRepository) Delete(tenantID, id string) error {
+    _, err := r.collection(tenantID).DeleteOne(context.TODO(), scoped(tenantID, bson.M{"_id": id}))
+    return err
+}
@@ -0,0 +1,92 @@
+package repositories
+
+import (
+    "context"
+    "insurance-claims-api/internal/models"
+    "time"
+
+    "go.mongodb.org/mongo-driver/bson"
+    "go.mongodb.org/mongo-driver/bson/primitive"
+    "go.mongodb.org/mongo-driver/mongo"
+    "go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// JobRepository stores job leases and run history. Jobs span all tenants, so
+// both live in the control database.
+type JobRepository interface {
+    AcquireLease(job, owner string, slot time.Time, ttl time.Duration) (bool, error)
+    ReleaseLease(job, owner string) error
+    CreateRun(run *models.JobRun) error
+    FinishRun(run *models.JobRun) error
+    FindRuns(job string, limit int) ([]models.JobRun, error)
+}
+
+type jobRepository struct {
+    leases *mongo.Collection
+    runs   *mongo.Collection
+}
+
+func NewJobRepository(dbs TenantDatabases) JobRepository {
+    return &jobRepository{
+        leases: dbs.Control().Collection("job_leases"),
+        runs:   dbs.Control().Collection("job_runs"),
+    }
+}
+
+// AcquireLease takes the job's lease if nobody holds it and, for scheduled
+// runs, if slot has not already been run by another replica. A zero slot is
+// used for manual runs. Losing the race surfaces as a duplicate key error from
+// the upsert and is reported as false.
+func (r *jobRepository) AcquireLease(job, owner string, slot time.Time, ttl time.Duration) (bool, error) {
+    now := time.Now()
+    filter := bson.M{
+        "_id":        job,
+        "expires_at": bson.M{"$lte": now},
+    }
+    update := bson.M{"$set": bson.M{"owner": owner, "expires_at": now.Add(ttl)}}
+    if slot.IsZero() {
+        update["$setOnInsert"] = bson.M{"last_slot": time.Time{}}
+    } else {
+        filter["last_slot"] = bson.M{"$lt": slot}
+        update["$set"].(bson.M)["last_slot"] = slot
+    }
+    _, err := r.leases.UpdateOne(context.TODO(), filter, update, options.Update().SetUpsert(true))
+    if mongo.IsDuplicateKeyError(err) {
+        return false, nil
+    }
+    return err == nil, err
+}
+
+func (r *jobRepository) ReleaseLease(job, owner string) error {
+    _, err := r.leases.UpdateOne(context.TODO(),
+        bson.M{"_id": job, "owner": owner},
+        bson.M{"$set": bson.M{"expires_at": time.Now()}},
+    )
+    return err
+}
+
+func (r *jobRepository) CreateRun(run *models.JobRun) error {
+    run.ID = primitive.NewObjectID()
+    _, err := r.runs.InsertOne(context.TODO(), run)
+    return err
+}
+
+func (r *jobRepository) FinishRun(run *models.JobRun) error {
+    _, err := r.runs.UpdateOne(context.TODO(), bson.M{"_id": run.ID}, bson.M{"$set": run})
+    return err
+}
+
+func (r *jobRepository) FindRuns(job string, limit int) ([]models.JobRun, error) {
+    opts := options.Find().SetSort(bson.M{"started_at": -1}).SetLimit(int64(limit))
+    cursor, err := r.runs.Find(context.TODO(), bson.M{"job": job}, opts)
+    if err != nil {
+        return nil, err
+    }
+    defer cursor.Close(context.TODO())
+
+    var runs []models.JobRun
+    if err = cursor.All(context.TODO(), &runs); err != nil {
+        return nil, err
+    }
+    return runs, nil
+}
@@ -0,0 +1,44 @@
+package repositories
+
+import (
+    "context"
+    "insurance-claims-api/internal/models"
+
+    "go.mongodb.org/mongo-driver/bson"
+    "go.mongodb.org/mongo-driver/bson/primitive"
+    "go.mongodb.org/mongo-driver/mongo"
+    "go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type NotificationPreferenceRepository interface {
+    Find(tenantID string, userID primitive.ObjectID) (*models.NotificationPreferences, error)
+    Save(tenantID string, prefs *models.NotificationPreferences) error
+}
+
+type notificationPreferenceRepository struct {
+    dbs TenantDatabases
+}
+
+func NewNotificationPreferenceRepository(dbs TenantDatabases) NotificationPreferenceRepository {
+    return &notificationPreferenceRepository{dbs}
+}
+
+func (r *notificationPreferenceRepository) collection(tenantID string) *mongo.Collection {
+    return r.dbs.Database(tenantID).Collection("notification_preferences")
+}
+
+func (r *notificationPreferenceRepository) Find(tenantID string, userID primitive.ObjectID) (*models.NotificationPreferences, error) {
+    var prefs models.NotificationPreferences
+    err := r.collection(tenantID).FindOne(context.TODO(), scoped(tenantID, bson.M{"user_id": userID})).Decode(&prefs)
+    if err != nil {
+        return nil, err
+    }
+    return &prefs, nil
+}
+
+func (r *notificationPreferenceRepository) Save(tenantID string, prefs *models.NotificationPreferences) error {
+    prefs.TenantID = tenantID
+    _, err := r.collection(tenantID).ReplaceOne(context.TODO(),
+        scoped(tenantID, bson.M{"user_id": prefs.UserID}), prefs, options.Replace().SetUpsert(true))
+    return err
+}
@@ -0,0 +1,77 @@
+package repositories
+
+import (
+    "context"
+    "insurance-claims-api/internal/models"
+    "time"
+
+    "go.mongodb.org/mongo-driver/bson"
+    "go.mongodb.org/mongo-driver/bson/primitive"
+    "go.mongodb.org/mongo-driver/mongo"
+    "go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type NotificationRepository interface {
+    Create(tenantID string, n *models.Notification) error
+    FindByUser(tenantID string, userID primitive.ObjectID, unreadOnly bool, page, limit int) ([]models.Notification, int64, error)
+    MarkRead(tenantID string, userID, id primitive.ObjectID) error
+    CountUnread(tenantID string, userID primitive.ObjectID) (int64, error)
+}
+
+type notificationRepository struct {
+    dbs TenantDatabases
+}
+
+func NewNotificationRepository(dbs TenantDatabases) NotificationRepository {
+    return &notificationRepository{dbs}
+}
+
+func (r *notificationRepository) collection(tenantID string) *mongo.Collection {
+    return r.dbs.Database(tenantID).Collection("notifications")
+}
+
+func (r *notificationRepository) Create(tenantID string, n *models.Notification) error {
+    n.ID = primitive.NewObjectID()
+    n.TenantID = tenantID
+    n.CreatedAt = time.Now()
+    _, err := r.collection(tenantID).InsertOne(context.TODO(), n)
+    return err
+}
+
+func (r *notificationRepository) FindByUser(tenantID string, userID primitive.ObjectID, unreadOnly bool, page, limit int) ([]models.Notification, int64, error) {
+    filter := bson.M{"user_id": userID}
+    if unreadOnly {
+        filter["read_at"] = bson.M{"$exists": false}
+    }
+    filter = scoped(tenantID, filter)
+    skip := (page - 1) * limit
+    opts := options.Find().SetSkip(int64(skip)).SetLimit(int64(limit)).SetSort(bson.M{"created_at": -1})
+    cursor, err := r.collection(tenantID).Find(context.TODO(), filter, opts)
+    if err != nil {
+        return nil, 0, err
+    }
+    defer cursor.Close(context.TODO())
+
+    var items []models.Notification
+    if err = cursor.All(context.TODO(), &items); err != nil {
+        return nil, 0, err
+    }
+    total, _ := r.collection(tenantID).CountDocuments(context.TODO(), filter)
+    return items, total, nil
+}
+
+func (r *notificationRepository) MarkRead(tenantID string, userID, id primitive.ObjectID) error {
+    res, err := r.collection(tenantID).UpdateOne(context.TODO(),
+        scoped(tenantID, bson.M{"_id": id, "user_id": userID}),
+        bson.M{"$set": bson.M{"read_at": time.Now()}},
+    )
+    if err == nil && res.MatchedCount == 0 {
+        return mongo.ErrNoDocuments
+    }
+    return err
+}
+
+func (r *notificationRepository) CountUnread(tenantID string, userID primitive.ObjectID) (int64, error) {
+    return r.collection(tenantID).CountDocuments(context.TODO(),
+        scoped(tenantID, bson.M{"user_id": userID, "read_at": bson.M{"$exists": false}}))
+}
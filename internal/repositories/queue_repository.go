@@ -0,0 +1,42 @@
+package repositories
+
+import (
+    "context"
+    "insurance-claims-api/internal/models"
+
+    "go.mongodb.org/mongo-driver/bson"
+    "go.mongodb.org/mongo-driver/mongo"
+    "go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// QueueRepository keeps the round-robin cursor per tenant and stage.
+type QueueRepository interface {
+    NextTurn(tenantID string, stage models.ClaimStatus) (int64, error)
+}
+
+type queueRepository struct {
+    dbs TenantDatabases
+}
+
+func NewQueueRepository(dbs TenantDatabases) QueueRepository {
+    return &queueRepository{dbs}
+}
+
+func (r *queueRepository) collection(tenantID string) *mongo.Collection {
+    return r.dbs.Database(tenantID).Collection("queue_cursors")
+}
+
+// NextTurn atomically advances the stage's cursor, creating it at 1, so
+// concurrent assignments each get their own turn.
+func (r *queueRepository) NextTurn(tenantID string, stage models.ClaimStatus) (int64, error) {
+    var cursor struct {
+        Turn int64 `bson:"turn"`
+    }
+    opts := options.FindOneAndUpdate().SetUpsert(true).SetReturnDocument(options.After)
+    err := r.collection(tenantID).FindOneAndUpdate(context.TODO(),
+        bson.M{"_id": tenantID + "/" + string(stage)},
+        bson.M{"$inc": bson.M{"turn": 1}, "$setOnInsert": bson.M{"tenant_id": tenantID, "stage": stage}},
+        opts,
+    ).Decode(&cursor)
+    return cursor.Turn, err
+}
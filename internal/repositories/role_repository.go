@@ -0,0 +1,63 @@
+package repositories
+
+import (
+    "context"
+    "insurance-claims-api/internal/models"
+    "time"
+
+    "go.mongodb.org/mongo-driver/bson"
+    "go.mongodb.org/mongo-driver/mongo"
+    "go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type RoleRepository interface {
+    FindByRole(tenantID, role string) (*models.RoleBinding, error)
+    FindAll(tenantID string) ([]models.RoleBinding, error)
+    Upsert(tenantID string, binding *models.RoleBinding) error
+}
+
+type roleRepository struct {
+    dbs TenantDatabases
+}
+
+func NewRoleRepository(dbs TenantDatabases) RoleRepository {
+    return &roleRepository{dbs}
+}
+
+func (r *roleRepository) collection(tenantID string) *mongo.Collection {
+    return r.dbs.Database(tenantID).Collection("role_bindings")
+}
+
+func (r *roleRepository) FindByRole(tenantID, role string) (*models.RoleBinding, error) {
+    var binding models.RoleBinding
+    err := r.collection(tenantID).FindOne(context.TODO(), scoped(tenantID, bson.M{"role": role})).Decode(&binding)
+    if err != nil {
+        return nil, err
+    }
+    return &binding, nil
+}
+
+func (r *roleRepository) FindAll(tenantID string) ([]models.RoleBinding, error) {
+    cursor, err := r.collection(tenantID).Find(context.TODO(), scoped(tenantID, bson.M{}), options.Find().SetSort(bson.M{"role": 1}))
+    if err != nil {
+        return nil, err
+    }
+    defer cursor.Close(context.TODO())
+
+    var bindings []models.RoleBinding
+    if err = cursor.All(context.TODO(), &bindings); err != nil {
+        return nil, err
+    }
+    return bindings, nil
+}
+
+func (r *roleRepository) Upsert(tenantID string, binding *models.RoleBinding) error {
+    binding.TenantID = tenantID
+    binding.UpdatedAt = time.Now()
+    _, err := r.collection(tenantID).UpdateOne(context.TODO(),
+        scoped(tenantID, bson.M{"role": binding.Role}),
+        bson.M{"$set": bson.M{"grants": binding.Grants, "updated_at": binding.UpdatedAt}},
+        options.Update().SetUpsert(true),
+    )
+    return err
+}
@@ -0,0 +1,113 @@
+package repositories
+
+import (
+    "context"
+    "insurance-claims-api/internal/models"
+    "sort"
+    "time"
+
+    "go.mongodb.org/mongo-driver/bson"
+    "go.mongodb.org/mongo-driver/bson/primitive"
+    "go.mongodb.org/mongo-driver/mongo"
+    "go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type RuleRepository interface {
+    FindCurrent(tenantID string) ([]models.ClaimRule, error)
+    FindCurrentByRuleID(tenantID, ruleID string) (*models.ClaimRule, error)
+    FindVersions(tenantID, ruleID string) ([]models.ClaimRule, error)
+    CreateVersion(tenantID string, rule *models.ClaimRule) error
+}
+
+type ruleRepository struct {
+    dbs     TenantDatabases
+    indexes *indexSet
+}
+
+func NewRuleRepository(dbs TenantDatabases) RuleRepository {
+    return &ruleRepository{dbs, newIndexSet(
+        mongo.IndexModel{Keys: bson.D{{Key: "tenant_id", Value: 1}, {Key: "current", Value: 1}, {Key: "priority", Value: 1}}},
+        mongo.IndexModel{
+            Keys:    bson.D{{Key: "tenant_id", Value: 1}, {Key: "rule_id", Value: 1}, {Key: "version", Value: 1}},
+            Options: options.Index().SetUnique(true),
+        },
+    )}
+}
+
+func (r *ruleRepository) collection(tenantID string) *mongo.Collection {
+    return r.indexes.ensure(r.dbs.Database(tenantID).Collection("claim_rules"))
+}
+
+// FindCurrent returns the current version of every rule in evaluation order.
+// While CreateVersion is between its insert and the retirement of the older
+// version both are marked current; only the newest one is returned.
+func (r *ruleRepository) FindCurrent(tenantID string) ([]models.ClaimRule, error) {
+    opts := options.Find().SetSort(bson.D{{Key: "version", Value: -1}})
+    cursor, err := r.collection(tenantID).Find(context.TODO(), scoped(tenantID, bson.M{"current": true}), opts)
+    if err != nil {
+        return nil, err
+    }
+    defer cursor.Close(context.TODO())
+
+    var rules []models.ClaimRule
+    if err = cursor.All(context.TODO(), &rules); err != nil {
+        return nil, err
+    }
+    seen := map[string]bool{}
+    current := rules[:0]
+    for _, rule := range rules {
+        if !seen[rule.RuleID] {
+            seen[rule.RuleID] = true
+            current = append(current, rule)
+        }
+    }
+    sort.SliceStable(current, func(i, j int) bool {
+        if current[i].Priority != current[j].Priority {
+            return current[i].Priority < current[j].Priority
+        }
+        return current[i].RuleID < current[j].RuleID
+    })
+    return current, nil
+}
+
+func (r *ruleRepository) FindCurrentByRuleID(tenantID, ruleID string) (*models.ClaimRule, error) {
+    var rule models.ClaimRule
+    opts := options.FindOne().SetSort(bson.M{"version": -1})
+    err := r.collection(tenantID).FindOne(context.TODO(), scoped(tenantID, bson.M{"rule_id": ruleID, "current": true}), opts).Decode(&rule)
+    if err != nil {
+        return nil, err
+    }
+    return &rule, nil
+}
+
+func (r *ruleRepository) FindVersions(tenantID, ruleID string) ([]models.ClaimRule, error) {
+    opts := options.Find().SetSort(bson.M{"version": -1})
+    cursor, err := r.collection(tenantID).Find(context.TODO(), scoped(tenantID, bson.M{"rule_id": ruleID}), opts)
+    if err != nil {
+        return nil, err
+    }
+    defer cursor.Close(context.TODO())
+
+    var rules []models.ClaimRule
+    if err = cursor.All(context.TODO(), &rules); err != nil {
+        return nil, err
+    }
+    return rules, nil
+}
+
+// CreateVersion stores rule as the new current version of its RuleID and
+// retires the older ones. The unique (rule_id, version) index lets only one
+// writer create a given version; the loser gets a duplicate key error.
+func (r *ruleRepository) CreateVersion(tenantID string, rule *models.ClaimRule) error {
+    rule.ID = primitive.NewObjectID()
+    rule.TenantID = tenantID
+    rule.Current = true
+    rule.CreatedAt = time.Now()
+    if _, err := r.collection(tenantID).InsertOne(context.TODO(), rule); err != nil {
+        return err
+    }
+    _, err := r.collection(tenantID).UpdateMany(context.TODO(),
+        scoped(tenantID, bson.M{"rule_id": rule.RuleID, "version": bson.M{"$lt": rule.Version}}),
+        bson.M{"$set": bson.M{"current": false}})
+    return err
+}
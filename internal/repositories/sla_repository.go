@@ -0,0 +1,94 @@
+package repositories
+
+import (
+    "context"
+    "insurance-claims-api/internal/models"
+    "time"
+
+    "go.mongodb.org/mongo-driver/bson"
+    "go.mongodb.org/mongo-driver/bson/primitive"
+    "go.mongodb.org/mongo-driver/mongo"
+    "go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type SLARepository interface {
+    FindPolicies(tenantID string) ([]models.SLAPolicy, error)
+    CreatePolicy(tenantID string, policy *models.SLAPolicy) error
+    UpdatePolicy(tenantID string, policy *models.SLAPolicy) error
+    DeletePolicy(tenantID string, id primitive.ObjectID) error
+    FindCalendar(tenantID string) (*models.BusinessCalendar, error)
+    UpsertCalendar(tenantID string, calendar *models.BusinessCalendar) error
+}
+
+type slaRepository struct {
+    dbs TenantDatabases
+}
+
+func NewSLARepository(dbs TenantDatabases) SLARepository {
+    return &slaRepository{dbs}
+}
+
+func (r *slaRepository) policies(tenantID string) *mongo.Collection {
+    return r.dbs.Database(tenantID).Collection("sla_policies")
+}
+
+func (r *slaRepository) calendars(tenantID string) *mongo.Collection {
+    return r.dbs.Database(tenantID).Collection("business_calendars")
+}
+
+func (r *slaRepository) FindPolicies(tenantID string) ([]models.SLAPolicy, error) {
+    cursor, err := r.policies(tenantID).Find(context.TODO(), scoped(tenantID, bson.M{}), options.Find().SetSort(bson.M{"name": 1}))
+    if err != nil {
+        return nil, err
+    }
+    defer cursor.Close(context.TODO())
+
+    var policies []models.SLAPolicy
+    if err = cursor.All(context.TODO(), &policies); err != nil {
+        return nil, err
+    }
+    return policies, nil
+}
+
+func (r *slaRepository) CreatePolicy(tenantID string, policy *models.SLAPolicy) error {
+    policy.ID = primitive.NewObjectID()
+    policy.TenantID = tenantID
+    policy.CreatedAt = time.Now()
+    policy.UpdatedAt = time.Now()
+    _, err := r.policies(tenantID).InsertOne(context.TODO(), policy)
+    return err
+}
+
+func (r *slaRepository) UpdatePolicy(tenantID string, policy *models.SLAPolicy) error {
+    policy.TenantID = tenantID
+    policy.UpdatedAt = time.Now()
+    res, err := r.policies(tenantID).UpdateOne(context.TODO(), scoped(tenantID, bson.M{"_id": policy.ID}), bson.M{"$set": policy})
+    if err == nil && res.MatchedCount == 0 {
+        return mongo.ErrNoDocuments
+    }
+    return err
+}
+
+func (r *slaRepository) DeletePolicy(tenantID string, id primitive.ObjectID) error {
+    res, err := r.policies(tenantID).DeleteOne(context.TODO(), scoped(tenantID, bson.M{"_id": id}))
+    if err == nil && res.DeletedCount == 0 {
+        return mongo.ErrNoDocuments
+    }
+    return err
+}
+
+func (r *slaRepository) FindCalendar(tenantID string) (*models.BusinessCalendar, error) {
+    var calendar models.BusinessCalendar
+    err := r.calendars(tenantID).FindOne(context.TODO(), scoped(tenantID, bson.M{})).Decode(&calendar)
+    if err != nil {
+        return nil, err
+    }
+    return &calendar, nil
+}
+
+func (r *slaRepository) UpsertCalendar(tenantID string, calendar *models.BusinessCalendar) error {
+    calendar.TenantID = tenantID
+    calendar.UpdatedAt = time.Now()
+    _, err := r.calendars(tenantID).ReplaceOne(context.TODO(), scoped(tenantID, bson.M{}), calendar, options.Replace().SetUpsert(true))
+    return err
+}
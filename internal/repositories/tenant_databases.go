@@ -0,0 +1,95 @@
+package repositories
+
+import (
+    "context"
+    "log"
+    "sync"
+
+    "go.mongodb.org/mongo-driver/bson"
+    "go.mongodb.org/mongo-driver/mongo"
+)
+
+const controlDatabase = "insurance"
+
+// TenantDatabases resolves the database holding a tenant's data. In "shared"
+// mode every tenant lives in the control database and is separated only by
+// tenant_id; in "database" mode each tenant gets its own database. Queries are
+// scoped by tenant_id in both modes.
+type TenantDatabases interface {
+    Control() *mongo.Database
+    Database(tenantID string) *mongo.Database
+}
+
+type tenantDatabases struct {
+    client *mongo.Client
+    mode   string
+}
+
+func NewTenantDatabases(client *mongo.Client, mode string) TenantDatabases {
+    return &tenantDatabases{client, mode}
+}
+
+func (d *tenantDatabases) Control() *mongo.Database {
+    return d.client.Database(controlDatabase)
+}
+
+func (d *tenantDatabases) Database(tenantID string) *mongo.Database {
+    if d.mode == "database" {
+        return d.client.Database(controlDatabase + "_" + tenantID)
+    }
+    return d.Control()
+}
+
+// indexSet creates a collection's indexes the first time each database is
+// used. Failures are logged and retried on the next use.
+type indexSet struct {
+    models []mongo.IndexModel
+    done   sync.Map
+}
+
+func newIndexSet(models ...mongo.IndexModel) *indexSet {
+    return &indexSet{models: models}
+}
+
+func (s *indexSet) ensure(coll *mongo.Collection) *mongo.Collection {
+    key := coll.Database().Name()
+    if _, done := s.done.LoadOrStore(key, true); done {
+        return coll
+    }
+    // One at a time, so an index that cannot be built (e.g. a unique index
+    // over existing duplicates) does not hold back the others.
+    for _, model := range s.models {
+        if _, err := coll.Indexes().CreateOne(context.TODO(), model); err != nil {
+            log.Println("cannot create index on", coll.Name()+":", err)
+            s.done.Delete(key)
+        }
+    }
+    return coll
+}
+
+// scoped returns a copy of filter restricted to tenantID.
+func scoped(tenantID string, filter bson.M) bson.M {
+    out := bson.M{}
+    for k, v := range filter {
+        out[k] = v
+    }
+    out["tenant_id"] = tenantID
+    return out
+}
+
+// BackfillTenant assigns documents created before multi-tenancy to tenantID.
+func BackfillTenant(dbs TenantDatabases, tenantID string, collections ...string) {
+    for _, name := range collections {
+        res, err := dbs.Database(tenantID).Collection(name).UpdateMany(context.TODO(),
+            bson.M{"tenant_id": bson.M{"$exists": false}},
+            bson.M{"$set": bson.M{"tenant_id": tenantID}},
+        )
+        if err != nil {
+            log.Println("tenant backfill failed for", name+":", err)
+            continue
+        }
+        if res.ModifiedCount > 0 {
+            log.Printf("assigned %d %s to tenant %s", res.ModifiedCount, name, tenantID)
+        }
+    }
+}
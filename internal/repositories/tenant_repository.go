@@ -0,0 +1,64 @@
+package repositories
+
+import (
+    "context"
+    "insurance-claims-api/internal/models"
+    "time"
+
+    "go.mongodb.org/mongo-driver/bson"
+    "go.mongodb.org/mongo-driver/mongo"
+    "go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// TenantRepository is the only repository that is not tenant-scoped; tenants
+// themselves live in the control database.
+type TenantRepository interface {
+    Create(tenant *models.Tenant) error
+    FindByID(id string) (*models.Tenant, error)
+    FindAll(ctx context.Context) ([]models.Tenant, error)
+    Update(tenant *models.Tenant) error
+}
+
+type tenantRepository struct {
+    collection *mongo.Collection
+}
+
+func NewTenantRepository(dbs TenantDatabases) TenantRepository {
+    return &tenantRepository{dbs.Control().Collection("tenants")}
+}
+
+func (r *tenantRepository) Create(tenant *models.Tenant) error {
+    tenant.CreatedAt = time.Now()
+    tenant.UpdatedAt = time.Now()
+    _, err := r.collection.InsertOne(context.TODO(), tenant)
+    return err
+}
+
+func (r *tenantRepository) FindByID(id string) (*models.Tenant, error) {
+    var tenant models.Tenant
+    err := r.collection.FindOne(context.TODO(), bson.M{"_id": id}).Decode(&tenant)
+    if err != nil {
+        return nil, err
+    }
+    return &tenant, nil
+}
+
+func (r *tenantRepository) FindAll(ctx context.Context) ([]models.Tenant, error) {
+    cursor, err := r.collection.Find(ctx, bson.M{}, options.Find().SetSort(bson.M{"_id": 1}))
+    if err != nil {
+        return nil, err
+    }
+    defer cursor.Close(context.TODO())
+
+    var tenants []models.Tenant
+    if err = cursor.All(context.TODO(), &tenants); err != nil {
+        return nil, err
+    }
+    return tenants, nil
+}
+
+func (r *tenantRepository) Update(tenant *models.Tenant) error {
+    tenant.UpdatedAt = time.Now()
+    _, err := r.collection.UpdateOne(context.TODO(), bson.M{"_id": tenant.ID}, bson.M{"$set": tenant})
+    return err
+}
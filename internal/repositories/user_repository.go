@@ -7,42 +7,99 @@ import (
     "go.mongodb.org/mongo-driver/bson"
     "go.mongodb.org/mongo-driver/bson/primitive"
     "go.mongodb.org/mongo-driver/mongo"
+    "go.mongodb.org/mongo-driver/mongo/options"
 )
 
 type UserRepository interface {
-    FindByUsername(username string) (*models.User, error)
-    Create(user *models.User) error
-    FindByID(id primitive.ObjectID) (*models.User, error)
+    FindByUsername(tenantID, username string) (*models.User, error)
+    Create(tenantID string, user *models.User) error
+    FindByID(tenantID string, id primitive.ObjectID) (*models.User, error)
+    FindBySubject(tenantID, provider, subject string) (*models.User, error)
+    FindByEmail(tenantID, email string) (*models.User, error)
+    Update(tenantID string, user *models.User) error
+    FindByRoles(tenantID string, roles []string) ([]models.User, error)
 }
 
 type userRepository struct {
-    collection *mongo.Collection
+    dbs     TenantDatabases
+    indexes *indexSet
 }
 
-func NewUserRepository(client *mongo.Client) UserRepository {
-    collection := client.Database("insurance").Collection("users")
-    return &userRepository{collection}
+func NewUserRepository(dbs TenantDatabases) UserRepository {
+    return &userRepository{dbs, newIndexSet(
+        mongo.IndexModel{Keys: bson.D{{Key: "tenant_id", Value: 1}, {Key: "username", Value: 1}}, Options: options.Index().SetUnique(true)},
+        mongo.IndexModel{Keys: bson.D{{Key: "tenant_id", Value: 1}, {Key: "provider", Value: 1}, {Key: "subject", Value: 1}}},
+        mongo.IndexModel{Keys: bson.D{{Key: "tenant_id", Value: 1}, {Key: "email", Value: 1}}},
+        mongo.IndexModel{Keys: bson.D{{Key: "tenant_id", Value: 1}, {Key: "role", Value: 1}}},
+    )}
 }
 
-func (r *userRepository) FindByUsername(username string) (*models.User, error) {
+func (r *userRepository) collection(tenantID string) *mongo.Collection {
+    return r.indexes.ensure(r.dbs.Database(tenantID).Collection("users"))
+}
+
+func (r *userRepository) FindByUsername(tenantID, username string) (*models.User, error) {
     var user models.User
-    err := r.collection.FindOne(context.TODO(), bson.M{"username": username}).Decode(&user)
+    err := r.collection(tenantID).FindOne(context.TODO(), scoped(tenantID, bson.M{"username": username})).Decode(&user)
     if err != nil {
         return nil, err
     }
     return &user, nil
 }
 
-func (r *userRepository) Create(user *models.User) error {
-    _, err := r.collection.InsertOne(context.TODO(), user)
+func (r *userRepository) Create(tenantID string, user *models.User) error {
+    if user.ID.IsZero() {
+        user.ID = primitive.NewObjectID()
+    }
+    user.TenantID = tenantID
+    _, err := r.collection(tenantID).InsertOne(context.TODO(), user)
     return err
 }
 
-func (r *userRepository) FindByID(id primitive.ObjectID) (*models.User, error) {
+func (r *userRepository) FindByID(tenantID string, id primitive.ObjectID) (*models.User, error) {
     var user models.User
-    err := r.collection.FindOne(context.TODO(), bson.M{"_id": id}).Decode(&user)
+    err := r.collection(tenantID).FindOne(context.TODO(), scoped(tenantID, bson.M{"_id": id})).Decode(&user)
     if err != nil {
         return nil, err
     }
     return &user, nil
-}
\ No newline at end of file
+}
+
+func (r *userRepository) FindBySubject(tenantID, provider, subject string) (*models.User, error) {
+    var user models.User
+    err := r.collection(tenantID).FindOne(context.TODO(), scoped(tenantID, bson.M{"provider": provider, "subject": subject})).Decode(&user)
+    if err != nil {
+        return nil, err
+    }
+    return &user, nil
+}
+
+func (r *userRepository) FindByEmail(tenantID, email string) (*models.User, error) {
+    var user models.User
+    err := r.collection(tenantID).FindOne(context.TODO(), scoped(tenantID, bson.M{"email": email})).Decode(&user)
+    if err != nil {
+        return nil, err
+    }
+    return &user, nil
+}
+
+func (r *userRepository) Update(tenantID string, user *models.User) error {
+    user.TenantID = tenantID
+    _, err := r.collection(tenantID).UpdateOne(context.TODO(), scoped(tenantID, bson.M{"_id": user.ID}), bson.M{"$set": user})
+    return err
+}
+
+func (r *userRepository) FindByRoles(tenantID string, roles []string) ([]models.User, error) {
+    opts := options.Find().SetSort(bson.M{"_id": 1})
+    cursor, err := r.collection(tenantID).Find(context.TODO(), scoped(tenantID, bson.M{"role": bson.M{"$in": roles}}), opts)
+    if err != nil {
+        return nil, err
+    }
+    defer cursor.Close(context.TODO())
+
+    var users []models.User
+    if err = cursor.All(context.TODO(), &users); err != nil {
+        return nil, err
+    }
+    return users, nil
+}
@@ -0,0 +1,44 @@
+package services
+
+import (
+    "insurance-claims-api/internal/models"
+    "insurance-claims-api/internal/repositories"
+    "log"
+
+    "go.mongodb.org/mongo-driver/bson"
+    "go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type AuditService interface {
+    Record(tenantID string, entry models.AuditLog)
+    List(actor models.Actor, filter models.AuditLogFilter, page, limit int) ([]models.AuditLog, int64, error)
+}
+
+type auditService struct {
+    auditRepo repositories.AuditRepository
+}
+
+func NewAuditService(auditRepo repositories.AuditRepository) AuditService {
+    return &auditService{auditRepo}
+}
+
+// Record never fails the caller; a lost audit entry is logged instead.
+func (s *auditService) Record(tenantID string, entry models.AuditLog) {
+    if err := s.auditRepo.Create(tenantID, &entry); err != nil {
+        log.Println("audit log write failed:", entry.Action, err)
+    }
+}
+
+func (s *auditService) List(actor models.Actor, filter models.AuditLogFilter, page, limit int) ([]models.AuditLog, int64, error) {
+    query := bson.M{}
+    if filter.Action != "" {
+        query["action"] = filter.Action
+    }
+    if id, err := primitive.ObjectIDFromHex(filter.ActorID); err == nil {
+        query["actor_id"] = id
+    }
+    if id, err := primitive.ObjectIDFromHex(filter.SubjectID); err == nil {
+        query["subject_id"] = id
+    }
+    return s.auditRepo.FindAll(actor.TenantID, query, page, limit)
+}
@@ -5,6 +5,7 @@ import (
     "insurance-claims-api/internal/config"
     "insurance-claims-api/internal/models"
     "insurance-claims-api/internal/repositories"
+    "insurance-claims-api/internal/utils"
     "time"
 
     "github.com/golang-jwt/jwt/v5"
@@ -12,38 +13,105 @@ import (
     "golang.org/x/crypto/bcrypt"
 )
 
+const impersonationTTL = time.Hour
+
+var (
+    ErrInvalidCredentials = utils.NewError(utils.KindUnauthorized, "invalid_credentials", "invalid credentials")
+    ErrUserNotFound       = utils.NewError(utils.KindNotFound, "user_not_found", "user not found")
+)
+
 type AuthService interface {
     Login(req models.LoginRequest) (*models.LoginResponse, error)
+    Impersonate(actor models.Actor, req models.ImpersonateRequest) (*models.LoginResponse, error)
 }
 
 type authService struct {
-    userRepo repositories.UserRepository
+    userRepo      repositories.UserRepository
+    tenantService TenantService
+    auditService  AuditService
 }
 
-func NewAuthService(userRepo repositories.UserRepository) AuthService {
-    return &authService{userRepo}
+func NewAuthService(userRepo repositories.UserRepository, tenantService TenantService, auditService AuditService) AuthService {
+    return &authService{userRepo, tenantService, auditService}
 }
 
 func (s *authService) Login(req models.LoginRequest) (*models.LoginResponse, error) {
-    user, err := s.userRepo.FindByUsername(req.Username)
-    if err != nil || bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(req.Password)) != nil {
-        return nil, errors.New("invalid credentials")
+    if req.Tenant == "" {
+        req.Tenant = config.AppConfig.DefaultTenant
+    }
+    if _, err := s.tenantService.Resolve(req.Tenant); err != nil {
+        if errors.Is(err, ErrTenantInactive) {
+            return nil, ErrInvalidCredentials
+        }
+        return nil, err
+    }
+    user, err := s.userRepo.FindByUsername(req.Tenant, req.Username)
+    if err != nil {
+        return nil, notFound(err, ErrInvalidCredentials)
+    }
+    if user.Provider != "" || bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(req.Password)) != nil {
+        return nil, ErrInvalidCredentials
+    }
+    return issueToken(user)
+}
+
+// Impersonate issues a short-lived, read-only token for another user of the
+// same tenant. The token carries the real actor so every use can be audited.
+func (s *authService) Impersonate(actor models.Actor, req models.ImpersonateRequest) (*models.LoginResponse, error) {
+    if actor.ImpersonatorID != nil {
+        return nil, ErrForbidden
+    }
+    user, err := s.userRepo.FindByID(actor.TenantID, req.UserID)
+    if err != nil {
+        return nil, notFound(err, ErrUserNotFound)
+    }
+    if user.Role == models.RoleAdmin || user.ID == actor.UserID {
+        return nil, ErrForbidden
     }
 
-    token := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
-        "user_id": user.ID.Hex(),
-        "role":    user.Role,
-        "exp":     time.Now().Add(time.Hour * 24 * 7).Unix(),
+    resp, err := signToken(user, jwt.MapClaims{"impersonator_id": actor.UserID.Hex()}, impersonationTTL)
+    if err != nil {
+        return nil, err
+    }
+    resp.ImpersonatedBy = &actor.UserID
+
+    s.auditService.Record(actor.TenantID, models.AuditLog{
+        Action:    models.AuditImpersonationStarted,
+        ActorID:   actor.UserID,
+        SubjectID: &user.ID,
+        Details:   map[string]interface{}{"reason": req.Reason, "expires_in": impersonationTTL.String()},
     })
+    return resp, nil
+}
 
-    tokenString, _ := token.SignedString([]byte(config.AppConfig.JWTSecret))
+func issueToken(user *models.User) (*models.LoginResponse, error) {
+    return signToken(user, nil, time.Hour*24*7)
+}
+
+func signToken(user *models.User, extra jwt.MapClaims, ttl time.Duration) (*models.LoginResponse, error) {
+    claims := jwt.MapClaims{
+        "user_id":   user.ID.Hex(),
+        "tenant_id": user.TenantID,
+        "role":      user.Role,
+        "exp":       time.Now().Add(ttl).Unix(),
+    }
+    for k, v := range extra {
+        claims[k] = v
+    }
+    token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+
+    tokenString, err := token.SignedString([]byte(config.AppConfig.JWTSecret))
+    if err != nil {
+        return nil, err
+    }
 
     resp := &models.LoginResponse{
         Token: tokenString,
     }
     resp.User.ID = user.ID
+    resp.User.TenantID = user.TenantID
     resp.User.Username = user.Username
     resp.User.Role = user.Role
 
     return resp, nil
-}
\ No newline at end of file
+}
@@ -0,0 +1,91 @@
+package services
+
+import (
+    "insurance-claims-api/internal/models"
+    "time"
+)
+
+// maxCalendarDays bounds calendar walks so a misconfigured calendar with no
+// working days cannot loop forever.
+const maxCalendarDays = 3660
+
+type businessCalendar struct {
+    loc      *time.Location
+    working  map[time.Weekday]bool
+    holidays map[string]bool
+}
+
+func defaultCalendar(timezone string) models.BusinessCalendar {
+    return models.BusinessCalendar{
+        Timezone:    timezone,
+        WorkingDays: []time.Weekday{time.Monday, time.Tuesday, time.Wednesday, time.Thursday, time.Friday},
+    }
+}
+
+func newBusinessCalendar(cal models.BusinessCalendar) (*businessCalendar, error) {
+    loc, err := time.LoadLocation(cal.Timezone)
+    if err != nil {
+        return nil, err
+    }
+    c := &businessCalendar{loc: loc, working: map[time.Weekday]bool{}, holidays: map[string]bool{}}
+    for _, d := range cal.WorkingDays {
+        c.working[d] = true
+    }
+    for _, h := range cal.Holidays {
+        c.holidays[h] = true
+    }
+    return c, nil
+}
+
+func (c *businessCalendar) isBusinessDay(day time.Time) bool {
+    return c.working[day.Weekday()] && !c.holidays[day.Format("2006-01-02")]
+}
+
+func (c *businessCalendar) startOfDay(t time.Time) time.Time {
+    t = t.In(c.loc)
+    return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, c.loc)
+}
+
+// businessDays measures the time between from and to in business days,
+// counting partial days proportionally.
+func (c *businessCalendar) businessDays(from, to time.Time) float64 {
+    total := 0.0
+    day := c.startOfDay(from)
+    for i := 0; day.Before(to) && i < maxCalendarDays; i++ {
+        next := day.AddDate(0, 0, 1)
+        if c.isBusinessDay(day) {
+            start, end := day, next
+            if from.After(start) {
+                start = from
+            }
+            if to.Before(end) {
+                end = to
+            }
+            if end.After(start) {
+                total += float64(end.Sub(start)) / float64(next.Sub(day))
+            }
+        }
+        day = next
+    }
+    return total
+}
+
+// addBusinessDays returns the instant n business days after from.
+func (c *businessCalendar) addBusinessDays(from time.Time, n float64) time.Time {
+    day := c.startOfDay(from)
+    cursor := from
+    for i := 0; i < maxCalendarDays; i++ {
+        next := day.AddDate(0, 0, 1)
+        if c.isBusinessDay(day) {
+            length := float64(next.Sub(day))
+            available := float64(next.Sub(cursor)) / length
+            if n <= available {
+                return cursor.Add(time.Duration(n * length))
+            }
+            n -= available
+        }
+        day = next
+        cursor = next
+    }
+    return cursor
+}
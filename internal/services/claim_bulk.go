@@ -0,0 +1,107 @@
+package services
+
+import (
+    "errors"
+    "fmt"
+    "insurance-claims-api/internal/models"
+    "insurance-claims-api/internal/utils"
+    "time"
+
+    "go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// BulkLimit caps how many claims one bulk action may touch.
+const BulkLimit = 500
+
+var errBulkLimit = errors.New("bulk limit reached")
+
+// bulkStatuses are the statuses each bulk action can move a claim out of; a
+// filter without a status is narrowed to them.
+var bulkStatuses = map[string][]models.ClaimStatus{
+    models.BulkReview:  {models.Submitted},
+    models.BulkApprove: {models.Reviewed, models.Appealed},
+    models.BulkReject:  {models.Reviewed, models.Appealed},
+}
+
+// BulkAction runs the single-claim transition for every selected claim, so
+// each one is authorized and checked on its own. Failures are reported per
+// item and do not stop the batch; every history entry and the audit entry
+// share one correlation ID.
+func (s *claimService) BulkAction(actor models.Actor, action string, req models.BulkActionRequest) (*models.BulkActionResult, error) {
+    statuses, ok := bulkStatuses[action]
+    if !ok {
+        return nil, utils.NewError(utils.KindBadRequest, utils.CodeBadRequest, "unknown bulk action")
+    }
+    if (len(req.IDs) == 0) == (req.Filter == nil) {
+        return nil, utils.ValidationError(utils.FieldError{Field: "ids", Code: "ids_or_filter", Message: "provide either ids or filter"})
+    }
+
+    ids := req.IDs
+    if req.Filter != nil {
+        base, err := claimListFilter(actor)
+        if err != nil {
+            return nil, err
+        }
+        query := *req.Filter
+        if len(query.Status) == 0 {
+            query.Status = statuses
+        }
+        filter, sort := claimQuery(base, query)
+        err = s.claimRepo.ForEachSorted(actor.TenantID, filter, sort, func(claim *models.Claim) error {
+            if len(ids) == BulkLimit {
+                return errBulkLimit
+            }
+            ids = append(ids, claim.ID)
+            return nil
+        })
+        if errors.Is(err, errBulkLimit) {
+            return nil, utils.ValidationError(utils.FieldError{Field: "filter", Code: "too_large", Message: fmt.Sprintf("matches more than %d claims", BulkLimit)})
+        }
+        if err != nil {
+            return nil, err
+        }
+    }
+
+    result := &models.BulkActionResult{
+        CorrelationID: primitive.NewObjectID().Hex(),
+        Action:        action,
+        Items:         []models.BulkItemResult{},
+    }
+    for _, id := range ids {
+        var err error
+        switch action {
+        case models.BulkReview:
+            err = s.review(actor, id, models.AnyVersion, req.Note, result.CorrelationID)
+        case models.BulkApprove:
+            err = s.approve(actor, id, models.AnyVersion, result.CorrelationID)
+        case models.BulkReject:
+            err = s.reject(actor, id, models.AnyVersion, req.Note, result.CorrelationID)
+        }
+        item := models.BulkItemResult{ID: id, Success: err == nil}
+        if err != nil {
+            e := utils.Public(err)
+            item.Code, item.Error = e.Code, e.Error()
+            result.Failed++
+        } else {
+            result.Succeeded++
+            if claim, err := s.claimRepo.FindByID(actor.TenantID, id); err == nil {
+                item.Reference = claim.Reference
+            }
+        }
+        result.Items = append(result.Items, item)
+    }
+
+    s.audit.Record(actor.TenantID, models.AuditLog{
+        Action:  models.AuditClaimBulkAction,
+        ActorID: actor.UserID,
+        Details: map[string]interface{}{
+            "correlation_id": result.CorrelationID,
+            "action":         action,
+            "succeeded":      result.Succeeded,
+            "failed":         result.Failed,
+            "items":          result.Items,
+        },
+        At: time.Now(),
+    })
+    return result, nil
+}
@@ -0,0 +1,217 @@
+package services
+
+import (
+    "bytes"
+    "encoding/json"
+    "fmt"
+    "insurance-claims-api/internal/models"
+    "insurance-claims-api/internal/utils"
+    "sort"
+    "strings"
+    "time"
+
+    "github.com/gin-gonic/gin/binding"
+    "go.mongodb.org/mongo-driver/bson"
+    "go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+var ErrFieldNotEditable = utils.NewError(utils.KindForbidden, "field_not_editable", "some fields cannot be changed")
+
+// claimPatchFields lists the fields each update permission may change in
+// each status: claim:update:own covers the claimant's fields and
+// claim:update:any the staff fields. Anything not listed, including every
+// field of a status missing here, is read-only.
+var claimPatchFields = map[models.ClaimStatus]map[string][]string{
+    models.Draft: {
+        models.PermClaimUpdateOwn: {"policy_number", "claim_amount", "description", "claim_type", "documents", "policy_start_date", "incident_date"},
+    },
+    models.Submitted: {
+        models.PermClaimUpdateOwn: {"documents"},
+        models.PermClaimUpdateAny: {"claim_type", "policy_start_date", "incident_date"},
+    },
+    models.Reviewed: {
+        models.PermClaimUpdateAny: {"claim_type"},
+    },
+}
+
+// editableFields returns the fields actor may change on claim.
+func editableFields(actor models.Actor, claim *models.Claim) map[string]bool {
+    allowed := map[string]bool{}
+    for perm, fields := range claimPatchFields[claim.Status] {
+        if authorizeClaim(actor, perm, claim) != nil {
+            continue
+        }
+        for _, f := range fields {
+            allowed[f] = true
+        }
+    }
+    return allowed
+}
+
+// nonNilDocuments keeps an empty document list as [] rather than null, so
+// JSON Patch can append to it with "/documents/-".
+func nonNilDocuments(docs []string) []string {
+    if docs == nil {
+        return []string{}
+    }
+    return docs
+}
+
+func claimFields(claim *models.Claim) models.ClaimFields {
+    return models.ClaimFields{
+        PolicyNumber: claim.PolicyNumber,
+        ClaimAmount:  claim.ClaimAmount,
+        Description:  claim.Description,
+        ClaimType:    claim.ClaimType,
+        Documents:    nonNilDocuments(claim.Documents),
+        PolicyStart:  claim.PolicyStart,
+        IncidentDate: claim.IncidentDate,
+    }
+}
+
+// UpdateClaim applies a JSON Merge Patch or JSON Patch to the editable
+// fields of a claim and returns the updated claim.
+func (s *claimService) UpdateClaim(actor models.Actor, claimID primitive.ObjectID, version int64, contentType string, patch []byte) (*models.Claim, error) {
+    claim, err := s.claimRepo.FindByID(actor.TenantID, claimID)
+    if err != nil {
+        return nil, notFound(err, ErrClaimNotFound)
+    }
+    if err := authorizeClaim(actor, models.PermClaimUpdate, claim); err != nil {
+        return nil, err
+    }
+    if err := checkVersion(claim, version); err != nil {
+        return nil, err
+    }
+
+    before, err := json.Marshal(claimFields(claim))
+    if err != nil {
+        return nil, err
+    }
+    var patched []byte
+    switch contentType {
+    case models.MergePatchContentType, "application/json":
+        patched, err = utils.ApplyMergePatch(before, patch)
+    case models.JSONPatchContentType:
+        patched, err = utils.ApplyJSONPatch(before, patch)
+    default:
+        return nil, utils.NewError(utils.KindUnsupportedMediaType, utils.CodeUnsupportedMediaType, fmt.Sprintf("unsupported patch type %q", contentType))
+    }
+    if err != nil {
+        return nil, &utils.Error{Kind: utils.KindValidation, Code: "invalid_patch", Message: err.Error(), Err: err}
+    }
+
+    var after models.ClaimFields
+    dec := json.NewDecoder(bytes.NewReader(patched))
+    dec.DisallowUnknownFields()
+    if err := dec.Decode(&after); err != nil {
+        if field := strings.TrimPrefix(err.Error(), "json: unknown field "); field != err.Error() {
+            return nil, ErrFieldNotEditable.WithFields(utils.FieldError{Field: strings.Trim(field, `"`), Code: "read_only", Message: "cannot be changed"})
+        }
+        return nil, utils.BindingError(err)
+    }
+    after.Documents = nonNilDocuments(after.Documents)
+    changed, err := changedClaimFields(before, after)
+    if err != nil {
+        return nil, err
+    }
+    if len(changed) == 0 {
+        return visibleClaim(actor, claim), nil
+    }
+    allowed := editableFields(actor, claim)
+    var denied []utils.FieldError
+    for _, f := range changed {
+        if !allowed[f] {
+            denied = append(denied, utils.FieldError{Field: f, Code: "read_only", Message: fmt.Sprintf("cannot be changed while the claim is %s", claim.Status)})
+        }
+    }
+    if len(denied) > 0 {
+        return nil, ErrFieldNotEditable.WithFields(denied...)
+    }
+    if err := binding.Validator.ValidateStruct(&after); err != nil {
+        return nil, utils.BindingError(err)
+    }
+
+    now := time.Now()
+    set := bson.M{"updated_at": now}
+    unset := bson.M{}
+    for _, f := range changed {
+        switch f {
+        case "policy_number":
+            set[f] = after.PolicyNumber
+        case "claim_amount":
+            set[f] = after.ClaimAmount
+        case "description":
+            set[f] = after.Description
+        case "policy_start_date":
+            setOrUnset(set, unset, f, after.PolicyStart)
+        case "incident_date":
+            setOrUnset(set, unset, f, after.IncidentDate)
+        case "documents":
+            if len(after.Documents) == 0 {
+                unset[f] = ""
+            } else {
+                set[f] = after.Documents
+            }
+        case "claim_type":
+            if after.ClaimType == "" {
+                unset[f] = ""
+            } else {
+                set[f] = after.ClaimType
+            }
+        }
+    }
+    update := bson.M{"$set": set, "$push": bson.M{"history": models.ClaimHistory{
+        Status:    claim.Status,
+        ChangedBy: actor.UserID,
+        ChangedAt: now,
+        Event:     "updated",
+        Note:      "changed " + strings.Join(changed, ", "),
+    }}}
+    if len(unset) > 0 {
+        update["$unset"] = unset
+    }
+    ok, err := s.claimRepo.UpdateIf(actor.TenantID, claimID, versionCond(bson.M{"status": claim.Status}, version), update)
+    if err != nil {
+        return nil, err
+    }
+    if !ok {
+        return nil, conflictError(version)
+    }
+    updated, err := s.claimRepo.FindByID(actor.TenantID, claimID)
+    if err != nil {
+        return nil, err
+    }
+    return visibleClaim(actor, updated), nil
+}
+
+func setOrUnset(set, unset bson.M, field string, t *time.Time) {
+    if t == nil {
+        unset[field] = ""
+    } else {
+        set[field] = *t
+    }
+}
+
+// changedClaimFields returns the names of the fields whose JSON differs
+// between before and after.
+func changedClaimFields(before []byte, after models.ClaimFields) ([]string, error) {
+    afterJSON, err := json.Marshal(after)
+    if err != nil {
+        return nil, err
+    }
+    var old, cur map[string]json.RawMessage
+    if err := json.Unmarshal(before, &old); err != nil {
+        return nil, err
+    }
+    if err := json.Unmarshal(afterJSON, &cur); err != nil {
+        return nil, err
+    }
+    var changed []string
+    for f, v := range cur {
+        if !bytes.Equal(v, old[f]) {
+            changed = append(changed, f)
+        }
+    }
+    sort.Strings(changed)
+    return changed, nil
+}
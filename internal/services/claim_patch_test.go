@@ -0,0 +1,78 @@
+package services
+
+import (
+    "encoding/json"
+    "insurance-claims-api/internal/models"
+    "insurance-claims-api/internal/utils"
+    "reflect"
+    "sort"
+    "testing"
+
+    "go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestAppendToEmptyDocuments(t *testing.T) {
+    claim := &models.Claim{PolicyNumber: "POL-1", ClaimAmount: 100, Description: "Kaca depan retak"}
+    before, err := json.Marshal(claimFields(claim))
+    if err != nil {
+        t.Fatal(err)
+    }
+    patched, err := utils.ApplyJSONPatch(before, []byte(`[{"op": "add", "path": "/documents/-", "value": "foto-1.jpg"}]`))
+    if err != nil {
+        t.Fatal(err)
+    }
+    var after models.ClaimFields
+    if err := json.Unmarshal(patched, &after); err != nil {
+        t.Fatal(err)
+    }
+    changed, err := changedClaimFields(before, after)
+    if err != nil {
+        t.Fatal(err)
+    }
+    if !reflect.DeepEqual(after.Documents, []string{"foto-1.jpg"}) || !reflect.DeepEqual(changed, []string{"documents"}) {
+        t.Errorf("documents = %v, changed = %v", after.Documents, changed)
+    }
+}
+
+func TestEditableFields(t *testing.T) {
+    owner := primitive.NewObjectID()
+    grants := func(perms ...string) []models.Grant {
+        var gs []models.Grant
+        for _, p := range perms {
+            gs = append(gs, models.Grant{Permission: p})
+        }
+        return gs
+    }
+    tests := []struct {
+        name   string
+        actor  models.Actor
+        status models.ClaimStatus
+        want   []string
+    }{
+        {"owner edits draft", models.Actor{UserID: owner, Role: "custom", Grants: grants(models.PermClaimUpdateOwn)}, models.Draft,
+            []string{"claim_amount", "claim_type", "description", "documents", "incident_date", "policy_number", "policy_start_date"}},
+        {"owner adds documents after submit", models.Actor{UserID: owner, Grants: grants(models.PermClaimUpdateOwn)}, models.Submitted,
+            []string{"documents"}},
+        {"own grant does not reach other claims", models.Actor{UserID: primitive.NewObjectID(), Grants: grants(models.PermClaimUpdateOwn)}, models.Submitted,
+            nil},
+        {"staff grant under any role name", models.Actor{UserID: primitive.NewObjectID(), Role: "claims-officer", Grants: grants(models.PermClaimUpdateAny)}, models.Submitted,
+            []string{"claim_type", "incident_date", "policy_start_date"}},
+        {"staff cannot edit drafts", models.Actor{UserID: primitive.NewObjectID(), Grants: grants(models.PermClaimUpdateAny)}, models.Draft,
+            nil},
+        {"nothing editable once approved", models.Actor{UserID: owner, Grants: grants(models.PermClaimUpdateOwn, models.PermClaimUpdateAny)}, models.Approved,
+            nil},
+    }
+    for _, tt := range tests {
+        t.Run(tt.name, func(t *testing.T) {
+            claim := &models.Claim{UserID: owner, Status: tt.status}
+            var got []string
+            for f := range editableFields(tt.actor, claim) {
+                got = append(got, f)
+            }
+            sort.Strings(got)
+            if !reflect.DeepEqual(got, tt.want) {
+                t.Errorf("got %v, want %v", got, tt.want)
+            }
+        })
+    }
+}
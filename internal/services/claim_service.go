@@ -1,44 +1,88 @@
 package services
 
 import (
-    "errors"
+    "fmt"
+    "insurance-claims-api/internal/config"
     "insurance-claims-api/internal/models"
     "insurance-claims-api/internal/repositories"
+    "insurance-claims-api/internal/utils"
+    "log"
+    "regexp"
+    "strings"
     "time"
     "go.mongodb.org/mongo-driver/bson"
     "go.mongodb.org/mongo-driver/bson/primitive"
 )
 
+var (
+    ErrClaimNotFound     = utils.NewError(utils.KindNotFound, "claim_not_found", "claim not found")
+    ErrInvalidTransition = utils.NewError(utils.KindConflict, "invalid_transition", "the claim's status does not allow this action")
+    ErrConcurrentUpdate  = utils.NewError(utils.KindConflict, "concurrent_update", "claim was changed by another user")
+    ErrCheckoutRequired  = utils.NewError(utils.KindConflict, "checkout_required", "claim must be checked out from the queue first")
+    ErrOwnClaim          = utils.NewError(utils.KindForbidden, "own_claim", "cannot decide a claim you or the delegator submitted")
+
+    // ErrVersionMismatch means the claim changed since the client read the
+    // version it sent in If-Match.
+    ErrVersionMismatch = utils.NewError(utils.KindPreconditionFailed, "version_mismatch", "claim has been modified; reload it and try again")
+)
+
 type ClaimService interface {
-    CreateClaim(userID primitive.ObjectID, req models.CreateClaimRequest) (*models.Claim, error)
-    GetMyClaims(userID primitive.ObjectID, page, limit int) ([]models.Claim, int64, error)
-    GetAllClaims(role string, page, limit int) ([]models.Claim, int64, error)
-    GetClaimByID(userID primitive.ObjectID, role string, claimID primitive.ObjectID) (*models.Claim, error)
-    UpdateClaim(userID primitive.ObjectID, claimID primitive.ObjectID, req models.UpdateClaimRequest) error
-    DeleteClaim(userID primitive.ObjectID, claimID primitive.ObjectID) error
-    SubmitClaim(userID primitive.ObjectID, claimID primitive.ObjectID) error
-    ReviewClaim(verifierID primitive.ObjectID, claimID primitive.ObjectID, note string) error
-    ApproveClaim(approverID primitive.ObjectID, claimID primitive.ObjectID) error
-    RejectClaim(approverID primitive.ObjectID, claimID primitive.ObjectID, reason string) error
+    CreateClaim(actor models.Actor, req models.CreateClaimRequest) (*models.Claim, error)
+    GetMyClaims(actor models.Actor, page, limit int) ([]models.Claim, int64, error)
+    GetAllClaims(actor models.Actor, query models.ClaimListQuery, page, limit int) ([]models.Claim, int64, error)
+    GetClaimByID(actor models.Actor, claimID primitive.ObjectID) (*models.Claim, error)
+    GetClaimByReference(actor models.Actor, reference string) (*models.Claim, error)
+    UpdateClaim(actor models.Actor, claimID primitive.ObjectID, version int64, contentType string, patch []byte) (*models.Claim, error)
+    DeleteClaim(actor models.Actor, claimID primitive.ObjectID) error
+    SubmitClaim(actor models.Actor, claimID primitive.ObjectID, version int64, justification string) error
+    ReviewClaim(actor models.Actor, claimID primitive.ObjectID, version int64, note string) error
+    ApproveClaim(actor models.Actor, claimID primitive.ObjectID, version int64) error
+    RejectClaim(actor models.Actor, claimID primitive.ObjectID, version int64, reason string) error
+    RequestInfo(actor models.Actor, claimID primitive.ObjectID, version int64, note string) error
+    ProvideInfo(actor models.Actor, claimID primitive.ObjectID, version int64, note string) error
+    ResolveFraudReview(actor models.Actor, claimID primitive.ObjectID, version int64, cleared bool, note string) error
+    WithdrawClaim(actor models.Actor, claimID primitive.ObjectID, version int64, reason string) error
+    AppealClaim(actor models.Actor, claimID primitive.ObjectID, version int64, req models.AppealClaimRequest) error
+    ReopenClaim(actor models.Actor, claimID primitive.ObjectID, version int64, reason string) error
+    BulkAction(actor models.Actor, action string, req models.BulkActionRequest) (*models.BulkActionResult, error)
 }
 
 type claimService struct {
-    claimRepo repositories.ClaimRepository
-    userRepo  repositories.UserRepository
+    claimRepo  repositories.ClaimRepository
+    counters   repositories.CounterRepository
+    userRepo   repositories.UserRepository
+    queue      QueueService
+    rules      RuleService
+    fraud      FraudService
+    duplicates DuplicateService
+    audit      AuditService
+    documents  DocumentService
+    notify     NotificationService
+    tenants    TenantService
 }
 
-func NewClaimService(claimRepo repositories.ClaimRepository, userRepo repositories.UserRepository) ClaimService {
-    return &claimService{claimRepo, userRepo}
+func NewClaimService(claimRepo repositories.ClaimRepository, counters repositories.CounterRepository, userRepo repositories.UserRepository, queue QueueService, rules RuleService, fraud FraudService, duplicates DuplicateService, audit AuditService, documents DocumentService, notify NotificationService, tenants TenantService) ClaimService {
+    return &claimService{claimRepo, counters, userRepo, queue, rules, fraud, duplicates, audit, documents, notify, tenants}
 }
 
-func (s *claimService) CreateClaim(userID primitive.ObjectID, req models.CreateClaimRequest) (*models.Claim, error) {
+func (s *claimService) CreateClaim(actor models.Actor, req models.CreateClaimRequest) (*models.Claim, error) {
+    userID := actor.UserID
+    reference, err := s.nextReference(actor.TenantID)
+    if err != nil {
+        return nil, err
+    }
     claim := &models.Claim{
         ID:           primitive.NewObjectID(),
+        TenantID:     actor.TenantID,
+        Reference:    reference,
         UserID:       userID,
         PolicyNumber: req.PolicyNumber,
         ClaimAmount:  req.ClaimAmount,
         Description:  req.Description,
+        ClaimType:    req.ClaimType,
         Documents:    req.Documents,
+        PolicyStart:  req.PolicyStart,
+        IncidentDate: req.IncidentDate,
         Status:       models.Draft,
         CreatedAt:    time.Now(),
         UpdatedAt:    time.Now(),
@@ -51,77 +95,146 @@ func (s *claimService) CreateClaim(userID primitive.ObjectID, req models.CreateC
         },
     }
 
-    err := s.claimRepo.Create(claim)
+    err = s.claimRepo.Create(actor.TenantID, claim)
     if err != nil {
         return nil, err
     }
     return claim, nil
 }
 
-func (s *claimService) GetMyClaims(userID primitive.ObjectID, page, limit int) ([]models.Claim, int64, error) {
-    return s.claimRepo.FindByUserID(userID, page, limit)
+func (s *claimService) nextReference(tenantID string) (string, error) {
+    return nextClaimReference(s.counters, tenantID, time.Now().Year())
 }
 
-func (s *claimService) GetAllClaims(role string, page, limit int) ([]models.Claim, int64, error) {
-    if role == "user" {
-        return nil, 0, errors.New("forbidden")
+// nextClaimReference returns the next human-friendly claim number for year,
+// e.g. CLM-2026-000123. Numbering restarts every calendar year.
+func nextClaimReference(counters repositories.CounterRepository, tenantID string, year int) (string, error) {
+    seq, err := counters.Next(tenantID, fmt.Sprintf("claim-%d", year))
+    if err != nil {
+        return "", err
     }
-    filter := bson.M{}
-    if role == "verifier" {
-        filter = bson.M{"status": bson.M{"$in": []models.ClaimStatus{models.Submitted, models.Reviewed}}}
+    return fmt.Sprintf("CLM-%d-%06d", year, seq), nil
+}
+
+func (s *claimService) GetMyClaims(actor models.Actor, page, limit int) ([]models.Claim, int64, error) {
+    claims, total, err := s.claimRepo.FindByUserID(actor.TenantID, actor.UserID, page, limit)
+    if err != nil {
+        return nil, 0, err
     }
-    if role == "approver" {
-        filter = bson.M{"status": bson.M{"$in": []models.ClaimStatus{models.Reviewed, models.Approved, models.Rejected}}}
+    for i := range claims {
+        visibleClaim(actor, &claims[i])
     }
-    return s.claimRepo.FindAll(filter, page, limit)
+    return claims, total, nil
 }
 
-func (s *claimService) GetClaimByID(userID primitive.ObjectID, role string, claimID primitive.ObjectID) (*models.Claim, error) {
-    claim, err := s.claimRepo.FindByID(claimID)
-    if err != nil {
-        return nil, err
+// internalHistoryEvents are history entries that reveal fraud scoring or
+// duplicate detection.
+var internalHistoryEvents = map[string]bool{"fraud_scored": true, "fraud_flagged": true, "duplicate_detected": true}
+
+// visibleClaim hides fraud scoring and duplicate links from callers who can
+// only read their own claims, so a claimant cannot learn they are suspected.
+func visibleClaim(actor models.Actor, claim *models.Claim) *models.Claim {
+    if actor.Can(models.PermClaimReadAny) {
+        return claim
     }
-    if role != "verifier" && role != "approver" && claim.UserID != userID {
-        return nil, errors.New("forbidden")
+    claim.FraudFlagged = false
+    claim.FraudScore = 0
+    claim.Fraud = nil
+    claim.Duplicates = nil
+    history := make([]models.ClaimHistory, 0, len(claim.History))
+    for _, h := range claim.History {
+        if !internalHistoryEvents[h.Event] {
+            history = append(history, h)
+        }
     }
-    return claim, nil
+    claim.History = history
+    return claim
 }
 
-func (s *claimService) UpdateClaim(userID primitive.ObjectID, claimID primitive.ObjectID, req models.UpdateClaimRequest) error {
-    claim, err := s.claimRepo.FindByID(claimID)
+func (s *claimService) GetAllClaims(actor models.Actor, query models.ClaimListQuery, page, limit int) ([]models.Claim, int64, error) {
+    base, err := claimListFilter(actor)
     if err != nil {
-        return err
+        return nil, 0, err
+    }
+    filter, sort := claimQuery(base, query)
+    return s.claimRepo.FindSorted(actor.TenantID, filter, sort, page, limit)
+}
+
+// claimQuery narrows base, the claims the actor may list, by query and
+// returns the filter and sort order.
+func claimQuery(base bson.M, query models.ClaimListQuery) (bson.M, bson.D) {
+    and := []bson.M{base}
+    if query.Reference != "" {
+        and = append(and, bson.M{"reference": bson.M{"$regex": "^" + regexp.QuoteMeta(strings.ToUpper(query.Reference))}})
+    }
+    if len(query.Status) > 0 {
+        and = append(and, bson.M{"status": bson.M{"$in": query.Status}})
+    }
+    if query.Event != "" {
+        and = append(and, bson.M{"history.event": query.Event})
     }
-    if claim.UserID != userID || claim.Status != models.Draft {
-        return errors.New("cannot edit claim")
+    score := bson.M{}
+    if query.MinFraudScore != nil {
+        score["$gte"] = *query.MinFraudScore
     }
-    if req.PolicyNumber != "" {
-        claim.PolicyNumber = req.PolicyNumber
+    if query.MaxFraudScore != nil {
+        score["$lte"] = *query.MaxFraudScore
     }
-    if req.ClaimAmount > 0 {
-        claim.ClaimAmount = req.ClaimAmount
+    if len(score) > 0 {
+        and = append(and, bson.M{"fraud_score": score})
     }
-    if req.Description != "" {
-        claim.Description = req.Description
+    if query.Duplicates != nil {
+        and = append(and, bson.M{"duplicates.0": bson.M{"$exists": *query.Duplicates}})
     }
-    if req.Documents != nil {
-        claim.Documents = req.Documents
+
+    sort := bson.D{{Key: "created_at", Value: -1}}
+    if field := strings.TrimPrefix(query.Sort, "-"); field != "" {
+        dir := 1
+        if strings.HasPrefix(query.Sort, "-") {
+            dir = -1
+        }
+        sort = bson.D{{Key: field, Value: dir}, {Key: "created_at", Value: -1}}
+    }
+    return bson.M{"$and": and}, sort
+}
+
+func (s *claimService) GetClaimByID(actor models.Actor, claimID primitive.ObjectID) (*models.Claim, error) {
+    claim, err := s.claimRepo.FindByID(actor.TenantID, claimID)
+    if err != nil {
+        return nil, notFound(err, ErrClaimNotFound)
+    }
+    if err := authorizeClaim(actor, models.PermClaimRead, claim); err != nil {
+        return nil, err
+    }
+    return visibleClaim(actor, claim), nil
+}
+
+func (s *claimService) GetClaimByReference(actor models.Actor, reference string) (*models.Claim, error) {
+    claim, err := s.claimRepo.FindByReference(actor.TenantID, strings.ToUpper(reference))
+    if err != nil {
+        return nil, notFound(err, ErrClaimNotFound)
+    }
+    if err := authorizeClaim(actor, models.PermClaimRead, claim); err != nil {
+        return nil, err
     }
-    return s.claimRepo.Update(claim)
+    return visibleClaim(actor, claim), nil
 }
 
-func (s *claimService) DeleteClaim(userID primitive.ObjectID, claimID primitive.ObjectID) error {
-    claim, err := s.claimRepo.FindByID(claimID)
+func (s *claimService) DeleteClaim(actor models.Actor, claimID primitive.ObjectID) error {
+    claim, err := s.claimRepo.FindByID(actor.TenantID, claimID)
     if err != nil {
+        return notFound(err, ErrClaimNotFound)
+    }
+    if err := authorizeClaim(actor, models.PermClaimDeleteOwn, claim); err != nil {
         return err
     }
-    if claim.UserID != userID || claim.Status != models.Draft {
-        return errors.New("cannot delete claim")
+    if claim.Status != models.Draft {
+        return ErrInvalidTransition.WithMessage("only draft claims can be deleted")
     }
-    return s.claimRepo.Delete(claimID)
+    return s.claimRepo.Delete(actor.TenantID, claimID)
 }
 
-func (s *claimService) SubmitClaim(userID primitive.ObjectID, claimID primitive.ObjectID) error {
+func (s *claimService) SubmitClaim(actor models.Actor, claimID primitive.ObjectID, version int64, justification string) error {
     // claim, err := s.claimRepo.FindByID(claimID)
     // if err != nil || claim.UserID != userID || claim.Status != models.Draft {
     //     return errors.New("invalid operation")
@@ -135,31 +248,178 @@ func (s *claimService) SubmitClaim(userID primitive.ObjectID, claimID primitive.
     // s.claimRepo.AddHistory(claimID, history)
     // return s.claimRepo.Update(claim)
 
-    claim, err := s.claimRepo.FindByID(claimID)
-    if err != nil || claim.Status != models.Draft {
-        return errors.New("invalid operation")
+    claim, err := s.claimRepo.FindByID(actor.TenantID, claimID)
+    if err != nil {
+        return notFound(err, ErrClaimNotFound)
+    }
+    if err := authorizeClaim(actor, models.PermClaimSubmitOwn, claim); err != nil {
+        return err
+    }
+    if err := checkVersion(claim, version); err != nil {
+        return err
+    }
+    if claim.Status != models.Draft {
+        return ErrInvalidTransition
+    }
+
+    settings, err := s.tenants.Settings(actor.TenantID)
+    if err != nil {
+        return err
+    }
+    hardBlock := config.AppConfig.DuplicateHardBlock
+    if settings.DuplicateHardBlock != nil {
+        hardBlock = *settings.DuplicateHardBlock
+    }
+    duplicates, err := s.duplicates.FindDuplicates(actor.TenantID, claim)
+    if err != nil {
+        log.Println("duplicate check failed:", err)
+    }
+    if len(duplicates) > 0 && hardBlock && justification == "" {
+        return &DuplicateClaimError{Matches: duplicates}
     }
 
     now := time.Now()
-    history := models.ClaimHistory{
+    history := []models.ClaimHistory{{
         Status:    models.Submitted,
-        ChangedBy: userID,
+        ChangedBy: actor.UserID,
         ChangedAt: now,
+    }}
+    set := bson.M{
+        "status":     models.Submitted,
+        "updated_at": now,
+    }
+    if len(duplicates) > 0 {
+        set["duplicates"] = duplicates
+        dup := models.ClaimHistory{
+            Status:    models.Submitted,
+            ChangedBy: actor.UserID,
+            ChangedAt: now,
+            Event:     "duplicate_detected",
+            Note:      fmt.Sprintf("%d likely duplicates", len(duplicates)),
+        }
+        if justification != "" {
+            dup.Event, dup.Note = "duplicate_override", justification
+        }
+        history = append(history, dup)
+    }
+
+    // A broken rule set must not stop claims being filed; they just go to
+    // manual triage.
+    outcome, err := s.rules.Evaluate(actor.TenantID, claim)
+    if err != nil {
+        log.Println("rule evaluation failed:", err)
+        outcome = &models.RuleOutcome{Status: models.Submitted}
+    }
+
+    assessment := s.fraud.Assess(actor.TenantID, claim)
+    set["fraud_score"] = assessment.Score
+    set["fraud"] = assessment
+    suspicious := s.fraud.NeedsReview(actor.TenantID, assessment)
+    withholdAutoDecision(outcome, len(duplicates) > 0, suspicious)
+    history = append(history, applyRuleOutcome(outcome, set, now)...)
+    if suspicious {
+        set["fraud_flagged"] = true
+        history = append(history, models.ClaimHistory{
+            Status:    models.FraudReview,
+            ChangedBy: primitive.NilObjectID,
+            ChangedAt: now,
+            Event:     "fraud_scored",
+            Note:      fmt.Sprintf("fraud score %.0f", assessment.Score),
+        })
     }
 
     update := bson.M{
-        "$set": bson.M{
-            "status":     models.Submitted,
-            "updated_at": now,
-        },
-        "$push": bson.M{"history": history},
+        "$set":  set,
+        "$push": bson.M{"history": bson.M{"$each": history}},
+    }
+
+    ok, err := s.claimRepo.UpdateIf(actor.TenantID, claimID, versionCond(bson.M{"status": models.Draft}, version), update)
+    if err != nil {
+        return err
+    }
+    if !ok {
+        return conflictError(version)
+    }
+    for _, d := range duplicates {
+        back := models.DuplicateLink{ClaimID: claimID, Score: d.Score, Reasons: d.Reasons}
+        if err := s.claimRepo.UpdateWithPush(actor.TenantID, d.ClaimID, bson.M{"$push": bson.M{"duplicates": back}}); err != nil {
+            log.Println("cannot link duplicate claim:", err)
+        }
+    }
+    if outcome.AssignTo == nil {
+        s.queue.AutoAssign(actor.TenantID, claimID, outcome.Status)
+    }
+    if outcome.Status == models.Approved {
+        s.decided(actor.TenantID, claim, models.Approved, primitive.NilObjectID, "")
+    } else {
+        s.notify.ClaimEvent(actor.TenantID, claim, models.EventClaimSubmitted, models.Submitted, "")
+    }
+    return nil
+}
+
+// withholdAutoDecision drops the rules' auto-review or auto-approval for
+// claims a person has to look at first: likely duplicates stay submitted for
+// a verifier and suspicious claims go to fraud review.
+func withholdAutoDecision(outcome *models.RuleOutcome, duplicate, suspicious bool) {
+    if duplicate && outcome.Decision != "" {
+        outcome.Decision, outcome.DecisionRule = "", nil
+        outcome.Status = models.Submitted
+    }
+    if suspicious {
+        outcome.Decision, outcome.DecisionRule = "", nil
+        outcome.Status = models.FraudReview
     }
+}
+
+// applyRuleOutcome adds the effects of the triage rules to set and returns
+// the history entries recording which rule caused each of them.
+func applyRuleOutcome(outcome *models.RuleOutcome, set bson.M, now time.Time) []models.ClaimHistory {
+    var history []models.ClaimHistory
+    entry := func(status models.ClaimStatus, event, note string, rule *models.RuleMatch) {
+        history = append(history, models.ClaimHistory{
+            Status:      status,
+            ChangedBy:   primitive.NilObjectID,
+            ChangedAt:   now,
+            Event:       event,
+            Note:        note,
+            RuleID:      rule.RuleID,
+            RuleVersion: rule.Version,
+        })
+    }
+
+    switch outcome.Decision {
+    case models.RuleAutoReview:
+        entry(models.Reviewed, "", "auto-reviewed by rule "+outcome.DecisionRule.Name, outcome.DecisionRule)
+    case models.RuleAutoApprove:
+        entry(models.Reviewed, "", "auto-reviewed by rule "+outcome.DecisionRule.Name, outcome.DecisionRule)
+        entry(models.Approved, "", "auto-approved by rule "+outcome.DecisionRule.Name, outcome.DecisionRule)
+    }
+    set["status"] = outcome.Status
+
+    if outcome.FlagFraud {
+        set["fraud_flagged"] = true
+        entry(outcome.Status, "fraud_flagged", "flagged for fraud review", outcome.FraudRule)
+    }
+    if outcome.Priority != "" {
+        set["priority"] = outcome.Priority
+        entry(outcome.Status, "priority_set", outcome.Priority, outcome.PriorityRule)
+    }
+    if outcome.AssignTo != nil {
+        if _, ok := stagePermissions[outcome.Status]; ok {
+            set["assignment"] = &models.ClaimAssignment{AssigneeID: *outcome.AssignTo, AssignedBy: primitive.NilObjectID, AssignedAt: now}
+            entry(outcome.Status, "rule_assigned", outcome.AssignTo.Hex(), outcome.AssignRule)
+        } else {
+            outcome.AssignTo = nil
+        }
+    }
+    return history
+}
 
-    return s.claimRepo.UpdateWithPush(claimID, update)
+func (s *claimService) ReviewClaim(actor models.Actor, claimID primitive.ObjectID, version int64, note string) error {
+    return s.review(actor, claimID, version, note, "")
 }
 
-// Verifier only
-func (s *claimService) ReviewClaim(verifierID primitive.ObjectID, claimID primitive.ObjectID, note string) error {
+func (s *claimService) review(actor models.Actor, claimID primitive.ObjectID, version int64, note, correlationID string) error {
     // claim, err := s.claimRepo.FindByID(claimID)
     // if err != nil || claim.Status != models.Submitted {
     //     return errors.New("invalid operation")
@@ -174,17 +434,30 @@ func (s *claimService) ReviewClaim(verifierID primitive.ObjectID, claimID primit
     // s.claimRepo.AddHistory(claimID, history)
     // return s.claimRepo.Update(claim)
 
-    claim, err := s.claimRepo.FindByID(claimID)
-    if err != nil || claim.Status != models.Submitted {
-        return errors.New("invalid operation")
+    claim, err := s.claimRepo.FindByID(actor.TenantID, claimID)
+    if err != nil {
+        return notFound(err, ErrClaimNotFound)
+    }
+    if err := authorizeClaim(actor, models.PermClaimReview, claim); err != nil {
+        return err
+    }
+    if err := checkVersion(claim, version); err != nil {
+        return err
+    }
+    if claim.Status != models.Submitted {
+        return ErrInvalidTransition
+    }
+    if err := requireAssignee(claim, actor, nil); err != nil {
+        return err
     }
 
     now := time.Now()
     history := models.ClaimHistory{
-        Status:    models.Reviewed,
-        ChangedBy: verifierID,
-        ChangedAt: now,
-        Note:      note,
+        Status:        models.Reviewed,
+        ChangedBy:     actor.UserID,
+        ChangedAt:     now,
+        Note:          note,
+        CorrelationID: correlationID,
     }
 
     update := bson.M{
@@ -192,14 +465,23 @@ func (s *claimService) ReviewClaim(verifierID primitive.ObjectID, claimID primit
             "status":     models.Reviewed,
             "updated_at": now,
         },
-        "$push": bson.M{"history": history},
+        "$push":  bson.M{"history": history},
+        "$unset": bson.M{"assignment": ""},
+    }
+
+    if err := s.decide(actor.TenantID, claim, version, update); err != nil {
+        return err
     }
+    s.queue.AutoAssign(actor.TenantID, claimID, models.Reviewed)
+    s.notify.ClaimEvent(actor.TenantID, claim, models.EventClaimReviewed, models.Reviewed, "")
+    return nil
+}
 
-    return s.claimRepo.UpdateWithPush(claimID, update)
+func (s *claimService) ApproveClaim(actor models.Actor, claimID primitive.ObjectID, version int64) error {
+    return s.approve(actor, claimID, version, "")
 }
 
-// Approver only
-func (s *claimService) ApproveClaim(approverID primitive.ObjectID, claimID primitive.ObjectID) error {
+func (s *claimService) approve(actor models.Actor, claimID primitive.ObjectID, version int64, correlationID string) error {
     // claim, err := s.claimRepo.FindByID(claimID)
     // if err != nil || claim.Status != models.Reviewed {
     //     return errors.New("invalid operation")
@@ -213,9 +495,28 @@ func (s *claimService) ApproveClaim(approverID primitive.ObjectID, claimID primi
     // s.claimRepo.AddHistory(claimID, history)
     // return s.claimRepo.Update(claim)
 
-    claim, err := s.claimRepo.FindByID(claimID)
-    if err != nil || claim.Status != models.Reviewed {
-        return errors.New("invalid operation")
+    claim, err := s.claimRepo.FindByID(actor.TenantID, claimID)
+    if err != nil {
+        return notFound(err, ErrClaimNotFound)
+    }
+    onBehalfOf, err := authorizeClaimAs(actor, models.PermClaimApprove, claim)
+    if err != nil {
+        return err
+    }
+    if err := checkVersion(claim, version); err != nil {
+        return err
+    }
+    if claim.Status != models.Reviewed && claim.Status != models.Appealed {
+        return ErrInvalidTransition
+    }
+    if err := requireAssignee(claim, actor, onBehalfOf); err != nil {
+        return err
+    }
+    if err := requireFreshApprover(claim, actor, onBehalfOf); err != nil {
+        return err
+    }
+    if err := requireIndependent(claim, actor, onBehalfOf); err != nil {
+        return err
     }
 
     now := time.Now()
@@ -226,17 +527,29 @@ func (s *claimService) ApproveClaim(approverID primitive.ObjectID, claimID primi
         },
         "$push": bson.M{
             "history": models.ClaimHistory{
-                Status:    models.Approved,
-                ChangedBy: approverID,
-                ChangedAt: now,
+                Status:        models.Approved,
+                ChangedBy:     actor.UserID,
+                ChangedAt:     now,
+                OnBehalfOf:    onBehalfOf,
+                Summary:       s.onBehalfSummary(actor, onBehalfOf),
+                CorrelationID: correlationID,
             },
         },
+        "$unset": bson.M{"assignment": ""},
+    }
+
+    if err := s.decide(actor.TenantID, claim, version, update); err != nil {
+        return err
     }
+    s.decided(actor.TenantID, claim, models.Approved, actor.UserID, "")
+    return nil
+}
 
-    return s.claimRepo.UpdateWithPush(claimID, update)
+func (s *claimService) RejectClaim(actor models.Actor, claimID primitive.ObjectID, version int64, reason string) error {
+    return s.reject(actor, claimID, version, reason, "")
 }
 
-func (s *claimService) RejectClaim(approverID primitive.ObjectID, claimID primitive.ObjectID, reason string) error {
+func (s *claimService) reject(actor models.Actor, claimID primitive.ObjectID, version int64, reason, correlationID string) error {
     // claim, err := s.claimRepo.FindByID(claimID)
     // if err != nil || claim.Status != models.Reviewed {
     //     return errors.New("invalid operation")
@@ -251,9 +564,28 @@ func (s *claimService) RejectClaim(approverID primitive.ObjectID, claimID primit
     // s.claimRepo.AddHistory(claimID, history)
     // return s.claimRepo.Update(claim)
 
-    claim, err := s.claimRepo.FindByID(claimID)
-    if err != nil || claim.Status != models.Reviewed {
-        return errors.New("invalid operation")
+    claim, err := s.claimRepo.FindByID(actor.TenantID, claimID)
+    if err != nil {
+        return notFound(err, ErrClaimNotFound)
+    }
+    onBehalfOf, err := authorizeClaimAs(actor, models.PermClaimReject, claim)
+    if err != nil {
+        return err
+    }
+    if err := checkVersion(claim, version); err != nil {
+        return err
+    }
+    if claim.Status != models.Reviewed && claim.Status != models.Appealed {
+        return ErrInvalidTransition
+    }
+    if err := requireAssignee(claim, actor, onBehalfOf); err != nil {
+        return err
+    }
+    if err := requireFreshApprover(claim, actor, onBehalfOf); err != nil {
+        return err
+    }
+    if err := requireIndependent(claim, actor, onBehalfOf); err != nil {
+        return err
     }
 
     now := time.Now()
@@ -264,13 +596,441 @@ func (s *claimService) RejectClaim(approverID primitive.ObjectID, claimID primit
         },
         "$push": bson.M{
             "history": models.ClaimHistory{
-                Status:    models.Rejected,
-                ChangedBy: approverID,
+                Status:        models.Rejected,
+                ChangedBy:     actor.UserID,
+                ChangedAt:     now,
+                Note:          reason,
+                OnBehalfOf:    onBehalfOf,
+                Summary:       s.onBehalfSummary(actor, onBehalfOf),
+                CorrelationID: correlationID,
+            },
+        },
+        "$unset": bson.M{"assignment": ""},
+    }
+
+    if err := s.decide(actor.TenantID, claim, version, update); err != nil {
+        return err
+    }
+    s.decided(actor.TenantID, claim, models.Rejected, actor.UserID, reason)
+    return nil
+}
+
+// RequestInfo pauses SLA clocks while the insurer waits on the claimant.
+func (s *claimService) RequestInfo(actor models.Actor, claimID primitive.ObjectID, version int64, note string) error {
+    claim, err := s.claimRepo.FindByID(actor.TenantID, claimID)
+    if err != nil {
+        return notFound(err, ErrClaimNotFound)
+    }
+    perm, ok := stagePermissions[claim.Status]
+    if !ok {
+        return ErrInvalidTransition
+    }
+    if err := authorizeClaim(actor, perm, claim); err != nil {
+        return err
+    }
+    if err := checkVersion(claim, version); err != nil {
+        return err
+    }
+    if n := len(claim.SLAPauses); n > 0 && claim.SLAPauses[n-1].EndedAt == nil {
+        return ErrInvalidTransition.WithMessage("information already requested")
+    }
+
+    now := time.Now()
+    update := bson.M{
+        "$set": bson.M{"updated_at": now},
+        "$push": bson.M{
+            "sla_pauses": models.SLAPause{StartedAt: now, Reason: note},
+            "history": models.ClaimHistory{
+                Status:    claim.Status,
+                ChangedBy: actor.UserID,
                 ChangedAt: now,
+                Event:     "info_requested",
+                Note:      note,
+            },
+        },
+    }
+    ok, err = s.claimRepo.UpdateIf(actor.TenantID, claimID, versionCond(bson.M{"status": claim.Status}, version), update)
+    if err != nil {
+        return err
+    }
+    if !ok {
+        return conflictError(version)
+    }
+    s.notify.ClaimEvent(actor.TenantID, claim, models.EventClaimInfoRequested, claim.Status, note)
+    return nil
+}
+
+// ProvideInfo is the claimant's answer to RequestInfo and resumes SLA clocks.
+func (s *claimService) ProvideInfo(actor models.Actor, claimID primitive.ObjectID, version int64, note string) error {
+    claim, err := s.claimRepo.FindByID(actor.TenantID, claimID)
+    if err != nil {
+        return notFound(err, ErrClaimNotFound)
+    }
+    if err := authorizeClaim(actor, models.PermClaimUpdateOwn, claim); err != nil {
+        return err
+    }
+    if err := checkVersion(claim, version); err != nil {
+        return err
+    }
+    n := len(claim.SLAPauses)
+    if n == 0 || claim.SLAPauses[n-1].EndedAt != nil {
+        return ErrInvalidTransition.WithMessage("no information was requested")
+    }
+
+    now := time.Now()
+    update := bson.M{
+        "$set": bson.M{
+            "updated_at": now,
+            fmt.Sprintf("sla_pauses.%d.ended_at", n-1): now,
+        },
+        "$push": bson.M{"history": models.ClaimHistory{
+            Status:    claim.Status,
+            ChangedBy: actor.UserID,
+            ChangedAt: now,
+            Event:     "info_provided",
+            Note:      note,
+        }},
+    }
+    ok, err := s.claimRepo.UpdateIf(actor.TenantID, claimID, versionCond(bson.M{"sla_pauses": bson.M{"$size": n}}, version), update)
+    if err != nil {
+        return err
+    }
+    if !ok {
+        return conflictError(version)
+    }
+    return nil
+}
+
+// ResolveFraudReview either clears a suspicious claim back into the normal
+// verification queue or confirms the suspicion and rejects it.
+func (s *claimService) ResolveFraudReview(actor models.Actor, claimID primitive.ObjectID, version int64, cleared bool, note string) error {
+    claim, err := s.claimRepo.FindByID(actor.TenantID, claimID)
+    if err != nil {
+        return notFound(err, ErrClaimNotFound)
+    }
+    if err := authorizeClaim(actor, models.PermClaimFraudReview, claim); err != nil {
+        return err
+    }
+    if err := checkVersion(claim, version); err != nil {
+        return err
+    }
+    if claim.Status != models.FraudReview {
+        return ErrInvalidTransition
+    }
+
+    now := time.Now()
+    status, event := models.Rejected, "fraud_confirmed"
+    if cleared {
+        status, event = models.Submitted, "fraud_cleared"
+    }
+    update := bson.M{
+        "$set": bson.M{
+            "status":        status,
+            "fraud_flagged": !cleared,
+            "updated_at":    now,
+        },
+        "$push": bson.M{
+            "history": models.ClaimHistory{
+                Status:    status,
+                ChangedBy: actor.UserID,
+                ChangedAt: now,
+                Event:     event,
+                Note:      note,
+            },
+        },
+    }
+    ok, err := s.claimRepo.UpdateIf(actor.TenantID, claimID, versionCond(bson.M{"status": models.FraudReview}, version), update)
+    if err != nil {
+        return err
+    }
+    if !ok {
+        return conflictError(version)
+    }
+    if cleared {
+        s.queue.AutoAssign(actor.TenantID, claimID, models.Submitted)
+    } else {
+        // The fraud note is internal; the claimant only learns of the rejection.
+        s.decided(actor.TenantID, claim, models.Rejected, actor.UserID, "")
+    }
+    return nil
+}
+
+// WithdrawClaim lets the claimant take back a claim that is still waiting for
+// a decision.
+func (s *claimService) WithdrawClaim(actor models.Actor, claimID primitive.ObjectID, version int64, reason string) error {
+    claim, err := s.claimRepo.FindByID(actor.TenantID, claimID)
+    if err != nil {
+        return notFound(err, ErrClaimNotFound)
+    }
+    if err := authorizeClaim(actor, models.PermClaimWithdrawOwn, claim); err != nil {
+        return err
+    }
+    if err := checkVersion(claim, version); err != nil {
+        return err
+    }
+    if claim.Status != models.Submitted && claim.Status != models.Reviewed {
+        return ErrInvalidTransition.WithMessage("only submitted or reviewed claims can be withdrawn")
+    }
+
+    now := time.Now()
+    update := bson.M{
+        "$set": bson.M{
+            "status":     models.Withdrawn,
+            "updated_at": now,
+        },
+        "$push": bson.M{
+            "history": models.ClaimHistory{
+                Status:    models.Withdrawn,
+                ChangedBy: actor.UserID,
+                ChangedAt: now,
+                Note:      reason,
+            },
+        },
+        "$unset": bson.M{"assignment": ""},
+    }
+    ok, err := s.claimRepo.UpdateIf(actor.TenantID, claimID, versionCond(bson.M{"status": claim.Status}, version), update)
+    if err != nil {
+        return err
+    }
+    if !ok {
+        return conflictError(version)
+    }
+    return nil
+}
+
+// AppealClaim reopens a rejected claim once, within APPEAL_WINDOW of the
+// rejection, for a different approver to decide with the new evidence.
+func (s *claimService) AppealClaim(actor models.Actor, claimID primitive.ObjectID, version int64, req models.AppealClaimRequest) error {
+    claim, err := s.claimRepo.FindByID(actor.TenantID, claimID)
+    if err != nil {
+        return notFound(err, ErrClaimNotFound)
+    }
+    if err := authorizeClaim(actor, models.PermClaimAppealOwn, claim); err != nil {
+        return err
+    }
+    if err := checkVersion(claim, version); err != nil {
+        return err
+    }
+    if claim.Status != models.Rejected {
+        return ErrInvalidTransition.WithMessage("only rejected claims can be appealed")
+    }
+    if claim.Appeal != nil {
+        return ErrInvalidTransition.WithMessage("claim has already been appealed")
+    }
+    rejection := lastHistory(claim, models.Rejected)
+    if rejection == nil {
+        return ErrInvalidTransition
+    }
+    settings, err := s.tenants.Settings(actor.TenantID)
+    if err != nil {
+        return err
+    }
+    window := config.AppConfig.AppealWindow
+    if settings.AppealWindowDays > 0 {
+        window = time.Duration(settings.AppealWindowDays) * 24 * time.Hour
+    }
+    now := time.Now()
+    if now.Sub(rejection.ChangedAt) > window {
+        return ErrInvalidTransition.WithMessage("appeal window has closed")
+    }
+    approver := rejection.ChangedBy
+    if rejection.OnBehalfOf != nil {
+        approver = *rejection.OnBehalfOf
+    }
+
+    appeal := models.ClaimAppeal{
+        Reason:           req.Reason,
+        Documents:        req.Documents,
+        FiledAt:          now,
+        OriginalApprover: approver,
+    }
+    update := bson.M{
+        "$set": bson.M{
+            "status":     models.Appealed,
+            "appeal":     appeal,
+            "updated_at": now,
+        },
+        "$push": bson.M{
+            "documents": bson.M{"$each": req.Documents},
+            "history": models.ClaimHistory{
+                Status:    models.Appealed,
+                ChangedBy: actor.UserID,
+                ChangedAt: now,
+                Note:      req.Reason,
+            },
+        },
+    }
+    ok, err := s.claimRepo.UpdateIf(actor.TenantID, claimID, versionCond(bson.M{"status": models.Rejected, "appeal": bson.M{"$exists": false}}, version), update)
+    if err != nil {
+        return err
+    }
+    if !ok {
+        return conflictError(version)
+    }
+    s.queue.AutoAssign(actor.TenantID, claimID, models.Appealed, approver)
+    s.notify.ClaimEvent(actor.TenantID, claim, models.EventClaimAppealed, models.Appealed, "")
+    return nil
+}
+
+// ReopenClaim sends a decided claim back to the approver queue.
+func (s *claimService) ReopenClaim(actor models.Actor, claimID primitive.ObjectID, version int64, reason string) error {
+    if strings.TrimSpace(reason) == "" {
+        return utils.ValidationError(utils.FieldError{Field: "reason", Code: "required", Message: "is required"})
+    }
+    claim, err := s.claimRepo.FindByID(actor.TenantID, claimID)
+    if err != nil {
+        return notFound(err, ErrClaimNotFound)
+    }
+    if err := authorizeClaim(actor, models.PermClaimReopen, claim); err != nil {
+        return err
+    }
+    if err := checkVersion(claim, version); err != nil {
+        return err
+    }
+    if claim.Status != models.Approved && claim.Status != models.Rejected {
+        return ErrInvalidTransition.WithMessage("only approved or rejected claims can be reopened")
+    }
+
+    now := time.Now()
+    update := bson.M{
+        "$set": bson.M{
+            "status":     models.Reviewed,
+            "updated_at": now,
+        },
+        "$push": bson.M{
+            "history": models.ClaimHistory{
+                Status:    models.Reviewed,
+                ChangedBy: actor.UserID,
+                ChangedAt: now,
+                Event:     "reopened",
                 Note:      reason,
             },
         },
     }
+    ok, err := s.claimRepo.UpdateIf(actor.TenantID, claimID, versionCond(bson.M{"status": claim.Status}, version), update)
+    if err != nil {
+        return err
+    }
+    if !ok {
+        return conflictError(version)
+    }
+    s.queue.AutoAssign(actor.TenantID, claimID, models.Reviewed)
+    s.notify.ClaimEvent(actor.TenantID, claim, models.EventClaimReopened, models.Reviewed, "")
+    return nil
+}
+
+func lastHistory(claim *models.Claim, status models.ClaimStatus) *models.ClaimHistory {
+    for i := len(claim.History) - 1; i >= 0; i-- {
+        if claim.History[i].Status == status {
+            return &claim.History[i]
+        }
+    }
+    return nil
+}
+
+// requireFreshApprover stops the approver whose rejection is being appealed
+// from deciding the appeal, directly or through a delegate.
+func requireFreshApprover(claim *models.Claim, actor models.Actor, onBehalfOf *primitive.ObjectID) error {
+    ex := excludedWorker(claim)
+    if ex == nil {
+        return nil
+    }
+    if *ex == actor.UserID || (onBehalfOf != nil && *ex == *onBehalfOf) {
+        return ErrForbidden
+    }
+    return nil
+}
+
+// requireIndependent refuses a decision on a claim submitted by the actor or
+// by the user the actor is standing in for.
+func requireIndependent(claim *models.Claim, actor models.Actor, onBehalfOf *primitive.ObjectID) error {
+    if claim.UserID == actor.UserID || (onBehalfOf != nil && claim.UserID == *onBehalfOf) {
+        return ErrOwnClaim
+    }
+    return nil
+}
 
-    return s.claimRepo.UpdateWithPush(claimID, update)
+// requireAssignee allows a queue decision only from the claim's current
+// assignee, or from someone acting on the assignee's behalf.
+func requireAssignee(claim *models.Claim, actor models.Actor, onBehalfOf *primitive.ObjectID) error {
+    if !claim.Assignment.ActiveAt(time.Now()) {
+        return ErrCheckoutRequired
+    }
+    assignee := claim.Assignment.AssigneeID
+    if assignee == actor.UserID || (onBehalfOf != nil && assignee == *onBehalfOf) {
+        return nil
+    }
+    return ErrClaimLocked
+}
+
+// decided is the shared end of every approval or rejection, whether an
+// approver, a triage rule or a confirmed fraud review made it: the decision
+// letter (and payment advice) is generated and the claimant notified. note is
+// the reason the claimant may see.
+func (s *claimService) decided(tenantID string, claim *models.Claim, status models.ClaimStatus, decidedBy primitive.ObjectID, note string) {
+    s.documents.GenerateDecision(tenantID, claim.ID, decidedBy)
+    event := models.EventClaimApproved
+    if status == models.Rejected {
+        event = models.EventClaimRejected
+    }
+    s.notify.ClaimEvent(tenantID, claim, event, status, note)
+}
+
+// decide applies a queue decision only if the claim is still in the status
+// and with the assignee it was checked against.
+func (s *claimService) decide(tenantID string, claim *models.Claim, version int64, update bson.M) error {
+    cond := bson.M{"status": claim.Status, "assignment.assignee_id": claim.Assignment.AssigneeID}
+    ok, err := s.claimRepo.UpdateIf(tenantID, claim.ID, versionCond(cond, version), update)
+    if err != nil {
+        return err
+    }
+    if !ok {
+        return conflictError(version)
+    }
+    return nil
+}
+
+// checkVersion enforces an If-Match precondition against the loaded claim.
+func checkVersion(claim *models.Claim, version int64) error {
+    if version != models.AnyVersion && claim.Version != version {
+        return ErrVersionMismatch
+    }
+    return nil
+}
+
+// versionCond adds the If-Match version to the condition of an update, so a
+// change made after the claim was loaded still fails the precondition.
+func versionCond(cond bson.M, version int64) bson.M {
+    switch version {
+    case models.AnyVersion:
+    case 0:
+        cond["version"] = bson.M{"$in": bson.A{0, nil}}
+    default:
+        cond["version"] = version
+    }
+    return cond
+}
+
+// conflictError explains a conditional update that matched nothing. Every
+// write bumps the version, so with If-Match it is always a stale version.
+func conflictError(version int64) error {
+    if version != models.AnyVersion {
+        return ErrVersionMismatch
+    }
+    return ErrConcurrentUpdate
+}
+
+// onBehalfSummary renders "B on behalf of A" for history entries made under a
+// delegation, falling back to IDs if a user cannot be loaded.
+func (s *claimService) onBehalfSummary(actor models.Actor, delegatorID *primitive.ObjectID) string {
+    if delegatorID == nil {
+        return ""
+    }
+    name := func(id primitive.ObjectID) string {
+        if user, err := s.userRepo.FindByID(actor.TenantID, id); err == nil {
+            return user.Username
+        }
+        return id.Hex()
+    }
+    return name(actor.UserID) + " on behalf of " + name(*delegatorID)
 }
\ No newline at end of file
@@ -0,0 +1,87 @@
+package services
+
+import (
+    "insurance-claims-api/internal/models"
+    "insurance-claims-api/internal/repositories"
+    "testing"
+    "time"
+
+    "go.mongodb.org/mongo-driver/bson"
+    "go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestDuplicatesWithholdAutoDecision(t *testing.T) {
+    rule := &models.RuleMatch{Name: "small glass claims"}
+    tests := []struct {
+        name                  string
+        duplicate, suspicious bool
+        want                  models.ClaimStatus
+    }{
+        {"clean claim is auto-approved", false, false, models.Approved},
+        {"duplicate stays submitted", true, false, models.Submitted},
+        {"suspicious goes to fraud review", false, true, models.FraudReview},
+        {"suspicious duplicate goes to fraud review", true, true, models.FraudReview},
+    }
+    for _, tt := range tests {
+        t.Run(tt.name, func(t *testing.T) {
+            outcome := &models.RuleOutcome{Status: models.Approved, Decision: models.RuleAutoApprove, DecisionRule: rule}
+            withholdAutoDecision(outcome, tt.duplicate, tt.suspicious)
+            set := bson.M{}
+            history := applyRuleOutcome(outcome, set, time.Now())
+            if set["status"] != tt.want {
+                t.Errorf("status = %v, want %v", set["status"], tt.want)
+            }
+            if tt.want != models.Approved && len(history) > 0 {
+                t.Errorf("auto-decision still recorded: %+v", history)
+            }
+        })
+    }
+}
+
+type fakeDecisionClaims struct {
+    repositories.ClaimRepository
+    claim *models.Claim
+}
+
+func (f *fakeDecisionClaims) FindByID(tenantID string, id primitive.ObjectID) (*models.Claim, error) {
+    return f.claim, nil
+}
+
+func (f *fakeDecisionClaims) UpdateIf(tenantID string, id primitive.ObjectID, cond bson.M, update bson.M) (bool, error) {
+    return true, nil
+}
+
+type fakeDocuments struct {
+    DocumentService
+    generated []primitive.ObjectID
+}
+
+func (f *fakeDocuments) GenerateDecision(tenantID string, claimID, generatedBy primitive.ObjectID) {
+    f.generated = append(f.generated, claimID)
+}
+
+type fakeNotifications struct {
+    NotificationService
+    events []string
+}
+
+func (f *fakeNotifications) ClaimEvent(tenantID string, claim *models.Claim, event string, status models.ClaimStatus, note string) {
+    f.events = append(f.events, event)
+}
+
+func TestFraudConfirmationGeneratesDecisionLetter(t *testing.T) {
+    claim := &models.Claim{ID: primitive.NewObjectID(), UserID: primitive.NewObjectID(), Status: models.FraudReview}
+    docs, notify := &fakeDocuments{}, &fakeNotifications{}
+    svc := &claimService{claimRepo: &fakeDecisionClaims{claim: claim}, documents: docs, notify: notify}
+    reviewer := models.Actor{UserID: primitive.NewObjectID(), TenantID: "t1", Grants: []models.Grant{{Permission: models.PermClaimFraudReview}}}
+
+    if err := svc.ResolveFraudReview(reviewer, claim.ID, models.AnyVersion, false, "forged invoice"); err != nil {
+        t.Fatal(err)
+    }
+    if len(docs.generated) != 1 || docs.generated[0] != claim.ID {
+        t.Errorf("decision letters generated for %v", docs.generated)
+    }
+    if len(notify.events) != 1 || notify.events[0] != models.EventClaimRejected {
+        t.Errorf("notifications = %v", notify.events)
+    }
+}
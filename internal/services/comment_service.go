@@ -0,0 +1,187 @@
+package services
+
+import (
+    "fmt"
+    "insurance-claims-api/internal/models"
+    "insurance-claims-api/internal/repositories"
+    "insurance-claims-api/internal/utils"
+    "regexp"
+    "time"
+
+    "go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+var ErrCommentNotFound = utils.NewError(utils.KindNotFound, "comment_not_found", "comment not found")
+
+var mentionPattern = regexp.MustCompile(`(?:^|\s)@([A-Za-z0-9._-]+)`)
+
+type CommentService interface {
+    List(actor models.Actor, claimID primitive.ObjectID) ([]models.ClaimComment, error)
+    Create(actor models.Actor, claimID primitive.ObjectID, req models.CreateCommentRequest) (*models.ClaimComment, error)
+    Update(actor models.Actor, claimID, commentID primitive.ObjectID, req models.UpdateCommentRequest) (*models.ClaimComment, error)
+}
+
+type commentService struct {
+    commentRepo   repositories.CommentRepository
+    claimRepo     repositories.ClaimRepository
+    userRepo      repositories.UserRepository
+    policy        PolicyService
+    notifications NotificationService
+}
+
+func NewCommentService(commentRepo repositories.CommentRepository, claimRepo repositories.ClaimRepository, userRepo repositories.UserRepository, policy PolicyService, notifications NotificationService) CommentService {
+    return &commentService{commentRepo, claimRepo, userRepo, policy, notifications}
+}
+
+// isStaff reports whether actor sees the claim as staff rather than as its
+// claimant; only staff can read or write internal comments.
+func isStaff(actor models.Actor, claim *models.Claim) bool {
+    return authorizeClaim(actor, models.PermClaimReadAny, claim) == nil
+}
+
+func (s *commentService) claim(actor models.Actor, claimID primitive.ObjectID) (*models.Claim, error) {
+    claim, err := s.claimRepo.FindByID(actor.TenantID, claimID)
+    if err != nil {
+        return nil, notFound(err, ErrClaimNotFound)
+    }
+    if err := authorizeClaim(actor, models.PermClaimRead, claim); err != nil {
+        return nil, err
+    }
+    return claim, nil
+}
+
+func (s *commentService) List(actor models.Actor, claimID primitive.ObjectID) ([]models.ClaimComment, error) {
+    claim, err := s.claim(actor, claimID)
+    if err != nil {
+        return nil, err
+    }
+    return s.commentRepo.FindByClaim(actor.TenantID, claimID, isStaff(actor, claim))
+}
+
+func (s *commentService) Create(actor models.Actor, claimID primitive.ObjectID, req models.CreateCommentRequest) (*models.ClaimComment, error) {
+    claim, err := s.claim(actor, claimID)
+    if err != nil {
+        return nil, err
+    }
+    staff := isStaff(actor, claim)
+    if req.Internal && !staff {
+        return nil, ErrForbidden
+    }
+    if err := checkAttachments(claim, req.Attachments); err != nil {
+        return nil, err
+    }
+    if req.ParentID != nil {
+        parent, err := s.commentRepo.FindByID(actor.TenantID, *req.ParentID)
+        if err != nil {
+            return nil, notFound(err, ErrCommentNotFound)
+        }
+        if parent.ClaimID != claimID || (parent.Internal && !staff) {
+            return nil, ErrCommentNotFound
+        }
+        if parent.ParentID != nil {
+            req.ParentID = parent.ParentID
+        }
+        // Replies in an internal thread stay internal.
+        req.Internal = req.Internal || parent.Internal
+    }
+
+    comment := &models.ClaimComment{
+        ClaimID:     claimID,
+        ParentID:    req.ParentID,
+        AuthorID:    actor.UserID,
+        Body:        req.Body,
+        Internal:    req.Internal,
+        Attachments: req.Attachments,
+    }
+    comment.Mentions = s.resolveMentions(actor, claim, comment)
+    if err := s.commentRepo.Create(actor.TenantID, comment); err != nil {
+        return nil, err
+    }
+    s.notifyMentions(actor, claim, comment, comment.Mentions)
+    return comment, nil
+}
+
+func (s *commentService) Update(actor models.Actor, claimID, commentID primitive.ObjectID, req models.UpdateCommentRequest) (*models.ClaimComment, error) {
+    claim, err := s.claim(actor, claimID)
+    if err != nil {
+        return nil, err
+    }
+    comment, err := s.commentRepo.FindByID(actor.TenantID, commentID)
+    if err != nil {
+        return nil, notFound(err, ErrCommentNotFound)
+    }
+    if comment.ClaimID != claimID {
+        return nil, ErrCommentNotFound
+    }
+    if comment.AuthorID != actor.UserID {
+        return nil, ErrForbidden
+    }
+    if err := checkAttachments(claim, req.Attachments); err != nil {
+        return nil, err
+    }
+
+    edit := models.CommentEdit{Body: comment.Body, EditedAt: time.Now()}
+    before := comment.Mentions
+    comment.Body = req.Body
+    comment.Attachments = req.Attachments
+    comment.Mentions = s.resolveMentions(actor, claim, comment)
+    if err := s.commentRepo.Edit(actor.TenantID, comment, edit); err != nil {
+        return nil, err
+    }
+    comment.Edits = append(comment.Edits, edit)
+
+    var added []primitive.ObjectID
+    for _, id := range comment.Mentions {
+        if !containsID(before, id) {
+            added = append(added, id)
+        }
+    }
+    s.notifyMentions(actor, claim, comment, added)
+    return comment, nil
+}
+
+func checkAttachments(claim *models.Claim, attachments []string) error {
+    for _, a := range attachments {
+        if !containsString(claim.Documents, a) {
+            return utils.ValidationError(utils.FieldError{Field: "attachments", Code: "not_allowed", Message: fmt.Sprintf("%q is not a document of this claim", a)})
+        }
+    }
+    return nil
+}
+
+// resolveMentions finds the @username mentions in the comment body that
+// refer to users allowed to read the comment. Unknown names are ignored.
+func (s *commentService) resolveMentions(actor models.Actor, claim *models.Claim, comment *models.ClaimComment) []primitive.ObjectID {
+    var ids []primitive.ObjectID
+    for _, m := range mentionPattern.FindAllStringSubmatch(comment.Body, -1) {
+        user, err := s.userRepo.FindByUsername(actor.TenantID, m[1])
+        if err != nil || user.ID == actor.UserID || containsID(ids, user.ID) {
+            continue
+        }
+        reader, err := s.policy.Actor(actor.TenantID, user.ID, user.Role)
+        if err != nil || authorizeClaim(reader, models.PermClaimRead, claim) != nil {
+            continue
+        }
+        if comment.Internal && !isStaff(reader, claim) {
+            continue
+        }
+        ids = append(ids, user.ID)
+    }
+    return ids
+}
+
+func (s *commentService) notifyMentions(actor models.Actor, claim *models.Claim, comment *models.ClaimComment, userIDs []primitive.ObjectID) {
+    label := claim.Reference
+    if label == "" {
+        label = claim.ID.Hex()
+    }
+    for _, id := range userIDs {
+        s.notifications.Notify(actor.TenantID, models.Notification{
+            UserID:    id,
+            Type:      models.NotificationMention,
+            ClaimID:   &claim.ID,
+            CommentID: &comment.ID,
+            Message:   fmt.Sprintf("You were mentioned in a comment on claim %s", label),
+        })
+    }
+}
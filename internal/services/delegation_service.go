@@ -0,0 +1,86 @@
+package services
+
+import (
+    "insurance-claims-api/internal/models"
+    "insurance-claims-api/internal/repositories"
+    "insurance-claims-api/internal/utils"
+    "time"
+
+    "go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+var (
+    ErrDelegationNotFound = utils.NewError(utils.KindNotFound, "delegation_not_found", "delegation not found")
+    ErrDelegationRevoked  = utils.NewError(utils.KindConflict, "delegation_revoked", "delegation already revoked")
+    ErrDelegationOverlaps = utils.NewError(utils.KindConflict, "delegation_overlaps", "an active delegation already covers this period")
+)
+
+type DelegationService interface {
+    List(actor models.Actor) ([]models.Delegation, error)
+    Create(actor models.Actor, req models.CreateDelegationRequest) (*models.Delegation, error)
+    Revoke(actor models.Actor, id primitive.ObjectID) error
+}
+
+type delegationService struct {
+    delegationRepo repositories.DelegationRepository
+    userRepo       repositories.UserRepository
+}
+
+func NewDelegationService(delegationRepo repositories.DelegationRepository, userRepo repositories.UserRepository) DelegationService {
+    return &delegationService{delegationRepo, userRepo}
+}
+
+func (s *delegationService) List(actor models.Actor) ([]models.Delegation, error) {
+    return s.delegationRepo.FindByUser(actor.TenantID, actor.UserID)
+}
+
+// Create lends the delegator's own approve/reject grants to a stand-in, who
+// need not hold them. Decisions stay subject to requireIndependent, so a
+// delegate cannot decide a claim filed by themselves or by the delegator.
+func (s *delegationService) Create(actor models.Actor, req models.CreateDelegationRequest) (*models.Delegation, error) {
+    if !actor.OwnCan(models.PermClaimApprove) && !actor.OwnCan(models.PermClaimReject) {
+        return nil, ErrForbidden
+    }
+    if req.DelegateID == actor.UserID {
+        return nil, utils.ValidationError(utils.FieldError{Field: "delegate_id", Code: "not_allowed", Message: "cannot be yourself"})
+    }
+    if !req.EndsAt.After(time.Now()) {
+        return nil, utils.ValidationError(utils.FieldError{Field: "ends_at", Code: "too_small", Message: "must be in the future"})
+    }
+    if _, err := s.userRepo.FindByID(actor.TenantID, req.DelegateID); err != nil {
+        return nil, notFound(err, utils.ValidationError(utils.FieldError{Field: "delegate_id", Code: "not_found", Message: "does not exist"}))
+    }
+    overlapping, err := s.delegationRepo.FindOverlapping(actor.TenantID, actor.UserID, req.StartsAt, req.EndsAt)
+    if err != nil {
+        return nil, err
+    }
+    if len(overlapping) > 0 {
+        return nil, ErrDelegationOverlaps
+    }
+
+    delegation := &models.Delegation{
+        DelegatorID: actor.UserID,
+        DelegateID:  req.DelegateID,
+        StartsAt:    req.StartsAt,
+        EndsAt:      req.EndsAt,
+        Reason:      req.Reason,
+    }
+    if err := s.delegationRepo.Create(actor.TenantID, delegation); err != nil {
+        return nil, err
+    }
+    return delegation, nil
+}
+
+func (s *delegationService) Revoke(actor models.Actor, id primitive.ObjectID) error {
+    delegation, err := s.delegationRepo.FindByID(actor.TenantID, id)
+    if err != nil {
+        return notFound(err, ErrDelegationNotFound)
+    }
+    if delegation.DelegatorID != actor.UserID {
+        return ErrForbidden
+    }
+    if delegation.RevokedAt != nil {
+        return ErrDelegationRevoked
+    }
+    return s.delegationRepo.Revoke(actor.TenantID, id, time.Now())
+}
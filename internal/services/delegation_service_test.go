@@ -0,0 +1,73 @@
+package services
+
+import (
+    "errors"
+    "insurance-claims-api/internal/models"
+    "insurance-claims-api/internal/repositories"
+    "testing"
+    "time"
+
+    "go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// fakeDelegationRepo stores delegations in memory; methods the tests do not
+// reach panic through the embedded interface.
+type fakeDelegationRepo struct {
+    repositories.DelegationRepository
+    delegations []models.Delegation
+}
+
+func (r *fakeDelegationRepo) Create(tenantID string, d *models.Delegation) error {
+    d.ID = primitive.NewObjectID()
+    r.delegations = append(r.delegations, *d)
+    return nil
+}
+
+func (r *fakeDelegationRepo) FindOverlapping(tenantID string, delegatorID primitive.ObjectID, from, to time.Time) ([]models.Delegation, error) {
+    var out []models.Delegation
+    for _, d := range r.delegations {
+        if d.DelegatorID == delegatorID && d.StartsAt.Before(to) && d.EndsAt.After(from) {
+            out = append(out, d)
+        }
+    }
+    return out, nil
+}
+
+func TestCreateDelegationLendsDelegatorGrants(t *testing.T) {
+    approver := models.Actor{UserID: primitive.NewObjectID(), TenantID: "t1", Role: models.RoleApprover, Grants: DefaultRoleBindings[models.RoleApprover]}
+    standIn := &models.User{ID: primitive.NewObjectID(), Role: models.RoleVerifier}
+    svc := NewDelegationService(&fakeDelegationRepo{}, &fakeUserRepo{users: []*models.User{standIn}})
+    now := time.Now()
+    req := models.CreateDelegationRequest{DelegateID: standIn.ID, StartsAt: now, EndsAt: now.Add(48 * time.Hour)}
+
+    if _, err := svc.Create(approver, req); err != nil {
+        t.Fatalf("delegating to a user without approve grants: %v", err)
+    }
+    if _, err := svc.Create(approver, req); !errors.Is(err, ErrDelegationOverlaps) {
+        t.Errorf("overlapping delegation: err = %v", err)
+    }
+
+    verifier := models.Actor{UserID: standIn.ID, TenantID: "t1", Role: models.RoleVerifier, Grants: DefaultRoleBindings[models.RoleVerifier]}
+    if _, err := svc.Create(verifier, models.CreateDelegationRequest{DelegateID: approver.UserID, StartsAt: now, EndsAt: now.Add(time.Hour)}); !errors.Is(err, ErrForbidden) {
+        t.Errorf("delegator without approve grants: err = %v", err)
+    }
+    self := req
+    self.DelegateID = approver.UserID
+    if _, err := svc.Create(approver, self); err == nil {
+        t.Error("delegating to yourself was accepted")
+    }
+}
+
+func TestRequireIndependentCoversDelegator(t *testing.T) {
+    delegator, delegate := primitive.NewObjectID(), primitive.NewObjectID()
+    actor := models.Actor{UserID: delegate}
+    if err := requireIndependent(&models.Claim{UserID: delegate}, actor, &delegator); !errors.Is(err, ErrOwnClaim) {
+        t.Errorf("delegate's own claim: err = %v", err)
+    }
+    if err := requireIndependent(&models.Claim{UserID: delegator}, actor, &delegator); !errors.Is(err, ErrOwnClaim) {
+        t.Errorf("delegator's own claim: err = %v", err)
+    }
+    if err := requireIndependent(&models.Claim{UserID: primitive.NewObjectID()}, actor, &delegator); err != nil {
+        t.Errorf("independent claim: err = %v", err)
+    }
+}
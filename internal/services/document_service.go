@@ -0,0 +1,328 @@
+package services
+
+import (
+    "bytes"
+    "embed"
+    "fmt"
+    "insurance-claims-api/internal/config"
+    "insurance-claims-api/internal/models"
+    "insurance-claims-api/internal/repositories"
+    "insurance-claims-api/internal/utils"
+    "log"
+    "path"
+    "sort"
+    "strconv"
+    "strings"
+    "text/template"
+    "time"
+
+    "go.mongodb.org/mongo-driver/bson"
+    "go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+var ErrDocumentNotFound = utils.NewError(utils.KindNotFound, "document_not_found", "document not found")
+
+// Templates are named <kind>.v<version>.<language>.tmpl. Adding a revision
+// means adding a file with a higher version; older ones stay available so
+// earlier documents can be reproduced.
+//
+//go:embed templates/*.tmpl
+var documentTemplateFS embed.FS
+
+type documentTemplate struct {
+    models.DocumentTemplate
+    tmpl *template.Template
+}
+
+var documentTemplates = loadDocumentTemplates()
+
+var monthNames = map[string][]string{
+    "id": {"Januari", "Februari", "Maret", "April", "Mei", "Juni", "Juli", "Agustus", "September", "Oktober", "November", "Desember"},
+    "en": {"January", "February", "March", "April", "May", "June", "July", "August", "September", "October", "November", "December"},
+}
+
+var statusLabels = map[string]map[models.ClaimStatus]string{
+    "id": {
+        models.Draft: "Draf", models.Submitted: "Diajukan", models.Reviewed: "Diverifikasi",
+        models.Approved: "Disetujui", models.Rejected: "Ditolak", models.Expired: "Kedaluwarsa",
+        models.FraudReview: "Pemeriksaan fraud", models.Withdrawn: "Dibatalkan", models.Appealed: "Banding",
+    },
+    "en": {
+        models.Draft: "Draft", models.Submitted: "Submitted", models.Reviewed: "Reviewed",
+        models.Approved: "Approved", models.Rejected: "Rejected", models.Expired: "Expired",
+        models.FraudReview: "Fraud review", models.Withdrawn: "Withdrawn", models.Appealed: "Appealed",
+    },
+}
+
+func loadDocumentTemplates() map[string][]documentTemplate {
+    files, err := documentTemplateFS.ReadDir("templates")
+    if err != nil {
+        log.Fatal(err)
+    }
+    out := map[string][]documentTemplate{}
+    for _, f := range files {
+        parts := strings.Split(strings.TrimSuffix(f.Name(), ".tmpl"), ".")
+        if len(parts) != 3 {
+            log.Fatal("bad template name ", f.Name())
+        }
+        version, err := strconv.Atoi(strings.TrimPrefix(parts[1], "v"))
+        if err != nil {
+            log.Fatal("bad template version ", f.Name())
+        }
+        lang := parts[2]
+        tmpl, err := template.New(f.Name()).Funcs(documentFuncs(lang)).ParseFS(documentTemplateFS, path.Join("templates", f.Name()))
+        if err != nil {
+            log.Fatal(err)
+        }
+        key := parts[0] + "/" + lang
+        out[key] = append(out[key], documentTemplate{models.DocumentTemplate{Kind: parts[0], Language: lang, Version: version}, tmpl})
+    }
+    for _, versions := range out {
+        sort.Slice(versions, func(i, j int) bool { return versions[i].Version < versions[j].Version })
+    }
+    return out
+}
+
+func documentFuncs(lang string) template.FuncMap {
+    return template.FuncMap{
+        "money": func(amount float64) string {
+            if lang == "id" {
+                return "Rp " + formatAmount(amount, ".", ",")
+            }
+            return "IDR " + formatAmount(amount, ",", ".")
+        },
+        "date": func(v interface{}) string {
+            var t time.Time
+            switch d := v.(type) {
+            case time.Time:
+                t = d
+            case *time.Time:
+                if d == nil {
+                    return "-"
+                }
+                t = *d
+            }
+            if loc, err := time.LoadLocation(config.AppConfig.SLATimezone); err == nil {
+                t = t.In(loc)
+            }
+            month := monthNames[lang][t.Month()-1]
+            if lang == "id" {
+                return fmt.Sprintf("%d %s %d", t.Day(), month, t.Year())
+            }
+            return fmt.Sprintf("%s %d, %d", month, t.Day(), t.Year())
+        },
+        "status": func(s models.ClaimStatus) string {
+            if label, ok := statusLabels[lang][s]; ok {
+                return label
+            }
+            return string(s)
+        },
+    }
+}
+
+// formatAmount renders 1234567.5 as e.g. 1.234.567,50.
+func formatAmount(amount float64, thousands, decimal string) string {
+    s := strconv.FormatFloat(amount, 'f', 2, 64)
+    whole, frac := s[:len(s)-3], s[len(s)-2:]
+    sign := ""
+    if strings.HasPrefix(whole, "-") {
+        sign, whole = "-", whole[1:]
+    }
+    var b strings.Builder
+    for i, r := range whole {
+        if i > 0 && (len(whole)-i)%3 == 0 {
+            b.WriteString(thousands)
+        }
+        b.WriteRune(r)
+    }
+    return sign + b.String() + decimal + frac
+}
+
+type DocumentService interface {
+    Templates() []models.DocumentTemplate
+    Generate(actor models.Actor, claimID primitive.ObjectID, req models.GenerateDocumentRequest) (*models.ClaimDocument, error)
+    GenerateDecision(tenantID string, claimID, generatedBy primitive.ObjectID)
+    Download(actor models.Actor, claimID, documentID primitive.ObjectID) (*models.ClaimDocument, []byte, error)
+}
+
+type documentService struct {
+    claimRepo    repositories.ClaimRepository
+    documentRepo repositories.DocumentRepository
+    userRepo     repositories.UserRepository
+}
+
+func NewDocumentService(claimRepo repositories.ClaimRepository, documentRepo repositories.DocumentRepository, userRepo repositories.UserRepository) DocumentService {
+    return &documentService{claimRepo, documentRepo, userRepo}
+}
+
+func (s *documentService) Templates() []models.DocumentTemplate {
+    out := []models.DocumentTemplate{}
+    for _, versions := range documentTemplates {
+        for _, t := range versions {
+            out = append(out, t.DocumentTemplate)
+        }
+    }
+    sort.Slice(out, func(i, j int) bool {
+        a, b := out[i], out[j]
+        if a.Kind != b.Kind {
+            return a.Kind < b.Kind
+        }
+        if a.Language != b.Language {
+            return a.Language < b.Language
+        }
+        return a.Version < b.Version
+    })
+    return out
+}
+
+func (s *documentService) Generate(actor models.Actor, claimID primitive.ObjectID, req models.GenerateDocumentRequest) (*models.ClaimDocument, error) {
+    claim, err := s.claimRepo.FindByID(actor.TenantID, claimID)
+    if err != nil {
+        return nil, notFound(err, ErrClaimNotFound)
+    }
+    if err := authorizeClaim(actor, models.PermClaimRead, claim); err != nil {
+        return nil, err
+    }
+    if err := authorizeClaim(actor, models.PermClaimDocumentGenerate, claim); err != nil {
+        return nil, err
+    }
+    lang := req.Language
+    if lang == "" {
+        lang = config.AppConfig.DocumentLanguage
+    }
+    return s.generate(actor.TenantID, claim, req.Kind, lang, req.Version, actor.UserID)
+}
+
+// GenerateDecision renders the decision letter, plus the payment advice for
+// approvals, once a claim is decided. Failures are only logged so they never
+// undo the decision.
+func (s *documentService) GenerateDecision(tenantID string, claimID, generatedBy primitive.ObjectID) {
+    claim, err := s.claimRepo.FindByID(tenantID, claimID)
+    if err != nil {
+        log.Println("decision letter: cannot load claim", claimID.Hex(), err)
+        return
+    }
+    kinds := []string{models.DocDecisionLetter}
+    if claim.Status == models.Approved {
+        kinds = append(kinds, models.DocPaymentAdvice)
+    }
+    for _, kind := range kinds {
+        if _, err := s.generate(tenantID, claim, kind, config.AppConfig.DocumentLanguage, 0, generatedBy); err != nil {
+            log.Println("cannot generate", kind, "for claim", claimID.Hex(), err)
+        }
+    }
+}
+
+func (s *documentService) generate(tenantID string, claim *models.Claim, kind, lang string, version int, generatedBy primitive.ObjectID) (*models.ClaimDocument, error) {
+    versions := documentTemplates[kind+"/"+lang]
+    if len(versions) == 0 {
+        return nil, utils.ValidationError(utils.FieldError{Field: "language", Code: "not_allowed", Message: fmt.Sprintf("has no %s template", kind)})
+    }
+    tmpl := versions[len(versions)-1]
+    if version != 0 {
+        found := false
+        for _, t := range versions {
+            if t.Version == version {
+                tmpl, found = t, true
+            }
+        }
+        if !found {
+            return nil, utils.ValidationError(utils.FieldError{Field: "version", Code: "not_found", Message: fmt.Sprintf("%s template version %d does not exist", kind, version)})
+        }
+    }
+
+    data := struct {
+        Claim       *models.Claim
+        Claimant    string
+        Decision    *models.ClaimHistory
+        GeneratedAt time.Time
+    }{Claim: claim, Claimant: claim.UserID.Hex(), GeneratedAt: time.Now()}
+    if user, err := s.userRepo.FindByID(tenantID, claim.UserID); err == nil {
+        data.Claimant = user.Username
+    }
+    switch kind {
+    case models.DocDecisionLetter:
+        if claim.Status != models.Approved && claim.Status != models.Rejected {
+            return nil, ErrInvalidTransition.WithMessage("claim has not been decided")
+        }
+        data.Decision = lastHistory(claim, claim.Status)
+        if data.Decision != nil && data.Decision.Event == "fraud_confirmed" {
+            // Fraud findings are internal, so the letter gives no reason.
+            decision := *data.Decision
+            decision.Note = ""
+            data.Decision = &decision
+        }
+    case models.DocPaymentAdvice:
+        if claim.Status != models.Approved {
+            return nil, ErrInvalidTransition.WithMessage("payment advice requires an approved claim")
+        }
+        data.Decision = lastHistory(claim, claim.Status)
+    }
+    if data.Decision == nil {
+        data.Decision = &models.ClaimHistory{ChangedAt: claim.UpdatedAt}
+    }
+
+    var text bytes.Buffer
+    if err := tmpl.tmpl.Execute(&text, data); err != nil {
+        return nil, err
+    }
+    pdf := utils.NewPDFWriter()
+    for _, line := range strings.Split(text.String(), "\n") {
+        switch {
+        case strings.HasPrefix(line, "## "):
+            pdf.Space()
+            pdf.Heading(2, strings.TrimPrefix(line, "## "))
+        case strings.HasPrefix(line, "# "):
+            pdf.Heading(1, strings.TrimPrefix(line, "# "))
+        case strings.TrimSpace(line) == "":
+            pdf.Space()
+        default:
+            pdf.Paragraph(line)
+        }
+    }
+    var out bytes.Buffer
+    if _, err := pdf.WriteTo(&out); err != nil {
+        return nil, err
+    }
+
+    name := claim.Reference
+    if name == "" {
+        name = claim.ID.Hex()
+    }
+    doc := models.ClaimDocument{
+        ID:              primitive.NewObjectID(),
+        Kind:            kind,
+        Language:        lang,
+        TemplateVersion: tmpl.Version,
+        FileName:        fmt.Sprintf("%s-%s-%s.pdf", name, kind, lang),
+        GeneratedBy:     generatedBy,
+        GeneratedAt:     data.GeneratedAt,
+    }
+    if err := s.documentRepo.Upload(tenantID, doc.ID, doc.FileName, out.Bytes()); err != nil {
+        return nil, err
+    }
+    if err := s.claimRepo.UpdateWithPush(tenantID, claim.ID, bson.M{"$push": bson.M{"generated_documents": doc}}); err != nil {
+        return nil, err
+    }
+    return &doc, nil
+}
+
+func (s *documentService) Download(actor models.Actor, claimID, documentID primitive.ObjectID) (*models.ClaimDocument, []byte, error) {
+    claim, err := s.claimRepo.FindByID(actor.TenantID, claimID)
+    if err != nil {
+        return nil, nil, notFound(err, ErrDocumentNotFound)
+    }
+    if err := authorizeClaim(actor, models.PermClaimRead, claim); err != nil {
+        return nil, nil, err
+    }
+    for _, doc := range claim.Generated {
+        if doc.ID == documentID {
+            var buf bytes.Buffer
+            if err := s.documentRepo.Download(actor.TenantID, doc.ID, &buf); err != nil {
+                return nil, nil, err
+            }
+            return &doc, buf.Bytes(), nil
+        }
+    }
+    return nil, nil, ErrDocumentNotFound
+}
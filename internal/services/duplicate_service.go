@@ -0,0 +1,131 @@
+package services
+
+import (
+    "context"
+    "fmt"
+    "insurance-claims-api/internal/models"
+    "insurance-claims-api/internal/repositories"
+    "math"
+    "sort"
+    "strings"
+    "unicode"
+
+    "go.mongodb.org/mongo-driver/bson"
+)
+
+// duplicateThreshold is the score from which a claim is reported as a likely
+// duplicate.
+const duplicateThreshold = 0.6
+
+// DuplicateClaimError is returned by SubmitClaim when hard blocking is on and
+// the claim looks like one the claimant already filed.
+type DuplicateClaimError struct {
+    Matches []models.DuplicateLink
+}
+
+func (e *DuplicateClaimError) Error() string {
+    return "claim looks like a duplicate of an existing claim; resubmit with a justification to override"
+}
+
+type DuplicateService interface {
+    FindDuplicates(tenantID string, claim *models.Claim) ([]models.DuplicateLink, error)
+}
+
+type duplicateService struct {
+    claimRepo repositories.ClaimRepository
+}
+
+func NewDuplicateService(claimRepo repositories.ClaimRepository) DuplicateService {
+    return &duplicateService{claimRepo}
+}
+
+// FindDuplicates compares claim against the claimant's other filed claims and
+// returns the likely duplicates, best match first.
+func (s *duplicateService) FindDuplicates(tenantID string, claim *models.Claim) ([]models.DuplicateLink, error) {
+    filter := bson.M{
+        "user_id": claim.UserID,
+        "_id":     bson.M{"$ne": claim.ID},
+        "status":  bson.M{"$nin": []models.ClaimStatus{models.Draft, models.Expired}},
+    }
+    var matches []models.DuplicateLink
+    err := s.claimRepo.ForEach(context.TODO(), tenantID, filter, func(other *models.Claim) error {
+        if link := compareClaims(claim, other); link.Score >= duplicateThreshold {
+            matches = append(matches, link)
+        }
+        return nil
+    })
+    sort.Slice(matches, func(i, j int) bool { return matches[i].Score > matches[j].Score })
+    return matches, err
+}
+
+func compareClaims(a, b *models.Claim) models.DuplicateLink {
+    link := models.DuplicateLink{ClaimID: b.ID, Reasons: []string{}}
+    add := func(weight float64, reason string) {
+        link.Score += weight
+        link.Reasons = append(link.Reasons, reason)
+    }
+
+    if a.PolicyNumber != "" && strings.EqualFold(a.PolicyNumber, b.PolicyNumber) {
+        add(0.2, "same policy number")
+    }
+    if b.ClaimAmount > 0 && math.Abs(a.ClaimAmount-b.ClaimAmount) <= 0.01*b.ClaimAmount {
+        add(0.25, "same amount")
+    }
+    if a.IncidentDate != nil && b.IncidentDate != nil &&
+        a.IncidentDate.UTC().Format("2006-01-02") == b.IncidentDate.UTC().Format("2006-01-02") {
+        add(0.2, "same incident date")
+    }
+    if sim := textSimilarity(a.Description, b.Description); sim >= 0.5 {
+        add(0.25*sim, fmt.Sprintf("description %.0f%% similar", sim*100))
+    }
+    if shared := sharedDocumentRefs(a.Documents, b.Documents); shared > 0 {
+        add(0.4, fmt.Sprintf("%d shared document references", shared))
+    }
+    if link.Score > 1 {
+        link.Score = 1
+    }
+    link.Score = math.Round(link.Score*100) / 100
+    return link
+}
+
+// textSimilarity is the Jaccard similarity of the two texts' word sets.
+func textSimilarity(a, b string) float64 {
+    wa, wb := wordSet(a), wordSet(b)
+    if len(wa) == 0 || len(wb) == 0 {
+        return 0
+    }
+    common := 0
+    for w := range wa {
+        if wb[w] {
+            common++
+        }
+    }
+    return float64(common) / float64(len(wa)+len(wb)-common)
+}
+
+func wordSet(s string) map[string]bool {
+    words := map[string]bool{}
+    for _, w := range strings.FieldsFunc(strings.ToLower(s), func(r rune) bool {
+        return !unicode.IsLetter(r) && !unicode.IsNumber(r)
+    }) {
+        words[w] = true
+    }
+    return words
+}
+
+// sharedDocumentRefs counts the document references the claims have in
+// common. Only the reference strings are compared, not file contents, so the
+// same file uploaded twice under different references is not caught.
+func sharedDocumentRefs(a, b []string) int {
+    seen := map[string]bool{}
+    for _, d := range b {
+        seen[d] = true
+    }
+    n := 0
+    for _, d := range a {
+        if seen[d] {
+            n++
+        }
+    }
+    return n
+}
@@ -0,0 +1,20 @@
+package services
+
+import (
+    "errors"
+    "insurance-claims-api/internal/utils"
+
+    "go.mongodb.org/mongo-driver/mongo"
+)
+
+// notFound reports a missing document as sentinel. Other errors, such as
+// the database being unreachable, are returned unchanged so they are not
+// mistaken for a missing record.
+func notFound(err error, sentinel *utils.Error) error {
+    if errors.Is(err, mongo.ErrNoDocuments) {
+        out := *sentinel
+        out.Err = err
+        return &out
+    }
+    return err
+}
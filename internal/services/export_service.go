@@ -0,0 +1,346 @@
+package services
+
+import (
+    "encoding/csv"
+    "encoding/json"
+    "fmt"
+    "insurance-claims-api/internal/models"
+    "insurance-claims-api/internal/repositories"
+    "insurance-claims-api/internal/utils"
+    "io"
+    "log"
+    "strings"
+    "time"
+
+    "go.mongodb.org/mongo-driver/bson"
+    "go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+var (
+    ErrExportNotFound = utils.NewError(utils.KindNotFound, "export_not_found", "export not found")
+    ErrExportNotReady = utils.NewError(utils.KindConflict, "export_not_ready", "export is not ready")
+)
+
+type claimColumn func(c *models.Claim) interface{}
+
+func timeValue(t *time.Time) interface{} {
+    if t == nil || t.IsZero() {
+        return nil
+    }
+    return t.UTC().Format(time.RFC3339)
+}
+
+var exportColumns = map[string]claimColumn{
+    "id":            func(c *models.Claim) interface{} { return c.ID.Hex() },
+    "reference":     func(c *models.Claim) interface{} { return c.Reference },
+    "status":        func(c *models.Claim) interface{} { return string(c.Status) },
+    "user_id":       func(c *models.Claim) interface{} { return c.UserID.Hex() },
+    "policy_number": func(c *models.Claim) interface{} { return c.PolicyNumber },
+    "claim_type":    func(c *models.Claim) interface{} { return c.ClaimType },
+    "claim_amount":  func(c *models.Claim) interface{} { return c.ClaimAmount },
+    "description":   func(c *models.Claim) interface{} { return c.Description },
+    "documents":     func(c *models.Claim) interface{} { return strings.Join(c.Documents, ";") },
+    "priority":      func(c *models.Claim) interface{} { return c.Priority },
+    "fraud_score":   func(c *models.Claim) interface{} { return c.FraudScore },
+    "incident_date": func(c *models.Claim) interface{} { return timeValue(c.IncidentDate) },
+    "created_at":    func(c *models.Claim) interface{} { return timeValue(&c.CreatedAt) },
+    "updated_at":    func(c *models.Claim) interface{} { return timeValue(&c.UpdatedAt) },
+    "assignee_id": func(c *models.Claim) interface{} {
+        if c.Assignment == nil {
+            return nil
+        }
+        return c.Assignment.AssigneeID.Hex()
+    },
+}
+
+// staffExportColumns need claim:read:any; claimants cannot export them.
+var staffExportColumns = map[string]bool{"fraud_score": true}
+
+var defaultExportColumns = []string{"id", "reference", "status", "policy_number", "claim_type", "claim_amount", "created_at"}
+
+type historyColumn func(h *models.ClaimHistory) interface{}
+
+var historyColumns = []struct {
+    name string
+    fn   historyColumn
+}{
+    {"history_status", func(h *models.ClaimHistory) interface{} { return string(h.Status) }},
+    {"history_event", func(h *models.ClaimHistory) interface{} { return h.Event }},
+    {"history_changed_by", func(h *models.ClaimHistory) interface{} { return h.ChangedBy.Hex() }},
+    {"history_changed_at", func(h *models.ClaimHistory) interface{} { return timeValue(&h.ChangedAt) }},
+    {"history_note", func(h *models.ClaimHistory) interface{} { return h.Note }},
+    {"history_rule_id", func(h *models.ClaimHistory) interface{} { return h.RuleID }},
+}
+
+type ExportService interface {
+    Count(actor models.Actor, req models.ExportRequest) (int64, error)
+    Stream(actor models.Actor, req models.ExportRequest, w io.Writer) (int64, error)
+    StartJob(actor models.Actor, req models.ExportRequest) (*models.ExportJob, error)
+    GetJob(actor models.Actor, id primitive.ObjectID) (*models.ExportJob, error)
+    Download(actor models.Actor, id primitive.ObjectID, w io.Writer) error
+}
+
+type exportService struct {
+    claimRepo  repositories.ClaimRepository
+    exportRepo repositories.ExportRepository
+}
+
+func NewExportService(claimRepo repositories.ClaimRepository, exportRepo repositories.ExportRepository) ExportService {
+    return &exportService{claimRepo, exportRepo}
+}
+
+// ExportFormat returns the file extension and content type for req.Format.
+func ExportFormat(req models.ExportRequest) (format, contentType string) {
+    switch req.Format {
+    case "ndjson":
+        return "ndjson", "application/x-ndjson"
+    case "xlsx":
+        return "xlsx", "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+    }
+    return "csv", "text/csv"
+}
+
+// exportFilter applies the list filters to every claim the actor can read:
+// staff get their GET /claims/all scope, claimants their own claims.
+func exportFilter(actor models.Actor, req models.ExportRequest) (bson.M, bson.D) {
+    base, err := claimListFilter(actor)
+    if err != nil {
+        base = bson.M{"user_id": actor.UserID}
+    }
+    query := req.ClaimListQuery
+    if !actor.Can(models.PermClaimReadAny) {
+        // Filtering or sorting on fraud data would reveal it.
+        query.MinFraudScore, query.MaxFraudScore, query.Duplicates = nil, nil, nil
+        if internalHistoryEvents[query.Event] {
+            query.Event = ""
+        }
+        if strings.TrimPrefix(query.Sort, "-") == "fraud_score" {
+            query.Sort = ""
+        }
+    }
+    return claimQuery(base, query)
+}
+
+func (s *exportService) Count(actor models.Actor, req models.ExportRequest) (int64, error) {
+    filter, _ := exportFilter(actor, req)
+    return s.claimRepo.Count(actor.TenantID, filter)
+}
+
+func exportColumnNames(actor models.Actor, req models.ExportRequest) ([]string, error) {
+    if strings.TrimSpace(req.Columns) == "" {
+        return defaultExportColumns, nil
+    }
+    var cols []string
+    for _, c := range strings.Split(req.Columns, ",") {
+        c = strings.TrimSpace(c)
+        if _, ok := exportColumns[c]; !ok {
+            return nil, utils.ValidationError(utils.FieldError{Field: "columns", Code: "not_allowed", Message: fmt.Sprintf("unknown column %q", c)})
+        }
+        if staffExportColumns[c] && !actor.Can(models.PermClaimReadAny) {
+            return nil, utils.ValidationError(utils.FieldError{Field: "columns", Code: "not_allowed", Message: fmt.Sprintf("column %q is not available", c)})
+        }
+        cols = append(cols, c)
+    }
+    return cols, nil
+}
+
+// Stream writes the export to w as rows are read from the cursor and returns
+// the number of rows written.
+func (s *exportService) Stream(actor models.Actor, req models.ExportRequest, w io.Writer) (int64, error) {
+    cols, err := exportColumnNames(actor, req)
+    if err != nil {
+        return 0, err
+    }
+    header := append([]string(nil), cols...)
+    if req.History {
+        for _, h := range historyColumns {
+            header = append(header, h.name)
+        }
+    }
+    format, _ := ExportFormat(req)
+    out, err := newRowWriter(format, header, w)
+    if err != nil {
+        return 0, err
+    }
+
+    var rows int64
+    filter, sort := exportFilter(actor, req)
+    err = s.claimRepo.ForEachSorted(actor.TenantID, filter, sort, func(claim *models.Claim) error {
+        visibleClaim(actor, claim)
+        values := make([]interface{}, 0, len(header))
+        for _, c := range cols {
+            values = append(values, exportColumns[c](claim))
+        }
+        if !req.History || len(claim.History) == 0 {
+            rows++
+            return out.WriteRow(values)
+        }
+        for i := range claim.History {
+            row := values
+            for _, h := range historyColumns {
+                row = append(row, h.fn(&claim.History[i]))
+            }
+            rows++
+            if err := out.WriteRow(row); err != nil {
+                return err
+            }
+        }
+        return nil
+    })
+    if err != nil {
+        return rows, err
+    }
+    return rows, out.Close()
+}
+
+func (s *exportService) StartJob(actor models.Actor, req models.ExportRequest) (*models.ExportJob, error) {
+    if _, err := exportColumnNames(actor, req); err != nil {
+        return nil, err
+    }
+    format, _ := ExportFormat(req)
+    job := &models.ExportJob{
+        UserID:   actor.UserID,
+        Status:   models.ExportPending,
+        Request:  req,
+        FileName: fmt.Sprintf("claims-%s.%s", time.Now().Format("20060102-150405"), format),
+    }
+    if err := s.exportRepo.Create(actor.TenantID, job); err != nil {
+        return nil, err
+    }
+    go s.run(actor, *job)
+    return job, nil
+}
+
+func (s *exportService) run(actor models.Actor, job models.ExportJob) {
+    job.Status = models.ExportRunning
+    s.exportRepo.Update(actor.TenantID, &job)
+
+    err := func() error {
+        upload, err := s.exportRepo.OpenUpload(actor.TenantID, job.FileName)
+        if err != nil {
+            return err
+        }
+        job.Rows, err = s.Stream(actor, job.Request, upload)
+        if err != nil {
+            upload.Abort()
+            return err
+        }
+        if err := upload.Close(); err != nil {
+            return err
+        }
+        if id, ok := upload.FileID.(primitive.ObjectID); ok {
+            job.FileID = &id
+        }
+        return nil
+    }()
+
+    finished := time.Now()
+    job.FinishedAt = &finished
+    job.Status = models.ExportDone
+    if err != nil {
+        log.Println("export failed:", err)
+        job.Status = models.ExportFailed
+        job.Error = err.Error()
+    }
+    if err := s.exportRepo.Update(actor.TenantID, &job); err != nil {
+        log.Println("cannot save export job:", err)
+    }
+}
+
+func (s *exportService) GetJob(actor models.Actor, id primitive.ObjectID) (*models.ExportJob, error) {
+    job, err := s.exportRepo.FindByID(actor.TenantID, id)
+    if err != nil {
+        return nil, notFound(err, ErrExportNotFound)
+    }
+    if job.UserID != actor.UserID {
+        return nil, ErrExportNotFound
+    }
+    return job, nil
+}
+
+func (s *exportService) Download(actor models.Actor, id primitive.ObjectID, w io.Writer) error {
+    job, err := s.GetJob(actor, id)
+    if err != nil {
+        return err
+    }
+    if job.Status != models.ExportDone || job.FileID == nil {
+        return ErrExportNotReady
+    }
+    return s.exportRepo.Download(actor.TenantID, *job.FileID, w)
+}
+
+type rowWriter interface {
+    WriteRow(values []interface{}) error
+    Close() error
+}
+
+func newRowWriter(format string, header []string, w io.Writer) (rowWriter, error) {
+    var out rowWriter
+    switch format {
+    case "ndjson":
+        return &ndjsonWriter{w: w, header: header}, nil
+    case "xlsx":
+        x, err := utils.NewXLSXWriter(w)
+        if err != nil {
+            return nil, err
+        }
+        out = x
+    default:
+        out = &csvWriter{csv.NewWriter(w)}
+    }
+    row := make([]interface{}, len(header))
+    for i, h := range header {
+        row[i] = h
+    }
+    return out, out.WriteRow(row)
+}
+
+type csvWriter struct {
+    w *csv.Writer
+}
+
+func (c *csvWriter) WriteRow(values []interface{}) error {
+    record := make([]string, len(values))
+    for i, v := range values {
+        if v != nil {
+            record[i] = fmt.Sprint(v)
+        }
+    }
+    return c.w.Write(record)
+}
+
+func (c *csvWriter) Close() error {
+    c.w.Flush()
+    return c.w.Error()
+}
+
+// ndjsonWriter writes one JSON object per row, keeping the column order.
+type ndjsonWriter struct {
+    w      io.Writer
+    header []string
+}
+
+func (n *ndjsonWriter) WriteRow(values []interface{}) error {
+    var b strings.Builder
+    b.WriteByte('{')
+    for i, v := range values {
+        if i > 0 {
+            b.WriteByte(',')
+        }
+        key, _ := json.Marshal(n.header[i])
+        val, err := json.Marshal(v)
+        if err != nil {
+            return err
+        }
+        b.Write(key)
+        b.WriteByte(':')
+        b.Write(val)
+    }
+    b.WriteString("}\n")
+    _, err := io.WriteString(n.w, b.String())
+    return err
+}
+
+func (n *ndjsonWriter) Close() error {
+    return nil
+}
@@ -0,0 +1,163 @@
+package services
+
+import (
+    "fmt"
+    "insurance-claims-api/internal/models"
+    "insurance-claims-api/internal/repositories"
+    "log"
+    "time"
+
+    "go.mongodb.org/mongo-driver/bson"
+)
+
+const maxFraudScore = 100
+
+// FraudScorer is one check in the fraud pipeline. It returns nil when the
+// claim shows nothing suspicious.
+type FraudScorer interface {
+    Name() string
+    Score(tenantID string, claim *models.Claim) (*models.FraudSignal, error)
+}
+
+type FraudService interface {
+    Assess(tenantID string, claim *models.Claim) models.FraudAssessment
+    NeedsReview(tenantID string, a models.FraudAssessment) bool
+}
+
+type fraudService struct {
+    scorers   []FraudScorer
+    threshold float64
+    tenants   TenantService
+}
+
+func NewFraudService(tenants TenantService, threshold float64, scorers ...FraudScorer) FraudService {
+    return &fraudService{scorers, threshold, tenants}
+}
+
+// Assess runs every scorer. A failing scorer is logged and skipped so one
+// broken check cannot block submissions.
+func (s *fraudService) Assess(tenantID string, claim *models.Claim) models.FraudAssessment {
+    a := models.FraudAssessment{ScoredAt: time.Now()}
+    for _, scorer := range s.scorers {
+        signal, err := scorer.Score(tenantID, claim)
+        if err != nil {
+            log.Printf("fraud scorer %s failed: %v", scorer.Name(), err)
+            continue
+        }
+        if signal == nil {
+            continue
+        }
+        signal.Scorer = scorer.Name()
+        a.Signals = append(a.Signals, *signal)
+        a.Score += signal.Points
+    }
+    if a.Score > maxFraudScore {
+        a.Score = maxFraudScore
+    }
+    return a
+}
+
+// NeedsReview compares the score with the tenant's threshold, falling back
+// to FRAUD_REVIEW_THRESHOLD.
+func (s *fraudService) NeedsReview(tenantID string, a models.FraudAssessment) bool {
+    threshold := s.threshold
+    if settings, err := s.tenants.Settings(tenantID); err != nil {
+        log.Printf("tenant settings unavailable for %s: %v", tenantID, err)
+    } else if settings.FraudReviewThreshold > 0 {
+        threshold = settings.FraudReviewThreshold
+    }
+    return a.Score >= threshold
+}
+
+// PolicyVelocityScorer flags several claims on one policy within a short
+// window.
+type PolicyVelocityScorer struct {
+    ClaimRepo repositories.ClaimRepository
+    Window    time.Duration
+    MinOthers int
+}
+
+func (PolicyVelocityScorer) Name() string { return "policy_velocity" }
+
+func (v PolicyVelocityScorer) Score(tenantID string, claim *models.Claim) (*models.FraudSignal, error) {
+    others, err := v.ClaimRepo.Count(tenantID, bson.M{
+        "policy_number": claim.PolicyNumber,
+        "_id":           bson.M{"$ne": claim.ID},
+        "status":        bson.M{"$ne": models.Draft},
+        "created_at":    bson.M{"$gte": time.Now().Add(-v.Window)},
+    })
+    if err != nil || others < int64(v.MinOthers) {
+        return nil, err
+    }
+    return &models.FraudSignal{
+        Points: float64(min(others*20, 60)),
+        Reason: fmt.Sprintf("%d other claims on policy %s in the last %s", others, claim.PolicyNumber, v.Window),
+    }, nil
+}
+
+// NearThresholdScorer flags amounts just below an approval limit, e.g. 9.9m
+// against a 10m limit.
+type NearThresholdScorer struct {
+    Thresholds []float64
+    Margin     float64
+}
+
+func (NearThresholdScorer) Name() string { return "near_threshold" }
+
+func (n NearThresholdScorer) Score(tenantID string, claim *models.Claim) (*models.FraudSignal, error) {
+    for _, t := range n.Thresholds {
+        if claim.ClaimAmount < t && claim.ClaimAmount >= t*(1-n.Margin) {
+            return &models.FraudSignal{
+                Points: 30,
+                Reason: fmt.Sprintf("amount %.2f is just under the %.2f threshold", claim.ClaimAmount, t),
+            }, nil
+        }
+    }
+    return nil, nil
+}
+
+// RecycledDocumentScorer flags document references that were already
+// attached to another claim. File contents are not compared.
+type RecycledDocumentScorer struct {
+    ClaimRepo repositories.ClaimRepository
+}
+
+func (RecycledDocumentScorer) Name() string { return "recycled_documents" }
+
+func (r RecycledDocumentScorer) Score(tenantID string, claim *models.Claim) (*models.FraudSignal, error) {
+    if len(claim.Documents) == 0 {
+        return nil, nil
+    }
+    others, err := r.ClaimRepo.Count(tenantID, bson.M{
+        "_id":       bson.M{"$ne": claim.ID},
+        "documents": bson.M{"$in": claim.Documents},
+    })
+    if err != nil || others == 0 {
+        return nil, err
+    }
+    return &models.FraudSignal{
+        Points: 50,
+        Reason: fmt.Sprintf("document references already used on %d other claims", others),
+    }, nil
+}
+
+// PolicyInceptionScorer flags claims filed soon after the policy started.
+type PolicyInceptionScorer struct {
+    Window time.Duration
+}
+
+func (PolicyInceptionScorer) Name() string { return "policy_inception" }
+
+func (p PolicyInceptionScorer) Score(tenantID string, claim *models.Claim) (*models.FraudSignal, error) {
+    if claim.PolicyStart == nil {
+        return nil, nil
+    }
+    age := time.Since(*claim.PolicyStart)
+    if age < 0 || age >= p.Window {
+        return nil, nil
+    }
+    return &models.FraudSignal{
+        Points: 30,
+        Reason: fmt.Sprintf("filed %d days after policy inception", int(age.Hours()/24)),
+    }, nil
+}
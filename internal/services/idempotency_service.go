@@ -0,0 +1,101 @@
+package services
+
+import (
+    "errors"
+    "insurance-claims-api/internal/models"
+    "insurance-claims-api/internal/repositories"
+    "insurance-claims-api/internal/utils"
+    "log"
+    "time"
+
+    "go.mongodb.org/mongo-driver/mongo"
+)
+
+var (
+    ErrIdempotencyMismatch   = utils.NewError(utils.KindValidation, "idempotency_key_reused", "Idempotency-Key was already used for a different request")
+    ErrIdempotencyInProgress = utils.NewError(utils.KindConflict, "request_in_progress", "a request with this Idempotency-Key is still being processed")
+)
+
+type IdempotencyService interface {
+    Begin(actor models.Actor, key, requestHash string) (*models.IdempotencyRecord, error)
+    Complete(actor models.Actor, key string, status int, contentType string, headers map[string]string, body []byte)
+    Release(actor models.Actor, key string)
+}
+
+type idempotencyService struct {
+    idempotencyRepo repositories.IdempotencyRepository
+    ttl             time.Duration
+}
+
+func NewIdempotencyService(idempotencyRepo repositories.IdempotencyRepository, ttl time.Duration) IdempotencyService {
+    return &idempotencyService{idempotencyRepo, ttl}
+}
+
+func idempotencyID(actor models.Actor, key string) string {
+    return actor.TenantID + "/" + actor.UserID.Hex() + "/" + key
+}
+
+// Begin reserves key for a new request and returns nil, or returns the
+// completed record of an earlier identical request to be replayed.
+func (s *idempotencyService) Begin(actor models.Actor, key, requestHash string) (*models.IdempotencyRecord, error) {
+    id := idempotencyID(actor, key)
+    for attempt := 0; attempt < 2; attempt++ {
+        now := time.Now()
+        inserted, err := s.idempotencyRepo.Insert(actor.TenantID, &models.IdempotencyRecord{
+            ID:          id,
+            UserID:      actor.UserID.Hex(),
+            Key:         key,
+            RequestHash: requestHash,
+            CreatedAt:   now,
+            ExpiresAt:   now.Add(s.ttl),
+        })
+        if err != nil || inserted {
+            return nil, err
+        }
+
+        existing, err := s.idempotencyRepo.Find(actor.TenantID, id)
+        if errors.Is(err, mongo.ErrNoDocuments) {
+            continue
+        }
+        if err != nil {
+            return nil, err
+        }
+        // MongoDB's TTL monitor runs about once a minute, so an expired key
+        // may still be present.
+        if existing.ExpiresAt.Before(now) {
+            if err := s.idempotencyRepo.Delete(actor.TenantID, id); err != nil {
+                return nil, err
+            }
+            continue
+        }
+        if existing.RequestHash != requestHash {
+            return nil, ErrIdempotencyMismatch
+        }
+        if !existing.Completed {
+            return nil, ErrIdempotencyInProgress
+        }
+        return existing, nil
+    }
+    return nil, ErrIdempotencyInProgress
+}
+
+func (s *idempotencyService) Complete(actor models.Actor, key string, status int, contentType string, headers map[string]string, body []byte) {
+    rec := &models.IdempotencyRecord{
+        ID:          idempotencyID(actor, key),
+        StatusCode:  status,
+        ContentType: contentType,
+        Headers:     headers,
+        Body:        body,
+    }
+    if err := s.idempotencyRepo.Complete(actor.TenantID, rec); err != nil {
+        log.Println("cannot store idempotent response:", err)
+    }
+}
+
+// Release forgets a reserved key, e.g. after a server error, so the client
+// can retry with it.
+func (s *idempotencyService) Release(actor models.Actor, key string) {
+    if err := s.idempotencyRepo.Delete(actor.TenantID, idempotencyID(actor, key)); err != nil {
+        log.Println("cannot release idempotency key:", err)
+    }
+}
@@ -0,0 +1,361 @@
+package services
+
+import (
+    "bufio"
+    "encoding/csv"
+    "encoding/json"
+    "errors"
+    "fmt"
+    "insurance-claims-api/internal/models"
+    "insurance-claims-api/internal/repositories"
+    "insurance-claims-api/internal/utils"
+    "io"
+    "strconv"
+    "strings"
+    "time"
+
+    "github.com/gin-gonic/gin/binding"
+    "go.mongodb.org/mongo-driver/bson/primitive"
+    "go.mongodb.org/mongo-driver/mongo"
+)
+
+type ImportService interface {
+    Import(actor models.Actor, format string, r io.Reader, dryRun bool) (*models.ImportReport, error)
+}
+
+type importService struct {
+    claimRepo repositories.ClaimRepository
+    counters  repositories.CounterRepository
+    userRepo  repositories.UserRepository
+}
+
+func NewImportService(claimRepo repositories.ClaimRepository, counters repositories.CounterRepository, userRepo repositories.UserRepository) ImportService {
+    return &importService{claimRepo, counters, userRepo}
+}
+
+// importReader yields one record at a time. A *rowError only marks that row
+// as invalid; any other error aborts the import.
+type importReader interface {
+    Next() (*models.ImportRecord, error)
+}
+
+type rowError struct {
+    err error
+}
+
+func (e *rowError) Error() string {
+    return e.err.Error()
+}
+
+var (
+    errBlankRow      = errors.New("blank row")
+    errMalformedFile = utils.NewError(utils.KindBadRequest, "malformed_import", "malformed import file")
+    errMalformedRow  = utils.NewError(utils.KindValidation, "malformed_row", "malformed row")
+)
+
+// Import validates and inserts every record of r, continuing past bad rows.
+// Claims whose external reference already exists are skipped, so a failed
+// import can simply be re-run. With dryRun nothing is written. Errors that
+// are not about the row itself, such as the database being unreachable,
+// abort the import instead of marking every following row invalid.
+func (s *importService) Import(actor models.Actor, format string, r io.Reader, dryRun bool) (*models.ImportReport, error) {
+    var in importReader
+    switch format {
+    case "ndjson":
+        scanner := bufio.NewScanner(r)
+        scanner.Buffer(make([]byte, 64*1024), 4*1024*1024)
+        in = &ndjsonImportReader{scanner}
+    case "csv", "":
+        c, err := newCSVImportReader(r)
+        if err != nil {
+            return nil, err
+        }
+        in = c
+    default:
+        return nil, utils.NewError(utils.KindBadRequest, utils.CodeBadRequest, fmt.Sprintf("unsupported import format %q", format))
+    }
+
+    report := &models.ImportReport{DryRun: dryRun, Rows: []models.ImportRowResult{}}
+    seen := map[string]bool{}
+    users := map[string]primitive.ObjectID{}
+    for row := 1; ; {
+        rec, err := in.Next()
+        if err == io.EOF {
+            break
+        }
+        if err == errBlankRow {
+            continue
+        }
+        if re, ok := err.(*rowError); ok {
+            err = errMalformedRow.WithMessage(re.Error())
+        } else if err != nil {
+            return nil, errMalformedFile.WithMessage(err.Error())
+        }
+        result := models.ImportRowResult{Row: row}
+        if err == nil {
+            result.ExternalReference = rec.ExternalReference
+            err = s.importRecord(actor, rec, dryRun, seen, users, &result)
+        }
+        if err != nil {
+            e := utils.Classify(err)
+            if e == nil || e.Kind == utils.KindUnavailable {
+                return nil, err
+            }
+            result.Result = models.ImportInvalid
+            result.Code, result.Error = e.Code, e.Error()
+        }
+        switch result.Result {
+        case models.ImportCreated:
+            report.Created++
+        case models.ImportValid:
+            report.Valid++
+        case models.ImportSkipped:
+            report.Skipped++
+        default:
+            report.Invalid++
+        }
+        report.Total++
+        report.Rows = append(report.Rows, result)
+        row++
+    }
+    return report, nil
+}
+
+func (s *importService) importRecord(actor models.Actor, rec *models.ImportRecord, dryRun bool, seen map[string]bool, users map[string]primitive.ObjectID, result *models.ImportRowResult) error {
+    if err := binding.Validator.ValidateStruct(rec); err != nil {
+        return utils.BindingError(err)
+    }
+    if seen[rec.ExternalReference] {
+        return utils.ValidationError(utils.FieldError{Field: "external_reference", Code: "duplicate_in_file", Message: "appears more than once in this file"})
+    }
+    seen[rec.ExternalReference] = true
+
+    if skipped, err := s.skipExisting(actor.TenantID, rec.ExternalReference, result); skipped || err != nil {
+        return err
+    }
+
+    lookup := func(field, username string) (primitive.ObjectID, error) {
+        if username == "" {
+            return actor.UserID, nil
+        }
+        if id, ok := users[username]; ok {
+            return id, nil
+        }
+        user, err := s.userRepo.FindByUsername(actor.TenantID, username)
+        if errors.Is(err, mongo.ErrNoDocuments) {
+            return primitive.NilObjectID, utils.ValidationError(utils.FieldError{Field: field, Code: "unknown_user", Message: fmt.Sprintf("unknown user %q", username)})
+        }
+        if err != nil {
+            return primitive.NilObjectID, err
+        }
+        users[username] = user.ID
+        return user.ID, nil
+    }
+    owner, err := lookup("owner", rec.Owner)
+    if err != nil {
+        return err
+    }
+
+    now := time.Now()
+    createdAt := now
+    if rec.CreatedAt != nil {
+        createdAt = *rec.CreatedAt
+    }
+    claim := &models.Claim{
+        ID:           primitive.NewObjectID(),
+        ExternalRef:  rec.ExternalReference,
+        UserID:       owner,
+        PolicyNumber: rec.PolicyNumber,
+        ClaimAmount:  rec.ClaimAmount,
+        Description:  rec.Description,
+        ClaimType:    rec.ClaimType,
+        Documents:    rec.Documents,
+        PolicyStart:  rec.PolicyStart,
+        IncidentDate: rec.IncidentDate,
+        Status:       rec.Status,
+        CreatedAt:    createdAt,
+    }
+    for i, h := range rec.History {
+        changedBy, err := lookup(fmt.Sprintf("history[%d].changed_by", i), h.ChangedBy)
+        if err != nil {
+            return err
+        }
+        claim.History = append(claim.History, models.ClaimHistory{
+            Status:    h.Status,
+            ChangedBy: changedBy,
+            ChangedAt: h.ChangedAt,
+            Note:      h.Note,
+            Event:     h.Event,
+        })
+    }
+    if len(claim.History) == 0 {
+        claim.History = []models.ClaimHistory{{Status: models.Draft, ChangedBy: owner, ChangedAt: createdAt}}
+    }
+    last := claim.History[len(claim.History)-1].Status
+    if claim.Status == "" {
+        claim.Status = last
+    } else if len(rec.History) > 0 && claim.Status != last {
+        return utils.ValidationError(utils.FieldError{Field: "status", Code: "history_mismatch", Message: fmt.Sprintf("%s does not match the last history entry (%s)", claim.Status, last)})
+    }
+    claim.History = append(claim.History, models.ClaimHistory{
+        Status:    claim.Status,
+        ChangedBy: actor.UserID,
+        ChangedAt: now,
+        Event:     "imported",
+        Note:      "external reference " + rec.ExternalReference,
+    })
+
+    if dryRun {
+        result.Result = models.ImportValid
+        return nil
+    }
+    claim.Reference, err = nextClaimReference(s.counters, actor.TenantID, createdAt.Year())
+    if err != nil {
+        return err
+    }
+    if err := s.claimRepo.Import(actor.TenantID, claim); err != nil {
+        // A concurrent import of the same file won the unique
+        // external_reference index; report the row like any other duplicate.
+        if mongo.IsDuplicateKeyError(err) {
+            if skipped, _ := s.skipExisting(actor.TenantID, rec.ExternalReference, result); skipped {
+                return nil
+            }
+        }
+        return err
+    }
+    result.Result = models.ImportCreated
+    result.ClaimID = claim.ID.Hex()
+    result.Reference = claim.Reference
+    return nil
+}
+
+// skipExisting marks result as skipped if a claim with externalRef already
+// exists.
+func (s *importService) skipExisting(tenantID, externalRef string, result *models.ImportRowResult) (bool, error) {
+    existing, err := s.claimRepo.FindByExternalRef(tenantID, externalRef)
+    if errors.Is(err, mongo.ErrNoDocuments) {
+        return false, nil
+    }
+    if err != nil {
+        return false, err
+    }
+    result.Result = models.ImportSkipped
+    result.ClaimID = existing.ID.Hex()
+    result.Reference = existing.Reference
+    return true, nil
+}
+
+type ndjsonImportReader struct {
+    scanner *bufio.Scanner
+}
+
+func (n *ndjsonImportReader) Next() (*models.ImportRecord, error) {
+    if !n.scanner.Scan() {
+        if err := n.scanner.Err(); err != nil {
+            return nil, err
+        }
+        return nil, io.EOF
+    }
+    line := strings.TrimSpace(n.scanner.Text())
+    if line == "" {
+        return nil, errBlankRow
+    }
+    var rec models.ImportRecord
+    if err := json.Unmarshal([]byte(line), &rec); err != nil {
+        return nil, &rowError{err}
+    }
+    return &rec, nil
+}
+
+// csvImportColumns maps the CSV header to record fields. Documents are
+// separated by ";" as in exports and history is a JSON array.
+var csvImportColumns = map[string]func(rec *models.ImportRecord, v string) error{
+    "external_reference": func(rec *models.ImportRecord, v string) error { rec.ExternalReference = v; return nil },
+    "owner":              func(rec *models.ImportRecord, v string) error { rec.Owner = v; return nil },
+    "policy_number":      func(rec *models.ImportRecord, v string) error { rec.PolicyNumber = v; return nil },
+    "description":        func(rec *models.ImportRecord, v string) error { rec.Description = v; return nil },
+    "claim_type":         func(rec *models.ImportRecord, v string) error { rec.ClaimType = v; return nil },
+    "status":             func(rec *models.ImportRecord, v string) error { rec.Status = models.ClaimStatus(v); return nil },
+    "claim_amount": func(rec *models.ImportRecord, v string) error {
+        amount, err := strconv.ParseFloat(v, 64)
+        if err != nil {
+            return errors.New("must be a number")
+        }
+        rec.ClaimAmount = amount
+        return nil
+    },
+    "documents": func(rec *models.ImportRecord, v string) error {
+        for _, d := range strings.Split(v, ";") {
+            if d = strings.TrimSpace(d); d != "" {
+                rec.Documents = append(rec.Documents, d)
+            }
+        }
+        return nil
+    },
+    "policy_start_date": func(rec *models.ImportRecord, v string) error { return parseImportTime(v, &rec.PolicyStart) },
+    "incident_date":     func(rec *models.ImportRecord, v string) error { return parseImportTime(v, &rec.IncidentDate) },
+    "created_at":        func(rec *models.ImportRecord, v string) error { return parseImportTime(v, &rec.CreatedAt) },
+    "history": func(rec *models.ImportRecord, v string) error {
+        if err := json.Unmarshal([]byte(v), &rec.History); err != nil {
+            return errors.New("must be a JSON array")
+        }
+        return nil
+    },
+}
+
+// parseImportTime accepts RFC 3339 timestamps and plain dates.
+func parseImportTime(v string, dst **time.Time) error {
+    t, err := time.Parse(time.RFC3339, v)
+    if err != nil {
+        t, err = time.Parse("2006-01-02", v)
+    }
+    if err != nil {
+        return fmt.Errorf("invalid date %q", v)
+    }
+    *dst = &t
+    return nil
+}
+
+type csvImportReader struct {
+    r      *csv.Reader
+    header []string
+}
+
+func newCSVImportReader(r io.Reader) (*csvImportReader, error) {
+    c := csv.NewReader(r)
+    c.FieldsPerRecord = -1
+    header, err := c.Read()
+    if err != nil {
+        return nil, errMalformedFile.WithMessage("cannot read CSV header: " + err.Error())
+    }
+    for i, h := range header {
+        header[i] = strings.TrimSpace(strings.TrimPrefix(h, "\uFEFF"))
+        if _, ok := csvImportColumns[header[i]]; !ok {
+            return nil, errMalformedFile.WithMessage(fmt.Sprintf("unknown column %q", header[i]))
+        }
+    }
+    return &csvImportReader{r: c, header: header}, nil
+}
+
+func (c *csvImportReader) Next() (*models.ImportRecord, error) {
+    fields, err := c.r.Read()
+    if err != nil {
+        return nil, err
+    }
+    if len(fields) == 1 && strings.TrimSpace(fields[0]) == "" {
+        return nil, errBlankRow
+    }
+    if len(fields) != len(c.header) {
+        return nil, &rowError{fmt.Errorf("expected %d fields, got %d", len(c.header), len(fields))}
+    }
+    var rec models.ImportRecord
+    for i, v := range fields {
+        if v = strings.TrimSpace(v); v == "" {
+            continue
+        }
+        if err := csvImportColumns[c.header[i]](&rec, v); err != nil {
+            return nil, &rowError{fmt.Errorf("%s: %w", c.header[i], err)}
+        }
+    }
+    return &rec, nil
+}
@@ -0,0 +1,56 @@
+package services
+
+import (
+    "errors"
+    "insurance-claims-api/internal/models"
+    "insurance-claims-api/internal/repositories"
+    "insurance-claims-api/internal/utils"
+    "strings"
+    "testing"
+
+    "go.mongodb.org/mongo-driver/bson/primitive"
+    "go.mongodb.org/mongo-driver/mongo"
+)
+
+// fakeImportClaims answers every external reference lookup with err.
+type fakeImportClaims struct {
+    repositories.ClaimRepository
+    err error
+}
+
+func (f fakeImportClaims) FindByExternalRef(tenantID, externalRef string) (*models.Claim, error) {
+    return nil, f.err
+}
+
+const importRows = `{"external_reference": "OLD-1", "owner": "ghost", "policy_number": "POL-000001", "claim_amount": 100, "description": "Kaca depan retak"}
+{"external_reference": "OLD-2", "policy_number": "POL-000002", "claim_amount": 100, "description": "Kaca depan retak"}
+`
+
+func TestImportRowErrors(t *testing.T) {
+    if err := utils.RegisterValidations(`^POL-\d+$`); err != nil {
+        t.Fatal(err)
+    }
+    actor := models.Actor{UserID: primitive.NewObjectID(), TenantID: "t1"}
+
+    svc := NewImportService(fakeImportClaims{err: mongo.ErrNoDocuments}, nil, &fakeUserRepo{})
+    report, err := svc.Import(actor, "ndjson", strings.NewReader(importRows+"{not json}\n"), true)
+    if err != nil {
+        t.Fatal(err)
+    }
+    want := []struct{ result, code string }{
+        {models.ImportInvalid, utils.CodeValidation},
+        {models.ImportValid, ""},
+        {models.ImportInvalid, "malformed_row"},
+    }
+    for i, w := range want {
+        if row := report.Rows[i]; row.Result != w.result || row.Code != w.code {
+            t.Errorf("row %d = %+v, want %s %s", i+1, row, w.result, w.code)
+        }
+    }
+
+    down := errors.New("dial tcp 10.0.0.5:27017: connection refused")
+    svc = NewImportService(fakeImportClaims{err: down}, nil, &fakeUserRepo{})
+    if report, err := svc.Import(actor, "ndjson", strings.NewReader(importRows), true); !errors.Is(err, down) {
+        t.Errorf("database failure: report = %+v, err = %v", report, err)
+    }
+}
@@ -0,0 +1,123 @@
+package services
+
+import (
+    "context"
+    "crypto/tls"
+    "fmt"
+    "insurance-claims-api/internal/config"
+    "log"
+    "mime"
+    "net"
+    "net/smtp"
+    "strconv"
+    "strings"
+    "time"
+)
+
+type MailMessage struct {
+    To      string
+    Subject string
+    Body    string
+}
+
+// MailTransport delivers one email. Implementations must be safe for
+// concurrent use and give up when ctx is done.
+type MailTransport interface {
+    Send(ctx context.Context, msg MailMessage) error
+}
+
+// NewMailTransport returns an SMTP transport when SMTP_HOST is set and a
+// transport that only logs otherwise, so development setups need no server.
+func NewMailTransport(cfg config.Config) MailTransport {
+    if cfg.SMTPHost == "" {
+        return LogTransport{}
+    }
+    return &SMTPTransport{
+        Addr:     net.JoinHostPort(cfg.SMTPHost, strconv.Itoa(cfg.SMTPPort)),
+        Username: cfg.SMTPUsername,
+        Password: cfg.SMTPPassword,
+        From:     cfg.SMTPFrom,
+    }
+}
+
+// SMTPTransport sends plain-text mail, authenticating with PLAIN when a
+// username is configured. STARTTLS is used whenever the server offers it.
+type SMTPTransport struct {
+    Addr     string
+    Username string
+    Password string
+    From     string
+}
+
+func (t *SMTPTransport) Send(ctx context.Context, msg MailMessage) error {
+    var b strings.Builder
+    fmt.Fprintf(&b, "From: %s\r\n", t.From)
+    fmt.Fprintf(&b, "To: %s\r\n", msg.To)
+    fmt.Fprintf(&b, "Subject: %s\r\n", mimeHeader(msg.Subject))
+    fmt.Fprintf(&b, "Date: %s\r\n", time.Now().Format(time.RFC1123Z))
+    b.WriteString("MIME-Version: 1.0\r\n")
+    b.WriteString("Content-Type: text/plain; charset=UTF-8\r\n")
+    b.WriteString("Content-Transfer-Encoding: 8bit\r\n\r\n")
+    b.WriteString(strings.ReplaceAll(msg.Body, "\n", "\r\n"))
+
+    var d net.Dialer
+    conn, err := d.DialContext(ctx, "tcp", t.Addr)
+    if err != nil {
+        return err
+    }
+    // Closing the connection unblocks any read or write once ctx is done.
+    stop := context.AfterFunc(ctx, func() { conn.Close() })
+    defer stop()
+
+    host, _, _ := net.SplitHostPort(t.Addr)
+    c, err := smtp.NewClient(conn, host)
+    if err != nil {
+        conn.Close()
+        return err
+    }
+    defer c.Close()
+    if ok, _ := c.Extension("STARTTLS"); ok {
+        if err := c.StartTLS(&tls.Config{ServerName: host}); err != nil {
+            return err
+        }
+    }
+    if t.Username != "" {
+        if err := c.Auth(smtp.PlainAuth("", t.Username, t.Password, host)); err != nil {
+            return err
+        }
+    }
+    if err := c.Mail(t.From); err != nil {
+        return err
+    }
+    if err := c.Rcpt(msg.To); err != nil {
+        return err
+    }
+    w, err := c.Data()
+    if err != nil {
+        return err
+    }
+    if _, err := w.Write([]byte(b.String())); err != nil {
+        return err
+    }
+    if err := w.Close(); err != nil {
+        return err
+    }
+    return c.Quit()
+}
+
+// mimeHeader encodes non-ASCII subjects as RFC 2047 words.
+func mimeHeader(s string) string {
+    for _, r := range s {
+        if r > 127 {
+            return mime.QEncoding.Encode("utf-8", s)
+        }
+    }
+    return s
+}
+
+type LogTransport struct{}
+
+func (LogTransport) Send(ctx context.Context, msg MailMessage) error {
+    log.Printf("email to %s: %s", msg.To, msg.Subject)
+    return nil
+}
@@ -0,0 +1,330 @@
+package services
+
+import (
+    "bufio"
+    "context"
+    "encoding/base64"
+    "fmt"
+    "insurance-claims-api/internal/models"
+    "net"
+    "strings"
+    "sync"
+    "testing"
+    "time"
+
+    "go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// smtpServer is a minimal in-process SMTP server. It records every accepted
+// message and answers RCPT with reject while reject is non-empty.
+type smtpServer struct {
+    ln net.Listener
+
+    mu       sync.Mutex
+    messages []smtpMessage
+    auth     []string
+    reject   []string
+    stall    bool
+}
+
+type smtpMessage struct {
+    From string
+    To   []string
+    Data string
+}
+
+// newSMTPServer starts a server; configure, if not nil, sets it up before it
+// accepts connections.
+func newSMTPServer(t *testing.T, configure func(s *smtpServer)) *smtpServer {
+    ln, err := net.Listen("tcp", "127.0.0.1:0")
+    if err != nil {
+        t.Fatal(err)
+    }
+    s := &smtpServer{ln: ln}
+    if configure != nil {
+        configure(s)
+    }
+    t.Cleanup(func() { ln.Close() })
+    go func() {
+        for {
+            conn, err := ln.Accept()
+            if err != nil {
+                return
+            }
+            go s.serve(conn)
+        }
+    }()
+    return s
+}
+
+func (s *smtpServer) addr() string { return s.ln.Addr().String() }
+
+func (s *smtpServer) serve(conn net.Conn) {
+    defer conn.Close()
+    s.mu.Lock()
+    stall := s.stall
+    s.mu.Unlock()
+    if stall {
+        // Accept the connection but never greet, like a hung server.
+        time.Sleep(5 * time.Second)
+        return
+    }
+
+    r := bufio.NewReader(conn)
+    reply := func(format string, args ...interface{}) { fmt.Fprintf(conn, format+"\r\n", args...) }
+    reply("220 localhost ESMTP test")
+    var msg smtpMessage
+    for {
+        line, err := r.ReadString('\n')
+        if err != nil {
+            return
+        }
+        line = strings.TrimRight(line, "\r\n")
+        cmd := strings.ToUpper(line)
+        switch {
+        case strings.HasPrefix(cmd, "EHLO"):
+            reply("250-localhost")
+            reply("250-8BITMIME")
+            reply("250 AUTH PLAIN")
+        case strings.HasPrefix(cmd, "AUTH PLAIN "):
+            creds, _ := base64.StdEncoding.DecodeString(line[len("AUTH PLAIN "):])
+            s.mu.Lock()
+            s.auth = append(s.auth, string(creds))
+            s.mu.Unlock()
+            reply("235 2.7.0 Authentication successful")
+        case strings.HasPrefix(cmd, "MAIL FROM:"):
+            msg = smtpMessage{From: smtpAddress(line)}
+            reply("250 OK")
+        case strings.HasPrefix(cmd, "RCPT TO:"):
+            s.mu.Lock()
+            var rejection string
+            if len(s.reject) > 0 {
+                rejection, s.reject = s.reject[0], s.reject[1:]
+            }
+            s.mu.Unlock()
+            if rejection != "" {
+                reply("%s", rejection)
+                continue
+            }
+            msg.To = append(msg.To, smtpAddress(line))
+            reply("250 OK")
+        case cmd == "DATA":
+            reply("354 End data with <CR><LF>.<CR><LF>")
+            var data strings.Builder
+            for {
+                l, err := r.ReadString('\n')
+                if err != nil {
+                    return
+                }
+                if l == ".\r\n" {
+                    break
+                }
+                data.WriteString(strings.TrimPrefix(l, "."))
+            }
+            msg.Data = data.String()
+            s.mu.Lock()
+            s.messages = append(s.messages, msg)
+            s.mu.Unlock()
+            reply("250 OK queued")
+        case cmd == "QUIT":
+            reply("221 Bye")
+            return
+        default:
+            reply("250 OK")
+        }
+    }
+}
+
+// smtpAddress returns the <address> of a MAIL or RCPT command, ignoring
+// parameters such as BODY=8BITMIME.
+func smtpAddress(line string) string {
+    _, rest, _ := strings.Cut(line, "<")
+    addr, _, _ := strings.Cut(rest, ">")
+    return addr
+}
+
+func (s *smtpServer) received() []smtpMessage {
+    s.mu.Lock()
+    defer s.mu.Unlock()
+    return append([]smtpMessage(nil), s.messages...)
+}
+
+func TestSMTPTransportSend(t *testing.T) {
+    srv := newSMTPServer(t, nil)
+    transport := &SMTPTransport{Addr: srv.addr(), Username: "mailer", Password: "secret", From: "claims@example.com"}
+
+    err := transport.Send(context.Background(), MailMessage{
+        To:      "ana@example.com",
+        Subject: "Klaim CLM-2026-000001 disetujui – terima kasih",
+        Body:    "Halo Ana,\n\nKlaim Anda disetujui.",
+    })
+    if err != nil {
+        t.Fatal(err)
+    }
+
+    msgs := srv.received()
+    if len(msgs) != 1 {
+        t.Fatalf("got %d messages, want 1", len(msgs))
+    }
+    msg := msgs[0]
+    if msg.From != "claims@example.com" || len(msg.To) != 1 || msg.To[0] != "ana@example.com" {
+        t.Errorf("envelope = %s -> %v", msg.From, msg.To)
+    }
+    for _, want := range []string{
+        "From: claims@example.com\r\n",
+        "To: ana@example.com\r\n",
+        "Subject: =?utf-8?q?",
+        "Content-Type: text/plain; charset=UTF-8\r\n",
+        "\r\n\r\nHalo Ana,\r\n\r\nKlaim Anda disetujui.",
+    } {
+        if !strings.Contains(msg.Data, want) {
+            t.Errorf("message does not contain %q:\n%s", want, msg.Data)
+        }
+    }
+    srv.mu.Lock()
+    defer srv.mu.Unlock()
+    if len(srv.auth) != 1 || srv.auth[0] != "\x00mailer\x00secret" {
+        t.Errorf("auth = %q", srv.auth)
+    }
+}
+
+func TestSMTPTransportRejectedRecipient(t *testing.T) {
+    srv := newSMTPServer(t, func(s *smtpServer) { s.reject = []string{"550 5.1.1 No such user"} })
+    transport := &SMTPTransport{Addr: srv.addr(), From: "claims@example.com"}
+
+    err := transport.Send(context.Background(), MailMessage{To: "nobody@example.com", Subject: "x", Body: "x"})
+    if err == nil || !strings.Contains(err.Error(), "No such user") {
+        t.Fatalf("err = %v, want the server's rejection", err)
+    }
+    if n := len(srv.received()); n != 0 {
+        t.Errorf("got %d messages, want none", n)
+    }
+}
+
+func TestSMTPTransportHonoursContext(t *testing.T) {
+    srv := newSMTPServer(t, func(s *smtpServer) { s.stall = true })
+    transport := &SMTPTransport{Addr: srv.addr(), From: "claims@example.com"}
+
+    ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+    defer cancel()
+    start := time.Now()
+    err := transport.Send(ctx, MailMessage{To: "ana@example.com", Subject: "x", Body: "x"})
+    if err == nil {
+        t.Fatal("expected an error from a server that never answers")
+    }
+    if elapsed := time.Since(start); elapsed > 2*time.Second {
+        t.Errorf("Send took %s after the context expired", elapsed)
+    }
+}
+
+// fakeOutbox is an in-memory EmailOutboxRepository.
+type fakeOutbox struct {
+    msgs []models.EmailMessage
+}
+
+func (f *fakeOutbox) Create(tenantID string, msg *models.EmailMessage) error {
+    msg.ID = primitive.NewObjectID()
+    msg.TenantID = tenantID
+    f.msgs = append(f.msgs, *msg)
+    return nil
+}
+
+func (f *fakeOutbox) FindDue(ctx context.Context, tenantID string, now time.Time, limit int) ([]models.EmailMessage, error) {
+    var due []models.EmailMessage
+    for _, m := range f.msgs {
+        if m.TenantID == tenantID && m.Status == models.EmailPending && !m.NextAttemptAt.After(now) && len(due) < limit {
+            due = append(due, m)
+        }
+    }
+    return due, nil
+}
+
+func (f *fakeOutbox) Update(ctx context.Context, tenantID string, msg *models.EmailMessage) error {
+    for i := range f.msgs {
+        if f.msgs[i].ID == msg.ID {
+            f.msgs[i] = *msg
+        }
+    }
+    return nil
+}
+
+func TestDispatchEmailsRetriesWithBackoff(t *testing.T) {
+    srv := newSMTPServer(t, func(s *smtpServer) { s.reject = []string{"451 4.3.0 Try again later", "451 4.3.0 Try again later"} })
+    outbox := &fakeOutbox{}
+    svc := &notificationService{
+        outboxRepo:  outbox,
+        transport:   &SMTPTransport{Addr: srv.addr(), From: "claims@example.com"},
+        maxAttempts: 5,
+    }
+    start := time.Date(2026, 3, 2, 9, 0, 0, 0, time.UTC)
+    outbox.Create("t1", &models.EmailMessage{To: "ana@example.com", Subject: "s", Body: "b", Status: models.EmailPending, NextAttemptAt: start})
+    ctx := context.Background()
+
+    dispatch := func(now time.Time, wantSent int) {
+        t.Helper()
+        sent, failed, err := svc.DispatchEmails(ctx, "t1", now)
+        if err != nil {
+            t.Fatal(err)
+        }
+        if sent != wantSent || failed != 0 {
+            t.Fatalf("at %s: sent %d failed %d, want sent %d", now.Format(time.Kitchen), sent, failed, wantSent)
+        }
+    }
+
+    dispatch(start, 0)
+    msg := outbox.msgs[0]
+    if msg.Attempts != 1 || msg.Status != models.EmailPending || !msg.NextAttemptAt.Equal(start.Add(time.Minute)) {
+        t.Fatalf("after first failure: %+v", msg)
+    }
+    if !strings.Contains(msg.LastError, "Try again later") {
+        t.Errorf("last error = %q", msg.LastError)
+    }
+
+    // Not due yet: nothing is attempted.
+    dispatch(start.Add(30*time.Second), 0)
+    if outbox.msgs[0].Attempts != 1 {
+        t.Fatalf("attempted before the backoff elapsed")
+    }
+
+    dispatch(start.Add(time.Minute), 0)
+    if msg := outbox.msgs[0]; msg.Attempts != 2 || !msg.NextAttemptAt.Equal(start.Add(3*time.Minute)) {
+        t.Fatalf("after second failure: %+v", msg)
+    }
+
+    dispatch(start.Add(3*time.Minute), 1)
+    msg = outbox.msgs[0]
+    if msg.Status != models.EmailSent || msg.Attempts != 3 || msg.SentAt == nil {
+        t.Fatalf("after delivery: %+v", msg)
+    }
+    if n := len(srv.received()); n != 1 {
+        t.Errorf("server received %d messages, want 1", n)
+    }
+}
+
+func TestDispatchEmailsGivesUpAfterMaxAttempts(t *testing.T) {
+    srv := newSMTPServer(t, func(s *smtpServer) { s.reject = []string{"451 busy", "451 busy", "451 busy"} })
+    outbox := &fakeOutbox{}
+    svc := &notificationService{
+        outboxRepo:  outbox,
+        transport:   &SMTPTransport{Addr: srv.addr(), From: "claims@example.com"},
+        maxAttempts: 3,
+    }
+    now := time.Date(2026, 3, 2, 9, 0, 0, 0, time.UTC)
+    outbox.Create("t1", &models.EmailMessage{To: "ana@example.com", Status: models.EmailPending, NextAttemptAt: now})
+
+    var failed int
+    for i := 0; i < 3; i++ {
+        _, f, err := svc.DispatchEmails(context.Background(), "t1", now)
+        if err != nil {
+            t.Fatal(err)
+        }
+        failed += f
+        now = outbox.msgs[0].NextAttemptAt
+    }
+    if failed != 1 || outbox.msgs[0].Status != models.EmailFailed || outbox.msgs[0].Attempts != 3 {
+        t.Fatalf("failed %d, message %+v", failed, outbox.msgs[0])
+    }
+    if sent, _, _ := svc.DispatchEmails(context.Background(), "t1", now.Add(time.Hour)); sent != 0 {
+        t.Errorf("failed message was retried")
+    }
+}
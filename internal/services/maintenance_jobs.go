@@ -0,0 +1,148 @@
+package services
+
+import (
+    "context"
+    "fmt"
+    "insurance-claims-api/internal/config"
+    "insurance-claims-api/internal/models"
+    "insurance-claims-api/internal/repositories"
+    "log"
+    "time"
+
+    "go.mongodb.org/mongo-driver/bson"
+    "go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// ExpireStaleDraftsJob moves drafts untouched for longer than maxAge to the
+// expired status in every tenant.
+func ExpireStaleDraftsJob(claimRepo repositories.ClaimRepository, tenantService TenantService, maxAge time.Duration) JobFunc {
+    return func(ctx context.Context) (string, error) {
+        tenantIDs, err := tenantService.ActiveIDs(ctx)
+        if err != nil {
+            return "", err
+        }
+        now := time.Now()
+        var total int64
+        for _, tenantID := range tenantIDs {
+            if err := ctx.Err(); err != nil {
+                return fmt.Sprintf("%d drafts expired before stopping", total), err
+            }
+            n, err := claimRepo.UpdateMany(ctx, tenantID,
+                bson.M{"status": models.Draft, "updated_at": bson.M{"$lt": now.Add(-maxAge)}},
+                bson.M{
+                    "$set": bson.M{"status": models.Expired, "updated_at": now},
+                    "$push": bson.M{"history": models.ClaimHistory{
+                        Status:    models.Expired,
+                        ChangedBy: primitive.NilObjectID,
+                        ChangedAt: now,
+                        Note:      fmt.Sprintf("draft inactive for more than %s", maxAge),
+                    }},
+                })
+            if err != nil {
+                log.Printf("draft expiry failed for tenant %s: %v", tenantID, err)
+                continue
+            }
+            total += n
+        }
+        return fmt.Sprintf("%d drafts expired", total), nil
+    }
+}
+
+// ReleaseExpiredLocksJob clears checkouts whose lock has lapsed so the
+// claims show up as unassigned again.
+func ReleaseExpiredLocksJob(claimRepo repositories.ClaimRepository, tenantService TenantService) JobFunc {
+    return func(ctx context.Context) (string, error) {
+        tenantIDs, err := tenantService.ActiveIDs(ctx)
+        if err != nil {
+            return "", err
+        }
+        var total int64
+        for _, tenantID := range tenantIDs {
+            if err := ctx.Err(); err != nil {
+                return fmt.Sprintf("%d locks released before stopping", total), err
+            }
+            n, err := claimRepo.UpdateSystemMany(ctx, tenantID,
+                bson.M{"assignment.expires_at": bson.M{"$lte": time.Now()}},
+                bson.M{"$unset": bson.M{"assignment": ""}})
+            if err != nil {
+                log.Printf("lock sweep failed for tenant %s: %v", tenantID, err)
+                continue
+            }
+            total += n
+        }
+        return fmt.Sprintf("%d locks released", total), nil
+    }
+}
+
+// DispatchEmailsJob delivers due outbox emails in every tenant.
+func DispatchEmailsJob(notificationService NotificationService, tenantService TenantService) JobFunc {
+    return func(ctx context.Context) (string, error) {
+        tenantIDs, err := tenantService.ActiveIDs(ctx)
+        if err != nil {
+            return "", err
+        }
+        var sent, failed int
+        for _, tenantID := range tenantIDs {
+            s, f, err := notificationService.DispatchEmails(ctx, tenantID, time.Now())
+            sent += s
+            failed += f
+            if ctx.Err() != nil {
+                return fmt.Sprintf("%d emails sent, %d failed permanently before stopping", sent, failed), ctx.Err()
+            }
+            if err != nil {
+                log.Printf("email dispatch failed for tenant %s: %v", tenantID, err)
+                continue
+            }
+        }
+        return fmt.Sprintf("%d emails sent, %d failed permanently", sent, failed), nil
+    }
+}
+
+func EvaluateSLAJob(slaService SLAService) JobFunc {
+    return func(ctx context.Context) (string, error) {
+        return "", slaService.EvaluateAll(ctx, time.Now())
+    }
+}
+
+// ReportDigestJob emails every user who may read reports a summary of the
+// claims created in the last `days` full days, counted in the SLA timezone.
+func ReportDigestJob(reportService ReportService, policy PolicyService, userRepo repositories.UserRepository, notificationService NotificationService, tenantService TenantService, days int) JobFunc {
+    return func(ctx context.Context) (string, error) {
+        tenantIDs, err := tenantService.ActiveIDs(ctx)
+        if err != nil {
+            return "", err
+        }
+        loc, err := time.LoadLocation(config.AppConfig.SLATimezone)
+        if err != nil {
+            loc = time.UTC
+        }
+        y, m, d := time.Now().In(loc).Date()
+        to := time.Date(y, m, d-1, 0, 0, 0, 0, loc)
+        from := to.AddDate(0, 0, 1-days)
+
+        queued := 0
+        for _, tenantID := range tenantIDs {
+            if err := ctx.Err(); err != nil {
+                return fmt.Sprintf("%d digests queued before stopping", queued), err
+            }
+            roles, err := policy.RolesWith(tenantID, models.PermReportRead)
+            if err != nil || len(roles) == 0 {
+                continue
+            }
+            users, err := userRepo.FindByRoles(tenantID, roles)
+            if err != nil || len(users) == 0 {
+                continue
+            }
+            report, err := reportService.Summary(models.Actor{TenantID: tenantID}, models.ReportQuery{From: &from, To: &to})
+            if err != nil {
+                log.Printf("report digest failed for tenant %s: %v", tenantID, err)
+                continue
+            }
+            for _, user := range users {
+                notificationService.ReportDigest(tenantID, user, report, from, to)
+                queued++
+            }
+        }
+        return fmt.Sprintf("%d digests queued", queued), nil
+    }
+}
@@ -0,0 +1,339 @@
+package services
+
+import (
+    "bytes"
+    "context"
+    "embed"
+    "errors"
+    "fmt"
+    "insurance-claims-api/internal/config"
+    "insurance-claims-api/internal/models"
+    "insurance-claims-api/internal/repositories"
+    "insurance-claims-api/internal/utils"
+    "log"
+    "path"
+    "strings"
+    "text/template"
+    "time"
+
+    "go.mongodb.org/mongo-driver/bson/primitive"
+    "go.mongodb.org/mongo-driver/mongo"
+)
+
+var ErrNotificationNotFound = utils.NewError(utils.KindNotFound, "notification_not_found", "notification not found")
+
+// Email templates are named <template>.<language>.tmpl. The first line is the
+// subject ("Subject: ..."), followed by a blank line and the body.
+//
+//go:embed templates/email/*.tmpl
+var emailTemplateFS embed.FS
+
+var emailTemplates = loadEmailTemplates()
+
+// emailTemplateFor maps claim events to templates; events without their own
+// template use status_changed.
+var emailTemplateFor = map[string]string{
+    models.EventClaimApproved:      "approved",
+    models.EventClaimRejected:      "rejected",
+    models.EventClaimInfoRequested: "info_requested",
+}
+
+const emailBatchSize = 100
+
+func loadEmailTemplates() map[string]*template.Template {
+    files, err := emailTemplateFS.ReadDir("templates/email")
+    if err != nil {
+        log.Fatal(err)
+    }
+    out := map[string]*template.Template{}
+    for _, f := range files {
+        parts := strings.Split(strings.TrimSuffix(f.Name(), ".tmpl"), ".")
+        if len(parts) != 2 {
+            log.Fatal("bad email template name ", f.Name())
+        }
+        tmpl, err := template.New(f.Name()).Funcs(documentFuncs(parts[1])).ParseFS(emailTemplateFS, path.Join("templates/email", f.Name()))
+        if err != nil {
+            log.Fatal(err)
+        }
+        out[parts[0]+"/"+parts[1]] = tmpl
+    }
+    return out
+}
+
+type NotificationService interface {
+    Notify(tenantID string, n models.Notification)
+    ClaimEvent(tenantID string, claim *models.Claim, event string, status models.ClaimStatus, note string)
+    List(actor models.Actor, unreadOnly bool, page, limit int) ([]models.Notification, int64, error)
+    UnreadCount(actor models.Actor) (int64, error)
+    MarkRead(actor models.Actor, id primitive.ObjectID) error
+    Preferences(actor models.Actor) (*models.NotificationPreferences, error)
+    UpdatePreferences(actor models.Actor, req models.UpdateNotificationPreferencesRequest) (*models.NotificationPreferences, error)
+    ReportDigest(tenantID string, user models.User, report *models.SummaryReport, from, to time.Time)
+    SLAEscalated(tenantID string, supervisorID primitive.ObjectID, claim *models.Claim, note string)
+    DispatchEmails(ctx context.Context, tenantID string, now time.Time) (sent, failed int, err error)
+}
+
+type notificationService struct {
+    notificationRepo repositories.NotificationRepository
+    preferenceRepo   repositories.NotificationPreferenceRepository
+    outboxRepo       repositories.EmailOutboxRepository
+    userRepo         repositories.UserRepository
+    transport        MailTransport
+    maxAttempts      int
+}
+
+func NewNotificationService(notificationRepo repositories.NotificationRepository, preferenceRepo repositories.NotificationPreferenceRepository, outboxRepo repositories.EmailOutboxRepository, userRepo repositories.UserRepository, transport MailTransport, maxAttempts int) NotificationService {
+    return &notificationService{notificationRepo, preferenceRepo, outboxRepo, userRepo, transport, maxAttempts}
+}
+
+// Notify stores an in-app notification. Delivery is best effort and never
+// fails the action that triggered it.
+func (s *notificationService) Notify(tenantID string, n models.Notification) {
+    if err := s.notificationRepo.Create(tenantID, &n); err != nil {
+        log.Println("cannot store notification:", err)
+    }
+}
+
+// ClaimEvent tells the claimant about a transition of their claim, in-app
+// and by email as their preferences allow. Emails go through the outbox, so
+// a mail server outage only delays them. Like Notify it never fails.
+func (s *notificationService) ClaimEvent(tenantID string, claim *models.Claim, event string, status models.ClaimStatus, note string) {
+    prefs, err := s.preferences(tenantID, claim.UserID)
+    if err != nil {
+        log.Println("cannot load notification preferences:", err)
+        return
+    }
+    if containsString(prefs.Muted, event) || (!prefs.InApp && !prefs.Email) {
+        return
+    }
+    user, err := s.userRepo.FindByID(tenantID, claim.UserID)
+    if err != nil {
+        log.Println("cannot notify claimant:", err)
+        return
+    }
+
+    lang := prefs.Language
+    if lang == "" {
+        lang = config.AppConfig.DocumentLanguage
+    }
+    name, ok := emailTemplateFor[event]
+    if !ok {
+        name = "status_changed"
+    }
+    subject, body, err := renderEmail(name, lang, map[string]interface{}{
+        "Claim":    claim,
+        "Claimant": user.Username,
+        "Status":   status,
+        "Note":     note,
+    })
+    if err != nil {
+        log.Println("cannot render notification:", err)
+        return
+    }
+
+    if prefs.InApp {
+        claimID := claim.ID
+        s.Notify(tenantID, models.Notification{UserID: claim.UserID, Type: models.NotificationClaimStatus, ClaimID: &claimID, Message: subject})
+    }
+    if prefs.Email && user.Email != "" {
+        msg := &models.EmailMessage{
+            UserID:        claim.UserID,
+            To:            user.Email,
+            Subject:       subject,
+            Body:          body,
+            Event:         event,
+            Status:        models.EmailPending,
+            NextAttemptAt: time.Now(),
+        }
+        if err := s.outboxRepo.Create(tenantID, msg); err != nil {
+            log.Println("cannot queue email:", err)
+        }
+    }
+}
+
+// ReportDigest queues the periodic summary report email for one user,
+// unless they turned email off or muted the digest. It never fails.
+func (s *notificationService) ReportDigest(tenantID string, user models.User, report *models.SummaryReport, from, to time.Time) {
+    prefs, err := s.preferences(tenantID, user.ID)
+    if err != nil {
+        log.Println("cannot load notification preferences:", err)
+        return
+    }
+    if !prefs.Email || user.Email == "" || containsString(prefs.Muted, models.EventReportDigest) {
+        return
+    }
+    lang := prefs.Language
+    if lang == "" {
+        lang = config.AppConfig.DocumentLanguage
+    }
+    subject, body, err := renderEmail("report_digest", lang, map[string]interface{}{
+        "Name":   user.Username,
+        "From":   from,
+        "To":     to,
+        "Report": report,
+    })
+    if err != nil {
+        log.Println("cannot render report digest:", err)
+        return
+    }
+    msg := &models.EmailMessage{
+        UserID:        user.ID,
+        To:            user.Email,
+        Subject:       subject,
+        Body:          body,
+        Event:         models.EventReportDigest,
+        Status:        models.EmailPending,
+        NextAttemptAt: time.Now(),
+    }
+    if err := s.outboxRepo.Create(tenantID, msg); err != nil {
+        log.Println("cannot queue email:", err)
+    }
+}
+
+// SLAEscalated tells a supervisor that claim breached an SLA, in-app and by
+// email as their preferences allow. It never fails.
+func (s *notificationService) SLAEscalated(tenantID string, supervisorID primitive.ObjectID, claim *models.Claim, note string) {
+    prefs, err := s.preferences(tenantID, supervisorID)
+    if err != nil {
+        log.Println("cannot load notification preferences:", err)
+        return
+    }
+    if containsString(prefs.Muted, models.EventSLAEscalated) || (!prefs.InApp && !prefs.Email) {
+        return
+    }
+    user, err := s.userRepo.FindByID(tenantID, supervisorID)
+    if err != nil {
+        log.Println("cannot notify supervisor:", err)
+        return
+    }
+    lang := prefs.Language
+    if lang == "" {
+        lang = config.AppConfig.DocumentLanguage
+    }
+    subject, body, err := renderEmail("sla_escalated", lang, map[string]interface{}{
+        "Name":  user.Username,
+        "Claim": claim,
+        "Note":  note,
+    })
+    if err != nil {
+        log.Println("cannot render SLA escalation:", err)
+        return
+    }
+
+    if prefs.InApp {
+        claimID := claim.ID
+        s.Notify(tenantID, models.Notification{UserID: supervisorID, Type: models.NotificationSLA, ClaimID: &claimID, Message: subject})
+    }
+    if prefs.Email && user.Email != "" {
+        msg := &models.EmailMessage{
+            UserID:        supervisorID,
+            To:            user.Email,
+            Subject:       subject,
+            Body:          body,
+            Event:         models.EventSLAEscalated,
+            Status:        models.EmailPending,
+            NextAttemptAt: time.Now(),
+        }
+        if err := s.outboxRepo.Create(tenantID, msg); err != nil {
+            log.Println("cannot queue email:", err)
+        }
+    }
+}
+
+func renderEmail(name, lang string, data interface{}) (subject, body string, err error) {
+    tmpl, ok := emailTemplates[name+"/"+lang]
+    if !ok {
+        return "", "", fmt.Errorf("no email template %s for language %q", name, lang)
+    }
+    var buf bytes.Buffer
+    if err := tmpl.Execute(&buf, data); err != nil {
+        return "", "", err
+    }
+    head, body, _ := strings.Cut(buf.String(), "\n")
+    if !strings.HasPrefix(head, "Subject: ") {
+        return "", "", fmt.Errorf("email template %s.%s has no subject line", name, lang)
+    }
+    return strings.TrimPrefix(head, "Subject: "), strings.TrimLeft(body, "\n"), nil
+}
+
+// DispatchEmails sends the tenant's due outbox messages. A failed message is
+// retried with exponential backoff from one minute and marked failed after
+// the configured number of attempts.
+func (s *notificationService) DispatchEmails(ctx context.Context, tenantID string, now time.Time) (sent, failed int, err error) {
+    msgs, err := s.outboxRepo.FindDue(ctx, tenantID, now, emailBatchSize)
+    if err != nil {
+        return 0, 0, err
+    }
+    for i := range msgs {
+        if err := ctx.Err(); err != nil {
+            return sent, failed, err
+        }
+        msg := &msgs[i]
+        msg.Attempts++
+        if err := s.transport.Send(ctx, MailMessage{To: msg.To, Subject: msg.Subject, Body: msg.Body}); err != nil {
+            msg.LastError = err.Error()
+            msg.NextAttemptAt = now.Add(time.Minute << (msg.Attempts - 1))
+            if msg.Attempts >= s.maxAttempts {
+                msg.Status = models.EmailFailed
+                failed++
+            }
+        } else {
+            msg.Status = models.EmailSent
+            msg.SentAt = &now
+            sent++
+        }
+        if err := s.outboxRepo.Update(ctx, tenantID, msg); err != nil {
+            log.Println("cannot update outbox message:", err)
+        }
+    }
+    return sent, failed, nil
+}
+
+func (s *notificationService) List(actor models.Actor, unreadOnly bool, page, limit int) ([]models.Notification, int64, error) {
+    return s.notificationRepo.FindByUser(actor.TenantID, actor.UserID, unreadOnly, page, limit)
+}
+
+func (s *notificationService) UnreadCount(actor models.Actor) (int64, error) {
+    return s.notificationRepo.CountUnread(actor.TenantID, actor.UserID)
+}
+
+func (s *notificationService) MarkRead(actor models.Actor, id primitive.ObjectID) error {
+    return notFound(s.notificationRepo.MarkRead(actor.TenantID, actor.UserID, id), ErrNotificationNotFound)
+}
+
+func (s *notificationService) preferences(tenantID string, userID primitive.ObjectID) (*models.NotificationPreferences, error) {
+    prefs, err := s.preferenceRepo.Find(tenantID, userID)
+    if errors.Is(err, mongo.ErrNoDocuments) {
+        defaults := models.DefaultNotificationPreferences(userID)
+        defaults.TenantID = tenantID
+        return &defaults, nil
+    }
+    return prefs, err
+}
+
+func (s *notificationService) Preferences(actor models.Actor) (*models.NotificationPreferences, error) {
+    return s.preferences(actor.TenantID, actor.UserID)
+}
+
+func (s *notificationService) UpdatePreferences(actor models.Actor, req models.UpdateNotificationPreferencesRequest) (*models.NotificationPreferences, error) {
+    prefs, err := s.preferences(actor.TenantID, actor.UserID)
+    if err != nil {
+        return nil, err
+    }
+    if req.Email != nil {
+        prefs.Email = *req.Email
+    }
+    if req.InApp != nil {
+        prefs.InApp = *req.InApp
+    }
+    if req.Language != "" {
+        prefs.Language = req.Language
+    }
+    if req.Muted != nil {
+        prefs.Muted = req.Muted
+    }
+    if err := s.preferenceRepo.Save(actor.TenantID, prefs); err != nil {
+        return nil, err
+    }
+    return prefs, nil
+}
@@ -0,0 +1,234 @@
+package services
+
+import (
+    "context"
+    "crypto/rand"
+    "encoding/base64"
+    "errors"
+    "insurance-claims-api/internal/config"
+    "insurance-claims-api/internal/models"
+    "insurance-claims-api/internal/repositories"
+    "insurance-claims-api/internal/utils"
+
+    "github.com/coreos/go-oidc/v3/oidc"
+    "go.mongodb.org/mongo-driver/mongo"
+    "golang.org/x/oauth2"
+)
+
+var (
+    ErrAccountLinked       = utils.NewError(utils.KindConflict, "account_linked", "the account is already linked to another identity")
+    ErrAccountLinkRequired = utils.NewError(utils.KindConflict, "account_link_required", "an account with this username already exists; ask an administrator to link it")
+    ErrOIDCLoginFailed     = utils.NewError(utils.KindUnauthorized, "oidc_login_failed", "single sign-on failed")
+)
+
+// OIDCAuthRequest is the per-login state the handler must keep (in cookies)
+// until the IdP redirects back to the callback.
+type OIDCAuthRequest struct {
+    URL      string
+    State    string
+    Nonce    string
+    Verifier string
+}
+
+type OIDCService interface {
+    NewAuthRequest() OIDCAuthRequest
+    Callback(ctx context.Context, code, verifier, nonce string) (*models.LoginResponse, error)
+}
+
+type oidcService struct {
+    provider *oidc.Provider
+    verifier *oidc.IDTokenVerifier
+    oauth    oauth2.Config
+    cfg      config.Config
+    userRepo repositories.UserRepository
+}
+
+func NewOIDCService(ctx context.Context, cfg config.Config, userRepo repositories.UserRepository) (OIDCService, error) {
+    provider, err := oidc.NewProvider(ctx, cfg.OIDCIssuer)
+    if err != nil {
+        return nil, err
+    }
+    return &oidcService{
+        provider: provider,
+        verifier: provider.Verifier(&oidc.Config{ClientID: cfg.OIDCClientID}),
+        oauth: oauth2.Config{
+            ClientID:     cfg.OIDCClientID,
+            ClientSecret: cfg.OIDCClientSecret,
+            RedirectURL:  cfg.OIDCRedirectURL,
+            Endpoint:     provider.Endpoint(),
+            Scopes:       []string{oidc.ScopeOpenID, "profile", "email"},
+        },
+        cfg:      cfg,
+        userRepo: userRepo,
+    }, nil
+}
+
+func (s *oidcService) NewAuthRequest() OIDCAuthRequest {
+    req := OIDCAuthRequest{
+        State:    randomString(),
+        Nonce:    randomString(),
+        Verifier: oauth2.GenerateVerifier(),
+    }
+    req.URL = s.oauth.AuthCodeURL(req.State, oidc.Nonce(req.Nonce), oauth2.S256ChallengeOption(req.Verifier))
+    return req
+}
+
+func (s *oidcService) Callback(ctx context.Context, code, verifier, nonce string) (*models.LoginResponse, error) {
+    token, err := s.oauth.Exchange(ctx, code, oauth2.VerifierOption(verifier))
+    if err != nil {
+        return nil, ErrOIDCLoginFailed.WithMessage("code exchange failed")
+    }
+    rawIDToken, ok := token.Extra("id_token").(string)
+    if !ok {
+        return nil, ErrOIDCLoginFailed.WithMessage("id_token missing from token response")
+    }
+    idToken, err := s.verifier.Verify(ctx, rawIDToken)
+    if err != nil {
+        return nil, ErrOIDCLoginFailed.WithMessage("invalid id_token")
+    }
+    if idToken.Nonce != nonce {
+        return nil, ErrOIDCLoginFailed.WithMessage("invalid nonce")
+    }
+
+    var claims map[string]interface{}
+    if err := idToken.Claims(&claims); err != nil {
+        return nil, err
+    }
+
+    user, err := s.provision(idToken.Issuer, idToken.Subject, claims)
+    if err != nil {
+        return nil, err
+    }
+    return issueToken(user)
+}
+
+// provision finds or creates the local user for an IdP identity. A group
+// mapped to a role updates the user's role on every login so IdP changes take
+// effect; without one the stored role is kept.
+func (s *oidcService) provision(issuer, subject string, claims map[string]interface{}) (*models.User, error) {
+    tenantID := s.cfg.OIDCTenant
+    role, mapped := s.mapRole(groupsClaim(claims, s.cfg.OIDCGroupsClaim))
+    email := stringClaim(claims, "email")
+    verified, _ := claims["email_verified"].(bool)
+
+    // Administrators link an existing account by setting its provider and
+    // subject, so that case is found here too.
+    user, err := s.userRepo.FindBySubject(tenantID, issuer, subject)
+    if errors.Is(err, mongo.ErrNoDocuments) {
+        user, err = s.link(tenantID, email, verified)
+        if err != nil {
+            return nil, err
+        }
+        if user == nil {
+            return s.create(tenantID, issuer, subject, email, role, claims)
+        }
+        user.Provider = issuer
+        user.Subject = subject
+        user.Password = ""
+    } else if err != nil {
+        return nil, err
+    }
+
+    if mapped {
+        user.Role = role
+    }
+    if email != "" && verified {
+        user.Email = email
+    }
+    if err := s.userRepo.Update(tenantID, user); err != nil {
+        return nil, err
+    }
+    return user, nil
+}
+
+// link returns the local account staff migrating from password login keep.
+// Only an email address the IdP has verified identifies it, and an account
+// that is already linked is never taken over. It returns nil when there is
+// nothing to link.
+func (s *oidcService) link(tenantID, email string, verified bool) (*models.User, error) {
+    if email == "" || !verified {
+        return nil, nil
+    }
+    user, err := s.userRepo.FindByEmail(tenantID, email)
+    if errors.Is(err, mongo.ErrNoDocuments) {
+        return nil, nil
+    }
+    if err != nil {
+        return nil, err
+    }
+    if user.Subject != "" {
+        return nil, ErrAccountLinked
+    }
+    return user, nil
+}
+
+func (s *oidcService) create(tenantID, issuer, subject, email, role string, claims map[string]interface{}) (*models.User, error) {
+    username := stringClaim(claims, "preferred_username")
+    if username == "" {
+        username = email
+    }
+    if username == "" {
+        username = subject
+    }
+    if _, err := s.userRepo.FindByUsername(tenantID, username); err == nil {
+        return nil, ErrAccountLinkRequired
+    } else if !errors.Is(err, mongo.ErrNoDocuments) {
+        return nil, err
+    }
+    user := &models.User{Username: username, Provider: issuer, Subject: subject, Role: role, Email: email}
+    if err := s.userRepo.Create(tenantID, user); err != nil {
+        return nil, err
+    }
+    return user, nil
+}
+
+// mapRole returns the role the IdP groups map to. ok is false when no
+// configured group matches; new users then get RoleUser.
+func (s *oidcService) mapRole(groups []string) (role string, ok bool) {
+    if containsAny(groups, s.cfg.OIDCApproverGroups) {
+        return models.RoleApprover, true
+    }
+    if containsAny(groups, s.cfg.OIDCVerifierGroups) {
+        return models.RoleVerifier, true
+    }
+    return models.RoleUser, false
+}
+
+func stringClaim(claims map[string]interface{}, name string) string {
+    v, _ := claims[name].(string)
+    return v
+}
+
+// groupsClaim accepts both a JSON array and a single string, since IdPs differ.
+func groupsClaim(claims map[string]interface{}, name string) []string {
+    switch v := claims[name].(type) {
+    case string:
+        return []string{v}
+    case []interface{}:
+        groups := make([]string, 0, len(v))
+        for _, g := range v {
+            if s, ok := g.(string); ok {
+                groups = append(groups, s)
+            }
+        }
+        return groups
+    }
+    return nil
+}
+
+func containsAny(values, wanted []string) bool {
+    for _, v := range values {
+        for _, w := range wanted {
+            if v == w {
+                return true
+            }
+        }
+    }
+    return false
+}
+
+func randomString() string {
+    b := make([]byte, 32)
+    rand.Read(b)
+    return base64.RawURLEncoding.EncodeToString(b)
+}
@@ -0,0 +1,341 @@
+package services
+
+import (
+    "context"
+    "crypto/rand"
+    "crypto/rsa"
+    "crypto/sha256"
+    "encoding/base64"
+    "encoding/json"
+    "errors"
+    "insurance-claims-api/internal/config"
+    "insurance-claims-api/internal/models"
+    "math/big"
+    "net/http"
+    "net/http/httptest"
+    "net/url"
+    "sync"
+    "testing"
+    "time"
+
+    "github.com/golang-jwt/jwt/v5"
+    "go.mongodb.org/mongo-driver/bson/primitive"
+    "go.mongodb.org/mongo-driver/mongo"
+)
+
+// mockIdP is an OpenID provider serving discovery, JWKS and a token endpoint
+// that checks PKCE. Codes are registered with authorize.
+type mockIdP struct {
+    server *httptest.Server
+    key    *rsa.PrivateKey
+
+    mu    sync.Mutex
+    codes map[string]mockGrant
+}
+
+type mockGrant struct {
+    challenge string
+    claims    jwt.MapClaims
+}
+
+func newMockIdP(t *testing.T) *mockIdP {
+    key, err := rsa.GenerateKey(rand.Reader, 2048)
+    if err != nil {
+        t.Fatal(err)
+    }
+    idp := &mockIdP{key: key, codes: map[string]mockGrant{}}
+    mux := http.NewServeMux()
+    mux.HandleFunc("/.well-known/openid-configuration", func(w http.ResponseWriter, r *http.Request) {
+        json.NewEncoder(w).Encode(map[string]interface{}{
+            "issuer":                                idp.server.URL,
+            "authorization_endpoint":                idp.server.URL + "/authorize",
+            "token_endpoint":                        idp.server.URL + "/token",
+            "jwks_uri":                              idp.server.URL + "/keys",
+            "id_token_signing_alg_values_supported": []string{"RS256"},
+        })
+    })
+    mux.HandleFunc("/keys", func(w http.ResponseWriter, r *http.Request) {
+        json.NewEncoder(w).Encode(map[string]interface{}{
+            "keys": []map[string]string{{
+                "kty": "RSA",
+                "alg": "RS256",
+                "use": "sig",
+                "kid": "test",
+                "n":   base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+                "e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+            }},
+        })
+    })
+    mux.HandleFunc("/token", func(w http.ResponseWriter, r *http.Request) {
+        r.ParseForm()
+        idp.mu.Lock()
+        grant, ok := idp.codes[r.PostForm.Get("code")]
+        delete(idp.codes, r.PostForm.Get("code"))
+        idp.mu.Unlock()
+        sum := sha256.Sum256([]byte(r.PostForm.Get("code_verifier")))
+        if !ok || base64.RawURLEncoding.EncodeToString(sum[:]) != grant.challenge {
+            w.Header().Set("Content-Type", "application/json")
+            w.WriteHeader(http.StatusBadRequest)
+            w.Write([]byte(`{"error":"invalid_grant"}`))
+            return
+        }
+        claims := jwt.MapClaims{
+            "iss": idp.server.URL,
+            "aud": "claims-api",
+            "iat": time.Now().Unix(),
+            "exp": time.Now().Add(time.Hour).Unix(),
+        }
+        for k, v := range grant.claims {
+            claims[k] = v
+        }
+        token := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
+        token.Header["kid"] = "test"
+        signed, err := token.SignedString(key)
+        if err != nil {
+            http.Error(w, err.Error(), http.StatusInternalServerError)
+            return
+        }
+        w.Header().Set("Content-Type", "application/json")
+        json.NewEncoder(w).Encode(map[string]interface{}{
+            "access_token": "access",
+            "token_type":   "Bearer",
+            "expires_in":   3600,
+            "id_token":     signed,
+        })
+    })
+    idp.server = httptest.NewServer(mux)
+    t.Cleanup(idp.server.Close)
+    return idp
+}
+
+// authorize plays the user signing in at the IdP: it issues a code for the
+// PKCE challenge and nonce of authURL.
+func (idp *mockIdP) authorize(t *testing.T, authURL string, claims jwt.MapClaims) string {
+    u, err := url.Parse(authURL)
+    if err != nil {
+        t.Fatal(err)
+    }
+    q := u.Query()
+    if q.Get("code_challenge_method") != "S256" {
+        t.Fatalf("auth URL without S256 PKCE: %s", authURL)
+    }
+    grant := mockGrant{challenge: q.Get("code_challenge"), claims: jwt.MapClaims{"nonce": q.Get("nonce")}}
+    for k, v := range claims {
+        grant.claims[k] = v
+    }
+    code := randomString()
+    idp.mu.Lock()
+    idp.codes[code] = grant
+    idp.mu.Unlock()
+    return code
+}
+
+type fakeUserRepo struct {
+    users []*models.User
+}
+
+func (r *fakeUserRepo) find(match func(u *models.User) bool) (*models.User, error) {
+    for _, u := range r.users {
+        if match(u) {
+            copy := *u
+            return &copy, nil
+        }
+    }
+    return nil, mongo.ErrNoDocuments
+}
+
+func (r *fakeUserRepo) FindByUsername(tenantID, username string) (*models.User, error) {
+    return r.find(func(u *models.User) bool { return u.Username == username })
+}
+
+func (r *fakeUserRepo) FindByID(tenantID string, id primitive.ObjectID) (*models.User, error) {
+    return r.find(func(u *models.User) bool { return u.ID == id })
+}
+
+func (r *fakeUserRepo) FindBySubject(tenantID, provider, subject string) (*models.User, error) {
+    return r.find(func(u *models.User) bool { return u.Provider == provider && u.Subject == subject })
+}
+
+func (r *fakeUserRepo) FindByEmail(tenantID, email string) (*models.User, error) {
+    return r.find(func(u *models.User) bool { return u.Email == email })
+}
+
+func (r *fakeUserRepo) FindByRoles(tenantID string, roles []string) ([]models.User, error) {
+    var out []models.User
+    for _, u := range r.users {
+        for _, role := range roles {
+            if u.Role == role {
+                out = append(out, *u)
+            }
+        }
+    }
+    return out, nil
+}
+
+func (r *fakeUserRepo) Create(tenantID string, user *models.User) error {
+    if user.ID.IsZero() {
+        user.ID = primitive.NewObjectID()
+    }
+    user.TenantID = tenantID
+    copy := *user
+    r.users = append(r.users, &copy)
+    return nil
+}
+
+func (r *fakeUserRepo) Update(tenantID string, user *models.User) error {
+    for i, u := range r.users {
+        if u.ID == user.ID {
+            copy := *user
+            r.users[i] = &copy
+            return nil
+        }
+    }
+    return mongo.ErrNoDocuments
+}
+
+func TestOIDCCallback(t *testing.T) {
+    config.AppConfig.JWTSecret = "test-secret"
+    idp := newMockIdP(t)
+
+    localID := primitive.NewObjectID()
+    linkedID := primitive.NewObjectID()
+    supervisorID := primitive.NewObjectID()
+    seed := func() *fakeUserRepo {
+        return &fakeUserRepo{users: []*models.User{
+            {ID: localID, Username: "dina", Password: "hash", Role: models.RoleVerifier, Email: "dina@example.com"},
+            {ID: linkedID, Username: "eko", Role: models.RoleApprover, Email: "eko@example.com", Provider: idp.server.URL, Subject: "eko-sub"},
+            {ID: supervisorID, Username: "sari", Role: models.RoleSupervisor, Provider: idp.server.URL, Subject: "sari-sub"},
+        }}
+    }
+
+    tests := []struct {
+        name    string
+        claims  jwt.MapClaims
+        wantErr error
+        check   func(t *testing.T, repo *fakeUserRepo, resp *models.LoginResponse)
+    }{
+        {
+            name:   "new user is provisioned with the mapped role",
+            claims: jwt.MapClaims{"sub": "new-sub", "preferred_username": "budi", "email": "budi@example.com", "email_verified": true, "groups": []string{"claims-approvers"}},
+            check: func(t *testing.T, repo *fakeUserRepo, resp *models.LoginResponse) {
+                u, err := repo.FindBySubject("", idp.server.URL, "new-sub")
+                if err != nil {
+                    t.Fatal("user not created")
+                }
+                if u.Username != "budi" || u.Role != models.RoleApprover || resp.User.ID != u.ID {
+                    t.Errorf("got %+v, response %+v", u, resp.User)
+                }
+            },
+        },
+        {
+            name:   "new user without a mapped group is a user",
+            claims: jwt.MapClaims{"sub": "plain-sub", "preferred_username": "citra"},
+            check: func(t *testing.T, repo *fakeUserRepo, resp *models.LoginResponse) {
+                if resp.User.Role != models.RoleUser {
+                    t.Errorf("role = %s", resp.User.Role)
+                }
+            },
+        },
+        {
+            name:   "existing subject keeps its role without a group mapping",
+            claims: jwt.MapClaims{"sub": "sari-sub", "preferred_username": "sari"},
+            check: func(t *testing.T, repo *fakeUserRepo, resp *models.LoginResponse) {
+                if resp.User.ID != supervisorID || resp.User.Role != models.RoleSupervisor {
+                    t.Errorf("got %+v", resp.User)
+                }
+            },
+        },
+        {
+            name:   "existing subject follows an explicit group mapping",
+            claims: jwt.MapClaims{"sub": "eko-sub", "groups": "claims-verifiers"},
+            check: func(t *testing.T, repo *fakeUserRepo, resp *models.LoginResponse) {
+                if resp.User.ID != linkedID || resp.User.Role != models.RoleVerifier {
+                    t.Errorf("got %+v", resp.User)
+                }
+            },
+        },
+        {
+            name:   "verified email links the local account",
+            claims: jwt.MapClaims{"sub": "dina-sub", "preferred_username": "dina", "email": "dina@example.com", "email_verified": true},
+            check: func(t *testing.T, repo *fakeUserRepo, resp *models.LoginResponse) {
+                u, _ := repo.FindByID("", localID)
+                if resp.User.ID != localID || u.Subject != "dina-sub" || u.Password != "" || u.Role != models.RoleVerifier {
+                    t.Errorf("got %+v", u)
+                }
+            },
+        },
+        {
+            name:    "unverified email does not take over the local account",
+            claims:  jwt.MapClaims{"sub": "attacker", "preferred_username": "dina", "email": "dina@example.com", "email_verified": false},
+            wantErr: ErrAccountLinkRequired,
+            check: func(t *testing.T, repo *fakeUserRepo, resp *models.LoginResponse) {
+                u, _ := repo.FindByID("", localID)
+                if u.Subject != "" || u.Password != "hash" {
+                    t.Errorf("local account changed: %+v", u)
+                }
+            },
+        },
+        {
+            name:    "an account linked to another subject is never re-linked",
+            claims:  jwt.MapClaims{"sub": "attacker", "email": "eko@example.com", "email_verified": true},
+            wantErr: ErrAccountLinked,
+            check: func(t *testing.T, repo *fakeUserRepo, resp *models.LoginResponse) {
+                u, _ := repo.FindByID("", linkedID)
+                if u.Subject != "eko-sub" {
+                    t.Errorf("account re-linked: %+v", u)
+                }
+            },
+        },
+    }
+
+    for _, tc := range tests {
+        t.Run(tc.name, func(t *testing.T) {
+            repo := seed()
+            cfg := config.Config{
+                OIDCIssuer:         idp.server.URL,
+                OIDCClientID:       "claims-api",
+                OIDCClientSecret:   "secret",
+                OIDCRedirectURL:    "http://localhost/callback",
+                OIDCGroupsClaim:    "groups",
+                OIDCApproverGroups: []string{"claims-approvers"},
+                OIDCVerifierGroups: []string{"claims-verifiers"},
+            }
+            svc, err := NewOIDCService(context.Background(), cfg, repo)
+            if err != nil {
+                t.Fatal("discovery failed:", err)
+            }
+            req := svc.NewAuthRequest()
+            code := idp.authorize(t, req.URL, tc.claims)
+            resp, err := svc.Callback(context.Background(), code, req.Verifier, req.Nonce)
+            if tc.wantErr != nil {
+                if !errors.Is(err, tc.wantErr) {
+                    t.Fatalf("err = %v, want %v", err, tc.wantErr)
+                }
+            } else if err != nil {
+                t.Fatal(err)
+            }
+            tc.check(t, repo, resp)
+        })
+    }
+}
+
+func TestOIDCCallbackRejectsBadNonceAndVerifier(t *testing.T) {
+    config.AppConfig.JWTSecret = "test-secret"
+    idp := newMockIdP(t)
+    svc, err := NewOIDCService(context.Background(), config.Config{OIDCIssuer: idp.server.URL, OIDCClientID: "claims-api"}, &fakeUserRepo{})
+    if err != nil {
+        t.Fatal(err)
+    }
+
+    req := svc.NewAuthRequest()
+    code := idp.authorize(t, req.URL, jwt.MapClaims{"sub": "x"})
+    if _, err := svc.Callback(context.Background(), code, req.Verifier, "other-nonce"); err == nil {
+        t.Error("callback accepted a different nonce")
+    }
+
+    req = svc.NewAuthRequest()
+    code = idp.authorize(t, req.URL, jwt.MapClaims{"sub": "x"})
+    if _, err := svc.Callback(context.Background(), code, "wrong-verifier", req.Nonce); err == nil {
+        t.Error("callback accepted a different PKCE verifier")
+    }
+}
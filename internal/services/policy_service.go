@@ -0,0 +1,307 @@
+package services
+
+import (
+    "errors"
+    "fmt"
+    "insurance-claims-api/internal/config"
+    "insurance-claims-api/internal/models"
+    "insurance-claims-api/internal/repositories"
+    "insurance-claims-api/internal/utils"
+    "sync"
+    "time"
+
+    "go.mongodb.org/mongo-driver/bson"
+    "go.mongodb.org/mongo-driver/bson/primitive"
+    "go.mongodb.org/mongo-driver/mongo"
+)
+
+var ErrForbidden = utils.NewError(utils.KindForbidden, utils.CodeForbidden, "forbidden")
+
+// DefaultRoleBindings are used for any role that has no binding stored in the
+// database yet, and reproduce the behaviour of the original role checks.
+var DefaultRoleBindings = map[string][]models.Grant{
+    models.RoleUser: {
+        {Permission: models.PermClaimCreate},
+        {Permission: models.PermClaimReadOwn},
+        {Permission: models.PermClaimUpdateOwn},
+        {Permission: models.PermClaimDeleteOwn},
+        {Permission: models.PermClaimSubmitOwn},
+        {Permission: models.PermClaimWithdrawOwn},
+        {Permission: models.PermClaimAppealOwn},
+    },
+    models.RoleVerifier: {
+        {Permission: models.PermClaimReadAny, Statuses: []models.ClaimStatus{models.Submitted, models.Reviewed, models.FraudReview}},
+        {Permission: models.PermClaimUpdateAny, Statuses: []models.ClaimStatus{models.Submitted, models.Reviewed}},
+        {Permission: models.PermClaimReview},
+        {Permission: models.PermClaimFraudReview},
+        {Permission: models.PermClaimRequestInfo},
+        {Permission: models.PermQueueWork},
+        {Permission: models.PermDelegationManageOwn},
+    },
+    models.RoleApprover: {
+        {Permission: models.PermClaimReadAny, Statuses: []models.ClaimStatus{models.Reviewed, models.Approved, models.Rejected, models.Appealed}},
+        {Permission: models.PermClaimApprove},
+        {Permission: models.PermClaimReject},
+        {Permission: models.PermClaimRequestInfo},
+        {Permission: models.PermClaimDocumentGenerate},
+        {Permission: models.PermQueueWork},
+        {Permission: models.PermDelegationManageOwn},
+    },
+    models.RoleSupervisor: {
+        {Permission: models.PermClaimReadAny},
+        {Permission: models.PermClaimAssign},
+        {Permission: models.PermClaimFraudReview},
+        {Permission: models.PermClaimReopen},
+        {Permission: models.PermReportRead},
+        {Permission: models.PermClaimDocumentGenerate},
+    },
+    models.RoleAdmin: {
+        {Permission: models.PermClaimReadAny},
+        {Permission: models.PermRoleManage},
+        {Permission: models.PermTenantManage},
+        {Permission: models.PermUserImpersonate},
+        {Permission: models.PermAuditRead},
+        {Permission: models.PermSLAManage},
+        {Permission: models.PermRuleManage},
+        {Permission: models.PermReportRead},
+        {Permission: models.PermClaimImport},
+        {Permission: models.PermClaimDocumentGenerate},
+    },
+}
+
+const policyCacheTTL = time.Minute
+
+type PolicyService interface {
+    Actor(tenantID string, userID primitive.ObjectID, role string) (models.Actor, error)
+    ListBindings(tenantID string) ([]models.RoleBinding, error)
+    UpdateBinding(tenantID, role string, grants []models.Grant) (*models.RoleBinding, error)
+    RolesWith(tenantID, perm string) ([]string, error)
+}
+
+type cachedGrants struct {
+    grants   []models.Grant
+    loadedAt time.Time
+}
+
+type policyService struct {
+    roleRepo       repositories.RoleRepository
+    userRepo       repositories.UserRepository
+    delegationRepo repositories.DelegationRepository
+
+    mu    sync.RWMutex
+    cache map[string]cachedGrants
+}
+
+func NewPolicyService(roleRepo repositories.RoleRepository, userRepo repositories.UserRepository, delegationRepo repositories.DelegationRepository) PolicyService {
+    return &policyService{
+        roleRepo:       roleRepo,
+        userRepo:       userRepo,
+        delegationRepo: delegationRepo,
+        cache:          map[string]cachedGrants{},
+    }
+}
+
+func (s *policyService) Actor(tenantID string, userID primitive.ObjectID, role string) (models.Actor, error) {
+    grants, err := s.grants(tenantID, role)
+    if err != nil {
+        return models.Actor{}, err
+    }
+    actor := models.Actor{UserID: userID, TenantID: tenantID, Role: role, Grants: append([]models.Grant{{Permission: models.PermSelf}}, grants...)}
+    if role == models.RoleAdmin && tenantID != "" && tenantID == config.AppConfig.PlatformTenant {
+        for _, perm := range models.PlatformPermissions {
+            actor.Grants = append(actor.Grants, models.Grant{Permission: perm})
+        }
+    }
+
+    delegations, err := s.delegationRepo.FindActiveForDelegate(tenantID, userID, time.Now())
+    if err != nil {
+        return models.Actor{}, err
+    }
+    for _, d := range delegations {
+        delegator, err := s.userRepo.FindByID(tenantID, d.DelegatorID)
+        if err != nil {
+            continue
+        }
+        delegatorGrants, err := s.grants(tenantID, delegator.Role)
+        if err != nil {
+            return models.Actor{}, err
+        }
+        if delegable := delegableGrants(delegatorGrants); len(delegable) > 0 {
+            actor.Delegated = append(actor.Delegated, models.DelegatedGrants{DelegatorID: d.DelegatorID, Grants: delegable})
+        }
+    }
+    return actor, nil
+}
+
+func delegableGrants(grants []models.Grant) []models.Grant {
+    var out []models.Grant
+    for _, g := range grants {
+        for _, perm := range models.DelegablePermissions {
+            if g.Satisfies(perm) {
+                out = append(out, g)
+                break
+            }
+        }
+    }
+    return out
+}
+
+func (s *policyService) grants(tenantID, role string) ([]models.Grant, error) {
+    key := tenantID + "/" + role
+    s.mu.RLock()
+    cached, ok := s.cache[key]
+    s.mu.RUnlock()
+    if ok && time.Since(cached.loadedAt) < policyCacheTTL {
+        return cached.grants, nil
+    }
+
+    var grants []models.Grant
+    binding, err := s.roleRepo.FindByRole(tenantID, role)
+    switch {
+    case err == nil:
+        // Bindings stored before platform permissions were refused must
+        // not open cross-tenant routes.
+        for _, g := range binding.Grants {
+            if !models.IsPlatformPermission(g.Permission) {
+                grants = append(grants, g)
+            }
+        }
+    case errors.Is(err, mongo.ErrNoDocuments):
+        grants = DefaultRoleBindings[role]
+    default:
+        return nil, err
+    }
+
+    s.mu.Lock()
+    s.cache[key] = cachedGrants{grants: grants, loadedAt: time.Now()}
+    s.mu.Unlock()
+    return grants, nil
+}
+
+// ListBindings returns stored bindings merged with the defaults for roles that
+// have not been customised.
+func (s *policyService) ListBindings(tenantID string) ([]models.RoleBinding, error) {
+    stored, err := s.roleRepo.FindAll(tenantID)
+    if err != nil {
+        return nil, err
+    }
+    seen := map[string]bool{}
+    for _, b := range stored {
+        seen[b.Role] = true
+    }
+    for role, grants := range DefaultRoleBindings {
+        if !seen[role] {
+            stored = append(stored, models.RoleBinding{TenantID: tenantID, Role: role, Grants: grants})
+        }
+    }
+    return stored, nil
+}
+
+func (s *policyService) UpdateBinding(tenantID, role string, grants []models.Grant) (*models.RoleBinding, error) {
+    var invalid []utils.FieldError
+    for i, g := range grants {
+        field := fmt.Sprintf("grants[%d].permission", i)
+        switch {
+        case models.IsPlatformPermission(g.Permission):
+            invalid = append(invalid, utils.FieldError{Field: field, Code: "not_allowed", Message: g.Permission + " cannot be granted to a tenant role"})
+        case !models.IsKnownPermission(g.Permission):
+            invalid = append(invalid, utils.FieldError{Field: field, Code: "not_allowed", Message: "unknown permission " + g.Permission})
+        }
+    }
+    if len(invalid) > 0 {
+        return nil, utils.ValidationError(invalid...)
+    }
+    binding := &models.RoleBinding{Role: role, Grants: grants}
+    if err := s.roleRepo.Upsert(tenantID, binding); err != nil {
+        return nil, err
+    }
+    s.mu.Lock()
+    delete(s.cache, tenantID+"/"+role)
+    s.mu.Unlock()
+    return binding, nil
+}
+
+// RolesWith lists the roles of a tenant whose grants include perm.
+func (s *policyService) RolesWith(tenantID, perm string) ([]string, error) {
+    bindings, err := s.ListBindings(tenantID)
+    if err != nil {
+        return nil, err
+    }
+    var roles []string
+    for _, b := range bindings {
+        if (models.Actor{Grants: b.Grants}).Can(perm) {
+            roles = append(roles, b.Role)
+        }
+    }
+    return roles, nil
+}
+
+// authorizeClaim checks perm against a concrete claim, honouring ownership
+// scopes and status conditions on the caller's grants.
+func authorizeClaim(actor models.Actor, perm string, claim *models.Claim) error {
+    _, err := authorizeClaimAs(actor, perm, claim)
+    return err
+}
+
+// authorizeClaimAs is authorizeClaim that also reports whose authority was
+// used: nil for the actor's own grants, or the delegator's ID.
+func authorizeClaimAs(actor models.Actor, perm string, claim *models.Claim) (*primitive.ObjectID, error) {
+    if grantsAllow(actor.Grants, actor.UserID, perm, claim) {
+        return nil, nil
+    }
+    for _, d := range actor.Delegated {
+        if grantsAllow(d.Grants, d.DelegatorID, perm, claim) {
+            delegatorID := d.DelegatorID
+            return &delegatorID, nil
+        }
+    }
+    return nil, ErrForbidden
+}
+
+func grantsAllow(grants []models.Grant, owner primitive.ObjectID, perm string, claim *models.Claim) bool {
+    for _, g := range grants {
+        if !g.Satisfies(perm) {
+            continue
+        }
+        if g.OwnOnly() && claim.UserID != owner {
+            continue
+        }
+        if len(g.Statuses) > 0 && !hasStatus(g.Statuses, claim.Status) {
+            continue
+        }
+        return true
+    }
+    return false
+}
+
+// claimListFilter builds the query restricting a listing to claims the caller
+// may read through a claim:read:any grant.
+func claimListFilter(actor models.Actor) (bson.M, error) {
+    var statuses []models.ClaimStatus
+    grants := actor.Grants
+    for _, d := range actor.Delegated {
+        grants = append(grants[:len(grants):len(grants)], d.Grants...)
+    }
+    for _, g := range grants {
+        if !g.Satisfies(models.PermClaimReadAny) {
+            continue
+        }
+        if len(g.Statuses) == 0 {
+            return bson.M{}, nil
+        }
+        statuses = append(statuses, g.Statuses...)
+    }
+    if len(statuses) == 0 {
+        return nil, ErrForbidden
+    }
+    return bson.M{"status": bson.M{"$in": statuses}}, nil
+}
+
+func hasStatus(statuses []models.ClaimStatus, status models.ClaimStatus) bool {
+    for _, s := range statuses {
+        if s == status {
+            return true
+        }
+    }
+    return false
+}
@@ -0,0 +1,260 @@
+package services
+
+import (
+    "context"
+    "insurance-claims-api/internal/config"
+    "insurance-claims-api/internal/models"
+    "insurance-claims-api/internal/repositories"
+    "insurance-claims-api/internal/utils"
+    "log"
+    "sort"
+    "time"
+
+    "go.mongodb.org/mongo-driver/bson"
+    "go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+var (
+    ErrClaimLocked = utils.NewError(utils.KindConflict, "claim_locked", "claim is assigned to another user")
+    ErrNotAssignee = utils.NewError(utils.KindForbidden, "not_assignee", "claim is not assigned to you")
+    ErrNotQueued   = utils.NewError(utils.KindConflict, "not_queued", "claim is not waiting in a queue")
+)
+
+// stagePermissions maps a queue stage to the permission needed to work it.
+var stagePermissions = map[models.ClaimStatus]string{
+    models.Submitted: models.PermClaimReview,
+    models.Reviewed:  models.PermClaimApprove,
+    models.Appealed:  models.PermClaimApprove,
+}
+
+// queueStatuses are the statuses in which a claim sits in a work queue.
+var queueStatuses = []models.ClaimStatus{models.Submitted, models.Reviewed, models.Appealed}
+
+// excludedWorker returns the user who may not work claim, if any: the
+// approver whose rejection is being appealed.
+func excludedWorker(claim *models.Claim) *primitive.ObjectID {
+    if claim.Status == models.Appealed && claim.Appeal != nil {
+        return &claim.Appeal.OriginalApprover
+    }
+    return nil
+}
+
+type QueueService interface {
+    Mine(actor models.Actor, page, limit int) ([]models.Claim, int64, error)
+    Checkout(actor models.Actor, claimID primitive.ObjectID) (*models.Claim, error)
+    Release(actor models.Actor, claimID primitive.ObjectID) error
+    Reassign(actor models.Actor, claimID primitive.ObjectID, assigneeID primitive.ObjectID) error
+    AutoAssign(tenantID string, claimID primitive.ObjectID, stage models.ClaimStatus, exclude ...primitive.ObjectID)
+}
+
+type queueService struct {
+    claimRepo repositories.ClaimRepository
+    userRepo  repositories.UserRepository
+    queueRepo repositories.QueueRepository
+    policy    PolicyService
+    tenants   TenantService
+}
+
+func NewQueueService(claimRepo repositories.ClaimRepository, userRepo repositories.UserRepository, queueRepo repositories.QueueRepository, policy PolicyService, tenants TenantService) QueueService {
+    return &queueService{claimRepo, userRepo, queueRepo, policy, tenants}
+}
+
+func activeAssignmentFilter(userID primitive.ObjectID, now time.Time) bson.M {
+    return bson.M{
+        "assignment.assignee_id": userID,
+        "$or": []bson.M{
+            {"assignment.expires_at": bson.M{"$exists": false}},
+            {"assignment.expires_at": bson.M{"$gt": now}},
+        },
+    }
+}
+
+// claimableFilter matches a claim nobody else currently holds.
+func claimableFilter(userID primitive.ObjectID, now time.Time) bson.M {
+    return bson.M{"$or": []bson.M{
+        {"assignment": bson.M{"$exists": false}},
+        {"assignment.expires_at": bson.M{"$lte": now}},
+        {"assignment.assignee_id": userID},
+    }}
+}
+
+func (s *queueService) Mine(actor models.Actor, page, limit int) ([]models.Claim, int64, error) {
+    filter := activeAssignmentFilter(actor.UserID, time.Now())
+    filter["status"] = bson.M{"$in": queueStatuses}
+    return s.claimRepo.FindAll(actor.TenantID, filter, page, limit)
+}
+
+func (s *queueService) Checkout(actor models.Actor, claimID primitive.ObjectID) (*models.Claim, error) {
+    claim, err := s.claimRepo.FindByID(actor.TenantID, claimID)
+    if err != nil {
+        return nil, notFound(err, ErrClaimNotFound)
+    }
+    perm, ok := stagePermissions[claim.Status]
+    if !ok {
+        return nil, ErrNotQueued
+    }
+    if err := authorizeClaim(actor, perm, claim); err != nil {
+        return nil, err
+    }
+    if ex := excludedWorker(claim); ex != nil && *ex == actor.UserID {
+        return nil, ErrForbidden
+    }
+
+    now := time.Now()
+    expires := now.Add(config.AppConfig.QueueLockTTL)
+    assignment := &models.ClaimAssignment{AssigneeID: actor.UserID, AssignedBy: actor.UserID, AssignedAt: now, ExpiresAt: &expires}
+    cond := claimableFilter(actor.UserID, now)
+    cond["status"] = claim.Status
+    ok, err = s.claimRepo.UpdateSystem(context.TODO(), actor.TenantID, claimID, cond, bson.M{"$set": bson.M{"assignment": assignment}})
+    if err != nil {
+        return nil, err
+    }
+    if !ok {
+        return nil, ErrClaimLocked
+    }
+    // Re-read so the caller gets the version current at checkout time.
+    claim, err = s.claimRepo.FindByID(actor.TenantID, claimID)
+    if err != nil {
+        return nil, notFound(err, ErrClaimNotFound)
+    }
+    return claim, nil
+}
+
+func (s *queueService) Release(actor models.Actor, claimID primitive.ObjectID) error {
+    ok, err := s.claimRepo.UpdateSystem(context.TODO(), actor.TenantID, claimID,
+        bson.M{"assignment.assignee_id": actor.UserID},
+        bson.M{"$unset": bson.M{"assignment": ""}},
+    )
+    if err != nil {
+        return err
+    }
+    if !ok {
+        return ErrNotAssignee
+    }
+    return nil
+}
+
+func (s *queueService) Reassign(actor models.Actor, claimID primitive.ObjectID, assigneeID primitive.ObjectID) error {
+    claim, err := s.claimRepo.FindByID(actor.TenantID, claimID)
+    if err != nil {
+        return notFound(err, ErrClaimNotFound)
+    }
+    perm, ok := stagePermissions[claim.Status]
+    if !ok {
+        return ErrNotQueued
+    }
+    if ex := excludedWorker(claim); ex != nil && *ex == assigneeID {
+        return utils.ValidationError(utils.FieldError{Field: "assignee_id", Code: "not_allowed", Message: "the original approver cannot decide an appeal"})
+    }
+    assignee, err := s.userRepo.FindByID(actor.TenantID, assigneeID)
+    if err != nil {
+        return notFound(err, utils.ValidationError(utils.FieldError{Field: "assignee_id", Code: "not_found", Message: "does not exist"}))
+    }
+    assigneeActor, err := s.policy.Actor(actor.TenantID, assignee.ID, assignee.Role)
+    if err != nil {
+        return err
+    }
+    if err := authorizeClaim(assigneeActor, perm, claim); err != nil {
+        return utils.ValidationError(utils.FieldError{Field: "assignee_id", Code: "not_allowed", Message: "cannot work this claim"})
+    }
+
+    assignment := &models.ClaimAssignment{AssigneeID: assigneeID, AssignedBy: actor.UserID, AssignedAt: time.Now()}
+    ok, err = s.claimRepo.UpdateSystem(context.TODO(), actor.TenantID, claimID, bson.M{"status": claim.Status}, bson.M{"$set": bson.M{"assignment": assignment}})
+    if err != nil {
+        return err
+    }
+    if !ok {
+        return ErrConcurrentUpdate
+    }
+    return nil
+}
+
+// AutoAssign hands a claim entering stage to the next worker according to
+// ASSIGNMENT_STRATEGY, skipping any excluded users. Failures leave the claim
+// unassigned in the pool.
+func (s *queueService) AutoAssign(tenantID string, claimID primitive.ObjectID, stage models.ClaimStatus, exclude ...primitive.ObjectID) {
+    strategy := config.AppConfig.AssignmentStrategy
+    if settings, err := s.tenants.Settings(tenantID); err != nil {
+        log.Printf("tenant settings unavailable for %s: %v", tenantID, err)
+    } else if settings.AssignmentStrategy != "" {
+        strategy = settings.AssignmentStrategy
+    }
+    perm, ok := stagePermissions[stage]
+    if strategy == "manual" || !ok {
+        return
+    }
+    roles, err := s.policy.RolesWith(tenantID, perm)
+    if err != nil || len(roles) == 0 {
+        return
+    }
+    candidates, err := s.userRepo.FindByRoles(tenantID, roles)
+    if err != nil {
+        return
+    }
+    var workers []models.User
+    for _, w := range candidates {
+        if !containsID(exclude, w.ID) {
+            workers = append(workers, w)
+        }
+    }
+    if len(workers) == 0 {
+        return
+    }
+
+    var assignee primitive.ObjectID
+    if strategy == "least_workload" {
+        assignee, err = s.leastLoaded(tenantID, workers)
+    } else {
+        assignee, err = s.nextInRotation(tenantID, stage, workers)
+    }
+    if err != nil {
+        log.Println("auto-assign failed:", err)
+        return
+    }
+
+    assignment := &models.ClaimAssignment{AssigneeID: assignee, AssignedBy: assignee, AssignedAt: time.Now()}
+    cond := bson.M{"status": stage, "assignment": bson.M{"$exists": false}}
+    if _, err := s.claimRepo.UpdateSystem(context.TODO(), tenantID, claimID, cond, bson.M{"$set": bson.M{"assignment": assignment}}); err != nil {
+        log.Println("auto-assign failed:", err)
+    }
+}
+
+// nextInRotation hands out workers in ID order, one turn per assignment.
+func (s *queueService) nextInRotation(tenantID string, stage models.ClaimStatus, workers []models.User) (primitive.ObjectID, error) {
+    turn, err := s.queueRepo.NextTurn(tenantID, stage)
+    if err != nil {
+        return primitive.NilObjectID, err
+    }
+    ids := make([]primitive.ObjectID, len(workers))
+    for i, w := range workers {
+        ids[i] = w.ID
+    }
+    sort.Slice(ids, func(i, j int) bool { return ids[i].Hex() < ids[j].Hex() })
+    return ids[(turn-1)%int64(len(ids))], nil
+}
+
+func (s *queueService) leastLoaded(tenantID string, workers []models.User) (primitive.ObjectID, error) {
+    now := time.Now()
+    best, bestLoad := primitive.NilObjectID, int64(-1)
+    for _, w := range workers {
+        filter := activeAssignmentFilter(w.ID, now)
+        filter["status"] = bson.M{"$in": queueStatuses}
+        load, err := s.claimRepo.Count(tenantID, filter)
+        if err != nil {
+            return primitive.NilObjectID, err
+        }
+        if bestLoad < 0 || load < bestLoad {
+            best, bestLoad = w.ID, load
+        }
+    }
+    return best, nil
+}
+
+func containsID(ids []primitive.ObjectID, id primitive.ObjectID) bool {
+    for _, v := range ids {
+        if v == id {
+            return true
+        }
+    }
+    return false
+}
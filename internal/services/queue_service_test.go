@@ -0,0 +1,49 @@
+package services
+
+import (
+    "insurance-claims-api/internal/models"
+    "sync"
+    "sync/atomic"
+    "testing"
+
+    "go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// fakeQueueRepo advances its cursor atomically, like the $inc in MongoDB.
+type fakeQueueRepo struct {
+    turn int64
+}
+
+func (r *fakeQueueRepo) NextTurn(tenantID string, stage models.ClaimStatus) (int64, error) {
+    return atomic.AddInt64(&r.turn, 1), nil
+}
+
+func TestRoundRobinUnderConcurrency(t *testing.T) {
+    svc := &queueService{queueRepo: &fakeQueueRepo{}}
+    workers := []models.User{{ID: primitive.NewObjectID()}, {ID: primitive.NewObjectID()}, {ID: primitive.NewObjectID()}}
+
+    const rounds = 4
+    var mu sync.Mutex
+    counts := map[primitive.ObjectID]int{}
+    var wg sync.WaitGroup
+    for i := 0; i < rounds*len(workers); i++ {
+        wg.Add(1)
+        go func() {
+            defer wg.Done()
+            id, err := svc.nextInRotation("t1", models.Submitted, workers)
+            if err != nil {
+                t.Error(err)
+                return
+            }
+            mu.Lock()
+            counts[id]++
+            mu.Unlock()
+        }()
+    }
+    wg.Wait()
+    for _, w := range workers {
+        if counts[w.ID] != rounds {
+            t.Errorf("worker %s assigned %d times, want %d", w.ID.Hex(), counts[w.ID], rounds)
+        }
+    }
+}
@@ -0,0 +1,257 @@
+package services
+
+import (
+    "insurance-claims-api/internal/config"
+    "insurance-claims-api/internal/models"
+    "insurance-claims-api/internal/repositories"
+    "math"
+    "sort"
+    "time"
+
+    "go.mongodb.org/mongo-driver/bson"
+    "go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type ReportService interface {
+    Summary(actor models.Actor, q models.ReportQuery) (*models.SummaryReport, error)
+    CycleTimes(actor models.Actor, q models.ReportQuery) (*models.CycleTimeReport, error)
+}
+
+type reportService struct {
+    claimRepo repositories.ClaimRepository
+}
+
+func NewReportService(claimRepo repositories.ClaimRepository) ReportService {
+    return &reportService{claimRepo}
+}
+
+// dateRange matches field against the query's inclusive day range.
+func dateRange(q models.ReportQuery, field string) bson.M {
+    r := bson.M{}
+    if q.From != nil {
+        r["$gte"] = *q.From
+    }
+    if q.To != nil {
+        r["$lt"] = q.To.AddDate(0, 0, 1)
+    }
+    if len(r) == 0 {
+        return bson.M{}
+    }
+    return bson.M{field: r}
+}
+
+func typeMatch(q models.ReportQuery, match bson.M) bson.M {
+    if q.ClaimType != "" {
+        match["claim_type"] = q.ClaimType
+    }
+    return match
+}
+
+// period truncates a date expression to the report's grouping unit in the
+// tenant's SLA timezone. Defaults to months.
+func period(q models.ReportQuery, expr string) bson.M {
+    unit := q.GroupBy
+    if unit == "" {
+        unit = "month"
+    }
+    return bson.M{"$dateTrunc": bson.M{"date": expr, "unit": unit, "timezone": config.AppConfig.SLATimezone}}
+}
+
+func (s *reportService) Summary(actor models.Actor, q models.ReportQuery) (*models.SummaryReport, error) {
+    report := &models.SummaryReport{
+        ByStatus:   []models.StatusTotal{},
+        ByPeriod:   []models.PeriodStatusTotal{},
+        Ratios:     []models.DecisionRatio{},
+        Throughput: []models.Throughput{},
+    }
+    created := typeMatch(q, dateRange(q, "created_at"))
+
+    err := s.claimRepo.Aggregate(actor.TenantID, []bson.M{
+        {"$match": created},
+        {"$group": bson.M{"_id": "$status", "count": bson.M{"$sum": 1}, "amount": bson.M{"$sum": "$claim_amount"}}},
+        {"$project": bson.M{"status": "$_id", "count": 1, "amount": 1}},
+        {"$sort": bson.M{"status": 1}},
+    }, &report.ByStatus)
+    if err != nil {
+        return nil, err
+    }
+
+    err = s.claimRepo.Aggregate(actor.TenantID, []bson.M{
+        {"$match": created},
+        {"$group": bson.M{
+            "_id":    bson.M{"period": period(q, "$created_at"), "status": "$status"},
+            "count":  bson.M{"$sum": 1},
+            "amount": bson.M{"$sum": "$claim_amount"},
+        }},
+        {"$project": bson.M{"period": "$_id.period", "status": "$_id.status", "count": 1, "amount": 1}},
+        {"$sort": bson.D{{Key: "period", Value: 1}, {Key: "status", Value: 1}}},
+    }, &report.ByPeriod)
+    if err != nil {
+        return nil, err
+    }
+
+    decided := typeMatch(q, dateRange(q, "created_at"))
+    decided["status"] = bson.M{"$in": []models.ClaimStatus{models.Approved, models.Rejected}}
+    err = s.claimRepo.Aggregate(actor.TenantID, []bson.M{
+        {"$match": decided},
+        {"$group": bson.M{
+            "_id":      "$claim_type",
+            "approved": bson.M{"$sum": bson.M{"$cond": bson.A{bson.M{"$eq": bson.A{"$status", models.Approved}}, 1, 0}}},
+            "rejected": bson.M{"$sum": bson.M{"$cond": bson.A{bson.M{"$eq": bson.A{"$status", models.Rejected}}, 1, 0}}},
+        }},
+        {"$project": bson.M{"claim_type": bson.M{"$ifNull": bson.A{"$_id", ""}}, "approved": 1, "rejected": 1}},
+        {"$sort": bson.M{"claim_type": 1}},
+    }, &report.Ratios)
+    if err != nil {
+        return nil, err
+    }
+    for i := range report.Ratios {
+        r := &report.Ratios[i]
+        if total := float64(r.Approved + r.Rejected); total > 0 {
+            r.ApprovalRate = round2(float64(r.Approved) / total)
+            r.RejectionRate = round2(float64(r.Rejected) / total)
+        }
+    }
+
+    // Throughput counts manual decisions only: rule automation has no actor
+    // and events such as reopening are not someone's throughput.
+    changes := dateRange(q, "history.changed_at")
+    changes["history.status"] = bson.M{"$in": []models.ClaimStatus{models.Reviewed, models.Approved, models.Rejected}}
+    changes["history.event"] = bson.M{"$in": bson.A{nil, ""}}
+    changes["history.changed_by"] = bson.M{"$ne": primitive.NilObjectID}
+    err = s.claimRepo.Aggregate(actor.TenantID, []bson.M{
+        {"$match": typeMatch(q, bson.M{})},
+        {"$unwind": "$history"},
+        {"$match": changes},
+        {"$group": bson.M{
+            "_id":   bson.M{"period": period(q, "$history.changed_at"), "user": "$history.changed_by", "status": "$history.status"},
+            "count": bson.M{"$sum": 1},
+        }},
+        {"$lookup": bson.M{"from": "users", "localField": "_id.user", "foreignField": "_id", "as": "user"}},
+        {"$project": bson.M{
+            "period":   "$_id.period",
+            "user_id":  "$_id.user",
+            "status":   "$_id.status",
+            "count":    1,
+            "username": bson.M{"$arrayElemAt": bson.A{"$user.username", 0}},
+        }},
+        {"$sort": bson.D{{Key: "period", Value: 1}, {Key: "username", Value: 1}, {Key: "status", Value: 1}}},
+    }, &report.Throughput)
+    if err != nil {
+        return nil, err
+    }
+    return report, nil
+}
+
+func (s *reportService) CycleTimes(actor models.Actor, q models.ReportQuery) (*models.CycleTimeReport, error) {
+    report := &models.CycleTimeReport{Stages: []models.StageDuration{}, DecisionByPeriod: []models.PeriodDuration{}}
+
+    // Each pair of consecutive history entries is a segment spent in the
+    // first entry's status; segments are summed per claim and status.
+    at := func(field string, i interface{}) bson.M {
+        return bson.M{"$arrayElemAt": bson.A{"$history." + field, i}}
+    }
+    var stages []struct {
+        Status    models.ClaimStatus `bson:"_id"`
+        Durations []float64          `bson:"durations"`
+    }
+    segmentRange := dateRange(q, "segment.start")
+    err := s.claimRepo.Aggregate(actor.TenantID, []bson.M{
+        {"$match": typeMatch(q, bson.M{})},
+        {"$project": bson.M{"segment": bson.M{"$map": bson.M{
+            "input": bson.M{"$range": bson.A{0, bson.M{"$subtract": bson.A{bson.M{"$size": "$history"}, 1}}}},
+            "as":    "i",
+            "in": bson.M{
+                "status": at("status", "$$i"),
+                "start":  at("changed_at", "$$i"),
+                "ms":     bson.M{"$subtract": bson.A{at("changed_at", bson.M{"$add": bson.A{"$$i", 1}}), at("changed_at", "$$i")}},
+            },
+        }}}},
+        {"$unwind": "$segment"},
+        {"$match": segmentRange},
+        {"$group": bson.M{"_id": bson.M{"claim": "$_id", "status": "$segment.status"}, "ms": bson.M{"$sum": "$segment.ms"}}},
+        {"$group": bson.M{"_id": "$_id.status", "durations": bson.M{"$push": "$ms"}}},
+        {"$sort": bson.M{"_id": 1}},
+    }, &stages)
+    if err != nil {
+        return nil, err
+    }
+    for _, st := range stages {
+        report.Stages = append(report.Stages, models.StageDuration{Status: st.Status, DurationStats: durationStats(st.Durations)})
+    }
+
+    changedAt := func(statuses ...models.ClaimStatus) bson.M {
+        return bson.M{"$map": bson.M{
+            "input": bson.M{"$filter": bson.M{
+                "input": "$history",
+                "as":    "h",
+                "cond":  bson.M{"$in": bson.A{"$$h.status", statuses}},
+            }},
+            "as": "h",
+            "in": "$$h.changed_at",
+        }}
+    }
+    decided := typeMatch(q, bson.M{})
+    decided["status"] = bson.M{"$in": []models.ClaimStatus{models.Approved, models.Rejected}}
+    var periods []struct {
+        Period    time.Time `bson:"_id"`
+        Durations []float64 `bson:"durations"`
+    }
+    decidedRange := dateRange(q, "decided")
+    decidedRange["submitted"] = bson.M{"$ne": nil}
+    err = s.claimRepo.Aggregate(actor.TenantID, []bson.M{
+        {"$match": decided},
+        {"$project": bson.M{
+            "submitted": bson.M{"$min": changedAt(models.Submitted)},
+            "decided":   bson.M{"$max": changedAt(models.Approved, models.Rejected)},
+        }},
+        {"$match": decidedRange},
+        {"$group": bson.M{
+            "_id":       period(q, "$decided"),
+            "durations": bson.M{"$push": bson.M{"$subtract": bson.A{"$decided", "$submitted"}}},
+        }},
+        {"$sort": bson.M{"_id": 1}},
+    }, &periods)
+    if err != nil {
+        return nil, err
+    }
+    var all []float64
+    for _, p := range periods {
+        all = append(all, p.Durations...)
+        report.DecisionByPeriod = append(report.DecisionByPeriod, models.PeriodDuration{Period: p.Period, DurationStats: durationStats(p.Durations)})
+    }
+    report.Decision = durationStats(all)
+    return report, nil
+}
+
+// durationStats turns millisecond durations into hour statistics using
+// nearest-rank percentiles.
+func durationStats(ms []float64) models.DurationStats {
+    if len(ms) == 0 {
+        return models.DurationStats{}
+    }
+    sorted := append([]float64(nil), ms...)
+    sort.Float64s(sorted)
+    sum := 0.0
+    for _, v := range sorted {
+        sum += v
+    }
+    pct := func(p float64) float64 {
+        i := int(math.Ceil(p*float64(len(sorted)))) - 1
+        if i < 0 {
+            i = 0
+        }
+        return round2(sorted[i] / float64(time.Hour/time.Millisecond))
+    }
+    return models.DurationStats{
+        Claims: len(sorted),
+        Avg:    round2(sum / float64(len(sorted)) / float64(time.Hour/time.Millisecond)),
+        P50:    pct(0.5),
+        P90:    pct(0.9),
+        P95:    pct(0.95),
+    }
+}
+
+func round2(v float64) float64 {
+    return math.Round(v*100) / 100
+}
@@ -0,0 +1,337 @@
+package services
+
+import (
+    "errors"
+    "insurance-claims-api/internal/models"
+    "insurance-claims-api/internal/repositories"
+    "insurance-claims-api/internal/utils"
+    "regexp"
+    "sort"
+    "time"
+
+    "go.mongodb.org/mongo-driver/bson"
+    "go.mongodb.org/mongo-driver/bson/primitive"
+    "go.mongodb.org/mongo-driver/mongo"
+)
+
+var (
+    ErrRuleNotFound = utils.NewError(utils.KindNotFound, "rule_not_found", "rule not found")
+    ErrRuleExists   = utils.NewError(utils.KindConflict, "rule_exists", "rule already exists")
+    ErrRuleModified = utils.NewError(utils.KindConflict, "rule_modified", "rule was changed by another user")
+)
+
+var ruleIDPattern = regexp.MustCompile(`^[a-z0-9][a-z0-9_-]{1,62}$`)
+
+const defaultRuleHistoryDays = 365
+
+type RuleService interface {
+    List(actor models.Actor) ([]models.ClaimRule, error)
+    Versions(actor models.Actor, ruleID string) ([]models.ClaimRule, error)
+    Create(actor models.Actor, req models.ClaimRuleRequest) (*models.ClaimRule, error)
+    Update(actor models.Actor, ruleID string, req models.ClaimRuleRequest) (*models.ClaimRule, error)
+    Disable(actor models.Actor, ruleID string) (*models.ClaimRule, error)
+    Simulate(actor models.Actor, req models.SimulateRulesRequest) (*models.RuleOutcome, error)
+    Evaluate(tenantID string, claim *models.Claim) (*models.RuleOutcome, error)
+}
+
+type ruleService struct {
+    ruleRepo  repositories.RuleRepository
+    claimRepo repositories.ClaimRepository
+    userRepo  repositories.UserRepository
+    policy    PolicyService
+}
+
+func NewRuleService(ruleRepo repositories.RuleRepository, claimRepo repositories.ClaimRepository, userRepo repositories.UserRepository, policy PolicyService) RuleService {
+    return &ruleService{ruleRepo, claimRepo, userRepo, policy}
+}
+
+func (s *ruleService) List(actor models.Actor) ([]models.ClaimRule, error) {
+    return s.ruleRepo.FindCurrent(actor.TenantID)
+}
+
+func (s *ruleService) Versions(actor models.Actor, ruleID string) ([]models.ClaimRule, error) {
+    rules, err := s.ruleRepo.FindVersions(actor.TenantID, ruleID)
+    if err == nil && len(rules) == 0 {
+        return nil, ErrRuleNotFound
+    }
+    return rules, err
+}
+
+func (s *ruleService) Create(actor models.Actor, req models.ClaimRuleRequest) (*models.ClaimRule, error) {
+    if !ruleIDPattern.MatchString(req.RuleID) {
+        return nil, utils.ValidationError(utils.FieldError{Field: "rule_id", Code: "invalid_format", Message: "must be 2-63 lowercase letters, digits, - or _"})
+    }
+    if _, err := s.ruleRepo.FindCurrentByRuleID(actor.TenantID, req.RuleID); err == nil {
+        return nil, ErrRuleExists
+    } else if !errors.Is(err, mongo.ErrNoDocuments) {
+        return nil, err
+    }
+    if err := s.checkAssignee(actor.TenantID, req.Actions.AssignTo); err != nil {
+        return nil, err
+    }
+    rule := ruleFromRequest(req)
+    rule.RuleID = req.RuleID
+    rule.Version = 1
+    rule.CreatedBy = actor.UserID
+    if err := s.ruleRepo.CreateVersion(actor.TenantID, &rule); err != nil {
+        if mongo.IsDuplicateKeyError(err) {
+            return nil, ErrRuleExists
+        }
+        return nil, err
+    }
+    return &rule, nil
+}
+
+func (s *ruleService) Update(actor models.Actor, ruleID string, req models.ClaimRuleRequest) (*models.ClaimRule, error) {
+    current, err := s.ruleRepo.FindCurrentByRuleID(actor.TenantID, ruleID)
+    if errors.Is(err, mongo.ErrNoDocuments) {
+        return nil, ErrRuleNotFound
+    }
+    if err != nil {
+        return nil, err
+    }
+    if err := s.checkAssignee(actor.TenantID, req.Actions.AssignTo); err != nil {
+        return nil, err
+    }
+    rule := ruleFromRequest(req)
+    rule.RuleID = ruleID
+    rule.Version = current.Version + 1
+    rule.CreatedBy = actor.UserID
+    if err := s.createVersion(actor.TenantID, &rule); err != nil {
+        return nil, err
+    }
+    return &rule, nil
+}
+
+// Disable stores a new, disabled version so the rule's history is kept.
+func (s *ruleService) Disable(actor models.Actor, ruleID string) (*models.ClaimRule, error) {
+    current, err := s.ruleRepo.FindCurrentByRuleID(actor.TenantID, ruleID)
+    if errors.Is(err, mongo.ErrNoDocuments) {
+        return nil, ErrRuleNotFound
+    }
+    if err != nil {
+        return nil, err
+    }
+    rule := *current
+    rule.Version++
+    rule.Enabled = false
+    rule.CreatedBy = actor.UserID
+    if err := s.createVersion(actor.TenantID, &rule); err != nil {
+        return nil, err
+    }
+    return &rule, nil
+}
+
+// createVersion stores the next version of an existing rule. A duplicate
+// version means someone else saved the rule since it was read.
+func (s *ruleService) createVersion(tenantID string, rule *models.ClaimRule) error {
+    err := s.ruleRepo.CreateVersion(tenantID, rule)
+    if mongo.IsDuplicateKeyError(err) {
+        return ErrRuleModified
+    }
+    return err
+}
+
+// checkAssignee makes sure a rule only assigns claims to an existing user
+// who can work at least one queue stage.
+func (s *ruleService) checkAssignee(tenantID string, assignTo *primitive.ObjectID) error {
+    if assignTo == nil {
+        return nil
+    }
+    invalid := utils.ValidationError(utils.FieldError{Field: "actions.assign_to", Code: "not_allowed", Message: "must be a user who can review or approve claims"})
+    user, err := s.userRepo.FindByID(tenantID, *assignTo)
+    if err != nil {
+        return notFound(err, invalid)
+    }
+    assignee, err := s.policy.Actor(tenantID, user.ID, user.Role)
+    if err != nil {
+        return err
+    }
+    for _, perm := range stagePermissions {
+        if assignee.OwnCan(perm) {
+            return nil
+        }
+    }
+    return invalid
+}
+
+func ruleFromRequest(req models.ClaimRuleRequest) models.ClaimRule {
+    enabled := true
+    if req.Enabled != nil {
+        enabled = *req.Enabled
+    }
+    return models.ClaimRule{
+        Name:       req.Name,
+        Priority:   req.Priority,
+        Enabled:    enabled,
+        Conditions: req.Conditions,
+        Actions:    req.Actions,
+    }
+}
+
+// Simulate is a dry run of Evaluate; nothing is written.
+func (s *ruleService) Simulate(actor models.Actor, req models.SimulateRulesRequest) (*models.RuleOutcome, error) {
+    var claim *models.Claim
+    switch {
+    case req.ClaimID != nil:
+        found, err := s.claimRepo.FindByID(actor.TenantID, *req.ClaimID)
+        if err != nil {
+            return nil, notFound(err, ErrClaimNotFound)
+        }
+        claim = found
+    case req.Claim != nil:
+        claim = &models.Claim{
+            PolicyNumber: req.Claim.PolicyNumber,
+            ClaimAmount:  req.Claim.ClaimAmount,
+            Description:  req.Claim.Description,
+            ClaimType:    req.Claim.ClaimType,
+            Documents:    req.Claim.Documents,
+            CreatedAt:    time.Now(),
+        }
+        if req.UserID != nil {
+            claim.UserID = *req.UserID
+        }
+    default:
+        return nil, utils.ValidationError(utils.FieldError{Field: "claim_id", Code: "required", Message: "or claim is required"})
+    }
+
+    if req.Rules == nil {
+        return s.Evaluate(actor.TenantID, claim)
+    }
+    rules := make([]models.ClaimRule, len(req.Rules))
+    for i, r := range req.Rules {
+        rules[i] = ruleFromRequest(r)
+        rules[i].RuleID = r.RuleID
+    }
+    sort.SliceStable(rules, func(i, j int) bool { return rules[i].Priority < rules[j].Priority })
+    return s.evaluate(actor.TenantID, claim, rules)
+}
+
+// Evaluate runs the current rules against a claim being submitted and
+// returns what should happen to it.
+func (s *ruleService) Evaluate(tenantID string, claim *models.Claim) (*models.RuleOutcome, error) {
+    rules, err := s.ruleRepo.FindCurrent(tenantID)
+    if err != nil {
+        return nil, err
+    }
+    return s.evaluate(tenantID, claim, rules)
+}
+
+func (s *ruleService) evaluate(tenantID string, claim *models.Claim, rules []models.ClaimRule) (*models.RuleOutcome, error) {
+    outcome := &models.RuleOutcome{Matched: []models.RuleMatch{}, Status: models.Submitted}
+    history := map[int]claimantHistory{}
+    for i := range rules {
+        rule := rules[i]
+        if !rule.Enabled {
+            continue
+        }
+        ok, err := s.matches(tenantID, claim, rule.Conditions, history)
+        if err != nil {
+            return nil, err
+        }
+        if !ok {
+            continue
+        }
+        match := models.RuleMatch{RuleID: rule.RuleID, Version: rule.Version, Name: rule.Name}
+        outcome.Matched = append(outcome.Matched, match)
+
+        a := rule.Actions
+        if a.Decision != "" && outcome.DecisionRule == nil {
+            outcome.Decision, outcome.DecisionRule = a.Decision, &match
+        }
+        if a.AssignTo != nil && outcome.AssignRule == nil {
+            outcome.AssignTo, outcome.AssignRule = a.AssignTo, &match
+        }
+        if a.FlagFraud && outcome.FraudRule == nil {
+            outcome.FlagFraud, outcome.FraudRule = true, &match
+        }
+        if a.Priority != "" && outcome.PriorityRule == nil {
+            outcome.Priority, outcome.PriorityRule = a.Priority, &match
+        }
+    }
+
+    if outcome.FlagFraud {
+        outcome.Decision, outcome.DecisionRule = "", nil
+        outcome.Status = models.FraudReview
+    }
+    switch outcome.Decision {
+    case models.RuleAutoReview:
+        outcome.Status = models.Reviewed
+    case models.RuleAutoApprove:
+        outcome.Status = models.Approved
+    }
+    return outcome, nil
+}
+
+type claimantHistory struct {
+    claims, rejects int64
+}
+
+func (s *ruleService) matches(tenantID string, claim *models.Claim, c models.RuleConditions, cache map[int]claimantHistory) (bool, error) {
+    if c.MinAmount != nil && claim.ClaimAmount < *c.MinAmount {
+        return false, nil
+    }
+    if c.MaxAmount != nil && claim.ClaimAmount > *c.MaxAmount {
+        return false, nil
+    }
+    if len(c.ClaimTypes) > 0 && !containsString(c.ClaimTypes, claim.ClaimType) {
+        return false, nil
+    }
+    if c.MinDocuments != nil && len(claim.Documents) < *c.MinDocuments {
+        return false, nil
+    }
+    if c.MaxDocuments != nil && len(claim.Documents) > *c.MaxDocuments {
+        return false, nil
+    }
+    if c.MaxPriorClaims == nil && c.MaxPriorRejects == nil {
+        return true, nil
+    }
+
+    days := c.HistoryDays
+    if days <= 0 {
+        days = defaultRuleHistoryDays
+    }
+    h, ok := cache[days]
+    if !ok {
+        var err error
+        if h, err = s.claimantHistory(tenantID, claim, days); err != nil {
+            return false, err
+        }
+        cache[days] = h
+    }
+    if c.MaxPriorClaims != nil && h.claims > int64(*c.MaxPriorClaims) {
+        return false, nil
+    }
+    if c.MaxPriorRejects != nil && h.rejects > int64(*c.MaxPriorRejects) {
+        return false, nil
+    }
+    return true, nil
+}
+
+func (s *ruleService) claimantHistory(tenantID string, claim *models.Claim, days int) (claimantHistory, error) {
+    filter := bson.M{
+        "user_id":    claim.UserID,
+        "_id":        bson.M{"$ne": claim.ID},
+        "status":     bson.M{"$ne": models.Draft},
+        "created_at": bson.M{"$gte": time.Now().AddDate(0, 0, -days)},
+    }
+    claims, err := s.claimRepo.Count(tenantID, filter)
+    if err != nil {
+        return claimantHistory{}, err
+    }
+    filter["status"] = models.Rejected
+    rejects, err := s.claimRepo.Count(tenantID, filter)
+    if err != nil {
+        return claimantHistory{}, err
+    }
+    return claimantHistory{claims, rejects}, nil
+}
+
+func containsString(list []string, v string) bool {
+    for _, item := range list {
+        if item == v {
+            return true
+        }
+    }
+    return false
+}
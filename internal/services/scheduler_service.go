@@ -0,0 +1,208 @@
+package services
+
+import (
+    "context"
+    "crypto/rand"
+    "encoding/hex"
+    "fmt"
+    "insurance-claims-api/internal/models"
+    "insurance-claims-api/internal/repositories"
+    "insurance-claims-api/internal/utils"
+    "log"
+    "os"
+    "sort"
+    "sync"
+    "time"
+
+    "github.com/robfig/cron/v3"
+)
+
+var (
+    ErrJobNotFound = utils.NewError(utils.KindNotFound, "job_not_found", "job not found")
+    ErrJobRunning  = utils.NewError(utils.KindConflict, "job_running", "job is already running")
+)
+
+// JobFunc does one run of a job and returns a short human-readable result.
+type JobFunc func(ctx context.Context) (string, error)
+
+const (
+    jobTimeout    = 10 * time.Minute
+    schedulerTick = 15 * time.Second
+)
+
+type SchedulerService interface {
+    Register(name, spec, description string, fn JobFunc) error
+    Start()
+    Jobs() ([]models.JobInfo, error)
+    Trigger(name string) (*models.JobRun, error)
+    Runs(name string, limit int) ([]models.JobRun, error)
+}
+
+type scheduledJob struct {
+    name        string
+    spec        string
+    description string
+    schedule    cron.Schedule
+    fn          JobFunc
+    next        time.Time
+}
+
+// schedulerService runs registered jobs on every replica's clock, but a job
+// only executes on the replica that wins its Mongo lease for that slot.
+type schedulerService struct {
+    jobRepo  repositories.JobRepository
+    instance string
+
+    mu   sync.Mutex
+    jobs map[string]*scheduledJob
+}
+
+func NewSchedulerService(jobRepo repositories.JobRepository) SchedulerService {
+    host, _ := os.Hostname()
+    b := make([]byte, 4)
+    rand.Read(b)
+    return &schedulerService{
+        jobRepo:  jobRepo,
+        instance: host + "-" + hex.EncodeToString(b),
+        jobs:     map[string]*scheduledJob{},
+    }
+}
+
+func (s *schedulerService) Register(name, spec, description string, fn JobFunc) error {
+    schedule, err := cron.ParseStandard(spec)
+    if err != nil {
+        return fmt.Errorf("job %s: %w", name, err)
+    }
+    s.mu.Lock()
+    defer s.mu.Unlock()
+    s.jobs[name] = &scheduledJob{
+        name:        name,
+        spec:        spec,
+        description: description,
+        schedule:    schedule,
+        fn:          fn,
+        next:        schedule.Next(time.Now()),
+    }
+    return nil
+}
+
+func (s *schedulerService) Start() {
+    go func() {
+        ticker := time.NewTicker(schedulerTick)
+        defer ticker.Stop()
+        for now := range ticker.C {
+            s.mu.Lock()
+            var due []*scheduledJob
+            var slots []time.Time
+            for _, job := range s.jobs {
+                if !now.Before(job.next) {
+                    due = append(due, job)
+                    slots = append(slots, job.next)
+                    job.next = job.schedule.Next(now)
+                }
+            }
+            s.mu.Unlock()
+            for i, job := range due {
+                go s.run(job, models.JobTriggerSchedule, slots[i])
+            }
+        }
+    }()
+}
+
+// run executes job if this replica can take its lease. It returns nil when
+// another replica holds the lease.
+func (s *schedulerService) run(job *scheduledJob, trigger string, slot time.Time) *models.JobRun {
+    ok, err := s.jobRepo.AcquireLease(job.name, s.instance, slot, jobTimeout)
+    if err != nil {
+        log.Printf("job %s: lease failed: %v", job.name, err)
+        return nil
+    }
+    if !ok {
+        return nil
+    }
+    defer s.jobRepo.ReleaseLease(job.name, s.instance)
+
+    run := &models.JobRun{
+        Job:       job.name,
+        Trigger:   trigger,
+        Instance:  s.instance,
+        Status:    models.JobRunning,
+        StartedAt: time.Now(),
+    }
+    if err := s.jobRepo.CreateRun(run); err != nil {
+        log.Printf("job %s: cannot record run: %v", job.name, err)
+    }
+
+    ctx, cancel := context.WithTimeout(context.Background(), jobTimeout)
+    defer cancel()
+    result, err := s.safeRun(ctx, job.fn)
+
+    finished := time.Now()
+    run.FinishedAt = &finished
+    run.Result = result
+    run.Status = models.JobSucceeded
+    if err != nil {
+        run.Status = models.JobFailed
+        run.Error = err.Error()
+        log.Printf("job %s failed: %v", job.name, err)
+    }
+    if err := s.jobRepo.FinishRun(run); err != nil {
+        log.Printf("job %s: cannot record run: %v", job.name, err)
+    }
+    return run
+}
+
+func (s *schedulerService) safeRun(ctx context.Context, fn JobFunc) (result string, err error) {
+    defer func() {
+        if r := recover(); r != nil {
+            err = fmt.Errorf("panic: %v", r)
+        }
+    }()
+    return fn(ctx)
+}
+
+func (s *schedulerService) Jobs() ([]models.JobInfo, error) {
+    s.mu.Lock()
+    infos := make([]models.JobInfo, 0, len(s.jobs))
+    for _, job := range s.jobs {
+        infos = append(infos, models.JobInfo{Name: job.name, Description: job.description, Schedule: job.spec, NextRunAt: job.next})
+    }
+    s.mu.Unlock()
+
+    sort.Slice(infos, func(i, j int) bool { return infos[i].Name < infos[j].Name })
+    for i := range infos {
+        runs, err := s.jobRepo.FindRuns(infos[i].Name, 1)
+        if err != nil {
+            return nil, err
+        }
+        if len(runs) > 0 {
+            infos[i].LastRun = &runs[0]
+        }
+    }
+    return infos, nil
+}
+
+// Trigger runs a job immediately on this replica and waits for the result.
+func (s *schedulerService) Trigger(name string) (*models.JobRun, error) {
+    s.mu.Lock()
+    job, ok := s.jobs[name]
+    s.mu.Unlock()
+    if !ok {
+        return nil, ErrJobNotFound
+    }
+    run := s.run(job, models.JobTriggerManual, time.Time{})
+    if run == nil {
+        return nil, ErrJobRunning
+    }
+    return run, nil
+}
+
+func (s *schedulerService) Runs(name string, limit int) ([]models.JobRun, error) {
+    s.mu.Lock()
+    _, ok := s.jobs[name]
+    s.mu.Unlock()
+    if !ok {
+        return nil, ErrJobNotFound
+    }
+    return s.jobRepo.FindRuns(name, limit)
+}
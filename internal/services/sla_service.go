@@ -0,0 +1,341 @@
+package services
+
+import (
+    "context"
+    "errors"
+    "fmt"
+    "insurance-claims-api/internal/config"
+    "insurance-claims-api/internal/models"
+    "insurance-claims-api/internal/repositories"
+    "insurance-claims-api/internal/utils"
+    "log"
+    "time"
+
+    "go.mongodb.org/mongo-driver/bson"
+    "go.mongodb.org/mongo-driver/bson/primitive"
+    "go.mongodb.org/mongo-driver/mongo"
+)
+
+const defaultAtRiskRatio = 0.8
+
+var ErrSLAPolicyNotFound = utils.NewError(utils.KindNotFound, "sla_policy_not_found", "SLA policy not found")
+
+type SLAService interface {
+    ListPolicies(actor models.Actor) ([]models.SLAPolicy, error)
+    CreatePolicy(actor models.Actor, policy models.SLAPolicy) (*models.SLAPolicy, error)
+    UpdatePolicy(actor models.Actor, id primitive.ObjectID, policy models.SLAPolicy) (*models.SLAPolicy, error)
+    DeletePolicy(actor models.Actor, id primitive.ObjectID) error
+    GetCalendar(actor models.Actor) (*models.BusinessCalendar, error)
+    UpdateCalendar(actor models.Actor, calendar models.BusinessCalendar) (*models.BusinessCalendar, error)
+
+    EvaluateAll(ctx context.Context, now time.Time) error
+}
+
+type slaService struct {
+    slaRepo       repositories.SLARepository
+    claimRepo     repositories.ClaimRepository
+    userRepo      repositories.UserRepository
+    policy        PolicyService
+    tenantService TenantService
+    notify        NotificationService
+}
+
+func NewSLAService(slaRepo repositories.SLARepository, claimRepo repositories.ClaimRepository, userRepo repositories.UserRepository, policy PolicyService, tenantService TenantService, notify NotificationService) SLAService {
+    return &slaService{slaRepo, claimRepo, userRepo, policy, tenantService, notify}
+}
+
+func (s *slaService) ListPolicies(actor models.Actor) ([]models.SLAPolicy, error) {
+    return s.slaRepo.FindPolicies(actor.TenantID)
+}
+
+func (s *slaService) CreatePolicy(actor models.Actor, policy models.SLAPolicy) (*models.SLAPolicy, error) {
+    if err := s.slaRepo.CreatePolicy(actor.TenantID, &policy); err != nil {
+        return nil, err
+    }
+    return &policy, nil
+}
+
+func (s *slaService) UpdatePolicy(actor models.Actor, id primitive.ObjectID, policy models.SLAPolicy) (*models.SLAPolicy, error) {
+    policy.ID = id
+    if err := s.slaRepo.UpdatePolicy(actor.TenantID, &policy); err != nil {
+        return nil, notFound(err, ErrSLAPolicyNotFound)
+    }
+    return &policy, nil
+}
+
+func (s *slaService) DeletePolicy(actor models.Actor, id primitive.ObjectID) error {
+    return notFound(s.slaRepo.DeletePolicy(actor.TenantID, id), ErrSLAPolicyNotFound)
+}
+
+func (s *slaService) GetCalendar(actor models.Actor) (*models.BusinessCalendar, error) {
+    cal, err := s.calendar(actor.TenantID)
+    if err != nil {
+        return nil, err
+    }
+    return &cal, nil
+}
+
+func (s *slaService) UpdateCalendar(actor models.Actor, calendar models.BusinessCalendar) (*models.BusinessCalendar, error) {
+    if _, err := newBusinessCalendar(calendar); err != nil {
+        return nil, utils.ValidationError(utils.FieldError{Field: "timezone", Code: "invalid", Message: "is not a known timezone"})
+    }
+    if err := s.slaRepo.UpsertCalendar(actor.TenantID, &calendar); err != nil {
+        return nil, err
+    }
+    return &calendar, nil
+}
+
+func (s *slaService) calendar(tenantID string) (models.BusinessCalendar, error) {
+    cal, err := s.slaRepo.FindCalendar(tenantID)
+    if errors.Is(err, mongo.ErrNoDocuments) {
+        return defaultCalendar(config.AppConfig.SLATimezone), nil
+    }
+    if err != nil {
+        return models.BusinessCalendar{}, err
+    }
+    return *cal, nil
+}
+
+func (s *slaService) EvaluateAll(ctx context.Context, now time.Time) error {
+    tenantIDs, err := s.tenantService.ActiveIDs(ctx)
+    if err != nil {
+        return err
+    }
+    for _, tenantID := range tenantIDs {
+        if err := ctx.Err(); err != nil {
+            return err
+        }
+        if err := s.evaluateTenant(ctx, tenantID, now); err != nil {
+            log.Printf("SLA evaluation failed for tenant %s: %v", tenantID, err)
+        }
+    }
+    return nil
+}
+
+func (s *slaService) evaluateTenant(ctx context.Context, tenantID string, now time.Time) error {
+    policies, err := s.slaRepo.FindPolicies(tenantID)
+    if err != nil || len(policies) == 0 {
+        return err
+    }
+    calSettings, err := s.calendar(tenantID)
+    if err != nil {
+        return err
+    }
+    cal, err := newBusinessCalendar(calSettings)
+    if err != nil {
+        return err
+    }
+
+    // Claims still flagged from a status they have since left are included
+    // so their flag is cleared.
+    filter := bson.M{"$or": []bson.M{
+        {"status": bson.M{"$in": slaStatuses(policies)}},
+        {"sla_flag": bson.M{"$exists": true, "$ne": ""}},
+    }}
+    return s.claimRepo.ForEach(ctx, tenantID, filter, func(claim *models.Claim) error {
+        states, flag := evaluateSLA(cal, policies, claim, now)
+        note, escalatedTo := s.escalate(tenantID, states, now)
+        if note == "" && flag == claim.SLAFlag && !slaChanged(claim.SLA, states) {
+            return nil
+        }
+        update := bson.M{"$set": bson.M{"sla": states, "sla_flag": flag}}
+        if note != "" {
+            update["$push"] = bson.M{"history": models.ClaimHistory{
+                Status:    claim.Status,
+                ChangedAt: now,
+                Event:     "sla_escalated",
+                Note:      note,
+            }}
+        }
+        if _, err := s.claimRepo.UpdateSystem(ctx, tenantID, claim.ID, bson.M{}, update); err != nil {
+            return err
+        }
+        for _, supervisorID := range escalatedTo {
+            s.notify.SLAEscalated(tenantID, supervisorID, claim, note)
+        }
+        return nil
+    })
+}
+
+// slaStatuses lists every status some policy measures.
+func slaStatuses(policies []models.SLAPolicy) []models.ClaimStatus {
+    var out []models.ClaimStatus
+    for _, p := range policies {
+        for _, st := range p.Statuses {
+            if !hasStatus(out, st) {
+                out = append(out, st)
+            }
+        }
+    }
+    return out
+}
+
+// slaChanged reports whether an evaluation moved any deadline or state.
+// ElapsedDays and EvaluatedAt advance on every run and are only persisted
+// alongside a real change.
+func slaChanged(prev, next []models.SLAState) bool {
+    if len(prev) != len(next) {
+        return true
+    }
+    for i := range next {
+        a, b := prev[i], next[i]
+        if a.PolicyID != b.PolicyID || a.State != b.State || !a.StartedAt.Equal(b.StartedAt) || !a.DueAt.Equal(b.DueAt) ||
+            (a.EscalatedAt == nil) != (b.EscalatedAt == nil) {
+            return true
+        }
+    }
+    return false
+}
+
+// escalate marks newly breached SLAs as escalated to the tenant's
+// supervisors and returns a history note and the supervisors to notify, or
+// "" and nil if nothing was escalated.
+func (s *slaService) escalate(tenantID string, states []models.SLAState, now time.Time) (string, []primitive.ObjectID) {
+    var supervisors []primitive.ObjectID
+    note := ""
+    for i := range states {
+        if states[i].State != models.SLABreached || states[i].EscalatedAt != nil {
+            continue
+        }
+        if supervisors == nil {
+            supervisors = s.supervisors(tenantID)
+        }
+        states[i].EscalatedAt = &now
+        states[i].EscalatedTo = supervisors
+        if note != "" {
+            note += "; "
+        }
+        note += fmt.Sprintf("SLA %q breached, escalated to %d supervisor(s)", states[i].Name, len(supervisors))
+    }
+    return note, supervisors
+}
+
+func (s *slaService) supervisors(tenantID string) []primitive.ObjectID {
+    ids := []primitive.ObjectID{}
+    roles, err := s.policy.RolesWith(tenantID, models.PermClaimAssign)
+    if err != nil || len(roles) == 0 {
+        return ids
+    }
+    users, err := s.userRepo.FindByRoles(tenantID, roles)
+    if err != nil {
+        return ids
+    }
+    for _, u := range users {
+        ids = append(ids, u.ID)
+    }
+    return ids
+}
+
+// evaluateSLA computes the state of every policy that applies to the claim's
+// current status and returns them with the worst state as the claim's flag.
+func evaluateSLA(cal *businessCalendar, policies []models.SLAPolicy, claim *models.Claim, now time.Time) ([]models.SLAState, string) {
+    previous := map[primitive.ObjectID]models.SLAState{}
+    for _, st := range claim.SLA {
+        previous[st.PolicyID] = st
+    }
+
+    var states []models.SLAState
+    flag := ""
+    for _, p := range applicablePolicies(policies, claim) {
+        if !hasStatus(p.Statuses, claim.Status) {
+            continue
+        }
+        start := slaStart(claim, p.Statuses)
+        paused := 0.0
+        for _, pause := range claim.SLAPauses {
+            from, to := pause.StartedAt, now
+            if pause.EndedAt != nil {
+                to = *pause.EndedAt
+            }
+            if from.Before(start) {
+                from = start
+            }
+            paused += cal.businessDays(from, to)
+        }
+        elapsed := cal.businessDays(start, now) - paused
+
+        ratio := p.AtRiskRatio
+        if ratio == 0 {
+            ratio = defaultAtRiskRatio
+        }
+        state := models.SLAOnTrack
+        switch {
+        case elapsed >= p.BusinessDays:
+            state = models.SLABreached
+        case elapsed >= ratio*p.BusinessDays:
+            state = models.SLAAtRisk
+        }
+
+        st := models.SLAState{
+            PolicyID:    p.ID,
+            Name:        p.Name,
+            State:       state,
+            StartedAt:   start,
+            DueAt:       cal.addBusinessDays(start, p.BusinessDays+paused),
+            ElapsedDays: elapsed,
+            EvaluatedAt: now,
+        }
+        if prev, ok := previous[p.ID]; ok && prev.StartedAt.Equal(start) {
+            st.EscalatedAt = prev.EscalatedAt
+            st.EscalatedTo = prev.EscalatedTo
+        }
+        states = append(states, st)
+        flag = worseSLAState(flag, state)
+    }
+    return states, flag
+}
+
+// applicablePolicies picks, per policy name, the one for the claim's type,
+// falling back to the catch-all policy without a claim type.
+func applicablePolicies(policies []models.SLAPolicy, claim *models.Claim) []models.SLAPolicy {
+    chosen := map[string]models.SLAPolicy{}
+    var order []string
+    for _, p := range policies {
+        if p.ClaimType != "" && p.ClaimType != claim.ClaimType {
+            continue
+        }
+        current, ok := chosen[p.Name]
+        if !ok {
+            order = append(order, p.Name)
+        }
+        if !ok || (current.ClaimType == "" && p.ClaimType != "") {
+            chosen[p.Name] = p
+        }
+    }
+    out := make([]models.SLAPolicy, 0, len(order))
+    for _, name := range order {
+        out = append(out, chosen[name])
+    }
+    return out
+}
+
+// slaStart finds when the claim entered the current uninterrupted run of
+// statuses, using the status history.
+func slaStart(claim *models.Claim, statuses []models.ClaimStatus) time.Time {
+    var start *time.Time
+    for i := range claim.History {
+        h := claim.History[i]
+        if h.Event != "" {
+            continue
+        }
+        if hasStatus(statuses, h.Status) {
+            if start == nil {
+                start = &claim.History[i].ChangedAt
+            }
+        } else {
+            start = nil
+        }
+    }
+    if start == nil {
+        return claim.UpdatedAt
+    }
+    return *start
+}
+
+func worseSLAState(a, b string) string {
+    rank := map[string]int{"": 0, models.SLAOnTrack: 1, models.SLAAtRisk: 2, models.SLABreached: 3}
+    if rank[b] > rank[a] {
+        return b
+    }
+    return a
+}
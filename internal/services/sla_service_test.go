@@ -0,0 +1,19 @@
+package services
+
+import (
+    "insurance-claims-api/internal/models"
+    "reflect"
+    "testing"
+)
+
+func TestSLAStatusesFollowPolicies(t *testing.T) {
+    policies := []models.SLAPolicy{
+        {Name: "decision", Statuses: []models.ClaimStatus{models.Submitted, models.Reviewed}},
+        {Name: "appeal", Statuses: []models.ClaimStatus{models.Appealed}},
+        {Name: "fraud", Statuses: []models.ClaimStatus{models.FraudReview, models.Reviewed}},
+    }
+    want := []models.ClaimStatus{models.Submitted, models.Reviewed, models.Appealed, models.FraudReview}
+    if got := slaStatuses(policies); !reflect.DeepEqual(got, want) {
+        t.Errorf("got %v, want %v", got, want)
+    }
+}
@@ -0,0 +1,185 @@
+package services
+
+import (
+    "context"
+    "errors"
+    "insurance-claims-api/internal/config"
+    "insurance-claims-api/internal/models"
+    "insurance-claims-api/internal/repositories"
+    "insurance-claims-api/internal/utils"
+    "sync"
+    "time"
+
+    "go.mongodb.org/mongo-driver/mongo"
+)
+
+var (
+    ErrTenantInactive = utils.NewError(utils.KindNotFound, "tenant_not_found", "tenant not found or inactive")
+    ErrTenantExists   = utils.NewError(utils.KindConflict, "tenant_exists", "tenant already exists")
+
+    // Only platform operators may activate or deactivate a tenant.
+    errTenantActiveField = ErrFieldNotEditable.WithFields(utils.FieldError{Field: "active", Code: "read_only", Message: "can only be changed by platform operators"})
+)
+
+type TenantService interface {
+    Resolve(id string) (*models.Tenant, error)
+    CheckActive(id string) error
+    Settings(id string) (models.TenantSettings, error)
+    List() ([]models.Tenant, error)
+    ActiveIDs(ctx context.Context) ([]string, error)
+    Create(req models.CreateTenantRequest) (*models.Tenant, error)
+    Update(actor models.Actor, id string, req models.UpdateTenantRequest) (*models.Tenant, error)
+}
+
+// tenantCacheTTL bounds how long a deactivated tenant's tokens keep working.
+const tenantCacheTTL = 30 * time.Second
+
+type cachedTenant struct {
+    tenant   *models.Tenant
+    err      error
+    loadedAt time.Time
+}
+
+type tenantService struct {
+    tenantRepo repositories.TenantRepository
+
+    mu     sync.RWMutex
+    status map[string]cachedTenant
+}
+
+func NewTenantService(tenantRepo repositories.TenantRepository) TenantService {
+    return &tenantService{tenantRepo: tenantRepo, status: map[string]cachedTenant{}}
+}
+
+// Resolve returns an active tenant. The default tenant exists implicitly so
+// single-insurer deployments work without any tenant documents.
+func (s *tenantService) Resolve(id string) (*models.Tenant, error) {
+    tenant, err := s.tenantRepo.FindByID(id)
+    if errors.Is(err, mongo.ErrNoDocuments) && id == config.AppConfig.DefaultTenant {
+        return &models.Tenant{ID: id, Name: id, Active: true}, nil
+    }
+    if err != nil {
+        if errors.Is(err, mongo.ErrNoDocuments) {
+            return nil, ErrTenantInactive
+        }
+        return nil, err
+    }
+    if !tenant.Active {
+        return nil, ErrTenantInactive
+    }
+    return tenant, nil
+}
+
+// CheckActive is Resolve for every authenticated request, so its result is
+// cached. Errors other than an inactive tenant are not cached.
+func (s *tenantService) CheckActive(id string) error {
+    _, err := s.cached(id)
+    return err
+}
+
+// Settings returns the tenant's configuration overrides, from the same
+// cache as CheckActive.
+func (s *tenantService) Settings(id string) (models.TenantSettings, error) {
+    tenant, err := s.cached(id)
+    if err != nil {
+        return models.TenantSettings{}, err
+    }
+    return tenant.Settings, nil
+}
+
+func (s *tenantService) cached(id string) (*models.Tenant, error) {
+    s.mu.RLock()
+    cached, ok := s.status[id]
+    s.mu.RUnlock()
+    if ok && time.Since(cached.loadedAt) < tenantCacheTTL {
+        return cached.tenant, cached.err
+    }
+    tenant, err := s.Resolve(id)
+    if err != nil && !errors.Is(err, ErrTenantInactive) {
+        return nil, err
+    }
+    s.mu.Lock()
+    s.status[id] = cachedTenant{tenant: tenant, err: err, loadedAt: time.Now()}
+    s.mu.Unlock()
+    return tenant, err
+}
+
+func (s *tenantService) List() ([]models.Tenant, error) {
+    return s.tenantRepo.FindAll(context.TODO())
+}
+
+// ActiveIDs lists every tenant background jobs should visit, including the
+// implicit default tenant.
+func (s *tenantService) ActiveIDs(ctx context.Context) ([]string, error) {
+    tenants, err := s.tenantRepo.FindAll(ctx)
+    if err != nil {
+        return nil, err
+    }
+    ids := []string{}
+    hasDefault := false
+    for _, t := range tenants {
+        if t.ID == config.AppConfig.DefaultTenant {
+            hasDefault = true
+        }
+        if t.Active {
+            ids = append(ids, t.ID)
+        }
+    }
+    if !hasDefault {
+        ids = append(ids, config.AppConfig.DefaultTenant)
+    }
+    return ids, nil
+}
+
+func (s *tenantService) Create(req models.CreateTenantRequest) (*models.Tenant, error) {
+    tenant := &models.Tenant{
+        ID:       req.ID,
+        Name:     req.Name,
+        Active:   true,
+        Settings: req.Settings,
+    }
+    if err := s.tenantRepo.Create(tenant); err != nil {
+        if mongo.IsDuplicateKeyError(err) {
+            return nil, ErrTenantExists
+        }
+        return nil, err
+    }
+    return tenant, nil
+}
+
+// Update changes a tenant. Deactivating a tenant locks out all of its users,
+// so Active is reserved for platform operators.
+func (s *tenantService) Update(actor models.Actor, id string, req models.UpdateTenantRequest) (*models.Tenant, error) {
+    if req.Active != nil && !actor.Can(models.PermTenantCreate) {
+        return nil, errTenantActiveField
+    }
+    tenant, err := s.tenantRepo.FindByID(id)
+    if errors.Is(err, mongo.ErrNoDocuments) && id == config.AppConfig.DefaultTenant {
+        tenant = &models.Tenant{ID: id, Name: id, Active: true}
+        if err = s.tenantRepo.Create(tenant); err != nil {
+            return nil, err
+        }
+    }
+    if errors.Is(err, mongo.ErrNoDocuments) {
+        return nil, ErrTenantInactive
+    }
+    if err != nil {
+        return nil, err
+    }
+    if req.Name != "" {
+        tenant.Name = req.Name
+    }
+    if req.Active != nil {
+        tenant.Active = *req.Active
+    }
+    if req.Settings != nil {
+        tenant.Settings = *req.Settings
+    }
+    if err := s.tenantRepo.Update(tenant); err != nil {
+        return nil, err
+    }
+    s.mu.Lock()
+    delete(s.status, id)
+    s.mu.Unlock()
+    return tenant, nil
+}
@@ -0,0 +1,177 @@
+package utils
+
+import (
+    "errors"
+    "log"
+    "net/http"
+    "strings"
+
+    "go.mongodb.org/mongo-driver/mongo"
+)
+
+// ErrorKind classifies an Error and decides its HTTP status.
+type ErrorKind int
+
+const (
+    KindInternal ErrorKind = iota
+    KindBadRequest
+    KindUnauthorized
+    KindForbidden
+    KindNotFound
+    KindConflict
+    KindPreconditionFailed
+    KindPreconditionRequired
+    KindUnsupportedMediaType
+    KindTooLarge
+    KindValidation
+    KindUnavailable
+)
+
+var kindStatus = map[ErrorKind]int{
+    KindInternal:             http.StatusInternalServerError,
+    KindBadRequest:           http.StatusBadRequest,
+    KindUnauthorized:         http.StatusUnauthorized,
+    KindForbidden:            http.StatusForbidden,
+    KindNotFound:             http.StatusNotFound,
+    KindConflict:             http.StatusConflict,
+    KindPreconditionFailed:   http.StatusPreconditionFailed,
+    KindPreconditionRequired: http.StatusPreconditionRequired,
+    KindUnsupportedMediaType: http.StatusUnsupportedMediaType,
+    KindTooLarge:             http.StatusRequestEntityTooLarge,
+    KindValidation:           http.StatusUnprocessableEntity,
+    KindUnavailable:          http.StatusServiceUnavailable,
+}
+
+// Status is the HTTP status errors of kind k are reported with.
+func (k ErrorKind) Status() int {
+    return kindStatus[k]
+}
+
+// Generic codes, used when nothing more specific applies.
+const (
+    CodeInternal             = "internal_error"
+    CodeBadRequest           = "bad_request"
+    CodeUnauthorized         = "unauthorized"
+    CodeForbidden            = "forbidden"
+    CodeNotFound             = "not_found"
+    CodeConflict             = "conflict"
+    CodePreconditionFailed   = "precondition_failed"
+    CodePreconditionRequired = "precondition_required"
+    CodeUnsupportedMediaType = "unsupported_media_type"
+    CodeTooLarge             = "request_too_large"
+    CodeValidation           = "validation_failed"
+    CodeUnavailable          = "service_unavailable"
+)
+
+var statusCode = map[int]string{
+    http.StatusBadRequest:            CodeBadRequest,
+    http.StatusUnauthorized:          CodeUnauthorized,
+    http.StatusForbidden:             CodeForbidden,
+    http.StatusNotFound:              CodeNotFound,
+    http.StatusConflict:              CodeConflict,
+    http.StatusPreconditionFailed:    CodePreconditionFailed,
+    http.StatusPreconditionRequired:  CodePreconditionRequired,
+    http.StatusUnsupportedMediaType:  CodeUnsupportedMediaType,
+    http.StatusRequestEntityTooLarge: CodeTooLarge,
+    http.StatusUnprocessableEntity:   CodeValidation,
+    http.StatusServiceUnavailable:    CodeUnavailable,
+}
+
+// Error is a domain error with a stable, machine-readable code. Codes are
+// part of the API contract: clients switch on them, so they are never
+// renamed. Two Errors match with errors.Is when their codes are equal, which
+// lets WithMessage refine a sentinel without breaking checks against it.
+type Error struct {
+    Kind    ErrorKind
+    Code    string
+    Message string
+    Fields  []FieldError
+    Err     error
+
+    // Data is extra payload for the client, e.g. the claims a duplicate
+    // matched.
+    Data interface{}
+}
+
+// FieldError explains why one request field was rejected. Field is the JSON
+// path, e.g. "claim_amount" or "history[0].status".
+type FieldError struct {
+    Field   string `json:"field"`
+    Code    string `json:"code"`
+    Message string `json:"message"`
+}
+
+func NewError(kind ErrorKind, code, message string) *Error {
+    return &Error{Kind: kind, Code: code, Message: message}
+}
+
+func (e *Error) Error() string {
+    if len(e.Fields) == 0 {
+        return e.Message
+    }
+    parts := make([]string, len(e.Fields))
+    for i, f := range e.Fields {
+        parts[i] = f.Field + " " + f.Message
+    }
+    return e.Message + ": " + strings.Join(parts, "; ")
+}
+
+func (e *Error) Unwrap() error {
+    return e.Err
+}
+
+func (e *Error) Is(target error) bool {
+    t, ok := target.(*Error)
+    return ok && t.Code == e.Code
+}
+
+// WithMessage returns a copy of e with a more specific message.
+func (e *Error) WithMessage(message string) *Error {
+    out := *e
+    out.Message = message
+    return &out
+}
+
+// WithFields returns a copy of e reporting fields.
+func (e *Error) WithFields(fields ...FieldError) *Error {
+    out := *e
+    out.Fields = fields
+    return &out
+}
+
+// ValidationError reports rejected fields.
+func ValidationError(fields ...FieldError) *Error {
+    return &Error{Kind: KindValidation, Code: CodeValidation, Message: "validation failed", Fields: fields}
+}
+
+// Classify maps err to a domain error. Domain errors keep their own kind;
+// database errors are classified by what went wrong: a missing document is
+// not found, a duplicate key a conflict and a database that cannot be
+// reached or answers too slowly is unavailable. Other errors return nil.
+func Classify(err error) *Error {
+    var e *Error
+    switch {
+    case err == nil:
+        return nil
+    case errors.As(err, &e):
+        return e
+    case errors.Is(err, mongo.ErrNoDocuments):
+        return &Error{Kind: KindNotFound, Code: CodeNotFound, Message: "not found", Err: err}
+    case mongo.IsDuplicateKeyError(err):
+        return &Error{Kind: KindConflict, Code: CodeConflict, Message: "already exists", Err: err}
+    case mongo.IsTimeout(err), mongo.IsNetworkError(err), errors.Is(err, mongo.ErrClientDisconnected):
+        return &Error{Kind: KindUnavailable, Code: CodeUnavailable, Message: "database unavailable, try again later", Err: err}
+    }
+    return nil
+}
+
+// Public returns err in a form that may be shown to a client, e.g. in a
+// per-item batch result: classified errors as they are, anything else as a
+// generic internal error whose text only goes to the log.
+func Public(err error) *Error {
+    if e := Classify(err); e != nil {
+        return e
+    }
+    log.Println("unclassified error:", err)
+    return &Error{Kind: KindInternal, Code: CodeInternal, Message: "internal server error"}
+}
@@ -0,0 +1,34 @@
+package utils
+
+import (
+    "errors"
+    "strings"
+    "testing"
+
+    "go.mongodb.org/mongo-driver/mongo"
+)
+
+func TestPublic(t *testing.T) {
+    domain := NewError(KindConflict, "invalid_transition", "the claim's status does not allow this action")
+    tests := []struct {
+        name string
+        err  error
+        code string
+    }{
+        {"domain error", domain, "invalid_transition"},
+        {"missing document", mongo.ErrNoDocuments, CodeNotFound},
+        {"database down", mongo.ErrClientDisconnected, CodeUnavailable},
+        {"unclassified", errors.New("dial tcp 10.0.0.5:27017: connection refused"), CodeInternal},
+    }
+    for _, tt := range tests {
+        t.Run(tt.name, func(t *testing.T) {
+            e := Public(tt.err)
+            if e.Code != tt.code {
+                t.Errorf("code = %s, want %s", e.Code, tt.code)
+            }
+            if strings.Contains(e.Error(), "10.0.0.5") {
+                t.Errorf("message leaks internals: %s", e.Error())
+            }
+        })
+    }
+}
@@ -0,0 +1,295 @@
+package utils
+
+import (
+    "encoding/json"
+    "errors"
+    "fmt"
+    "reflect"
+    "strconv"
+    "strings"
+)
+
+// ApplyMergePatch applies an RFC 7396 JSON Merge Patch to doc: members of
+// patch replace those of doc, objects are merged recursively and null
+// removes a member.
+func ApplyMergePatch(doc, patch []byte) ([]byte, error) {
+    var target, p interface{}
+    if err := json.Unmarshal(doc, &target); err != nil {
+        return nil, err
+    }
+    if err := json.Unmarshal(patch, &p); err != nil {
+        return nil, fmt.Errorf("invalid merge patch: %w", err)
+    }
+    return json.Marshal(mergePatch(target, p))
+}
+
+func mergePatch(target, patch interface{}) interface{} {
+    p, ok := patch.(map[string]interface{})
+    if !ok {
+        return patch
+    }
+    t, ok := target.(map[string]interface{})
+    if !ok {
+        t = map[string]interface{}{}
+    }
+    for k, v := range p {
+        if v == nil {
+            delete(t, k)
+        } else {
+            t[k] = mergePatch(t[k], v)
+        }
+    }
+    return t
+}
+
+// JSONPatchOperation is one step of an RFC 6902 JSON Patch.
+type JSONPatchOperation struct {
+    Op    string          `json:"op"`
+    Path  string          `json:"path"`
+    From  string          `json:"from,omitempty"`
+    Value json.RawMessage `json:"value,omitempty"`
+}
+
+// ApplyJSONPatch applies an RFC 6902 JSON Patch to doc. Operations run in
+// order and the first failing one, including a failed "test", aborts the
+// whole patch.
+func ApplyJSONPatch(doc, patch []byte) ([]byte, error) {
+    var target interface{}
+    if err := json.Unmarshal(doc, &target); err != nil {
+        return nil, err
+    }
+    var ops []JSONPatchOperation
+    if err := json.Unmarshal(patch, &ops); err != nil {
+        return nil, fmt.Errorf("invalid JSON patch: %w", err)
+    }
+    for i, op := range ops {
+        var err error
+        if target, err = applyPatchOperation(target, op); err != nil {
+            return nil, fmt.Errorf("operation %d (%s %s): %w", i, op.Op, op.Path, err)
+        }
+    }
+    return json.Marshal(target)
+}
+
+func applyPatchOperation(doc interface{}, op JSONPatchOperation) (interface{}, error) {
+    path, err := parsePointer(op.Path)
+    if err != nil {
+        return nil, err
+    }
+    var value interface{}
+    switch op.Op {
+    case "add", "replace", "test":
+        if op.Value == nil {
+            return nil, errors.New("value is required")
+        }
+        if err := json.Unmarshal(op.Value, &value); err != nil {
+            return nil, err
+        }
+    }
+
+    switch op.Op {
+    case "add":
+        return pointerAdd(doc, path, value)
+    case "remove":
+        doc, _, err = pointerRemove(doc, path)
+        return doc, err
+    case "replace":
+        if doc, _, err = pointerRemove(doc, path); err != nil {
+            return nil, err
+        }
+        return pointerAdd(doc, path, value)
+    case "move", "copy":
+        from, err := parsePointer(op.From)
+        if err != nil {
+            return nil, err
+        }
+        if op.Op == "move" {
+            if isPrefix(from, path) && len(from) < len(path) {
+                return nil, errors.New("cannot move a value into itself")
+            }
+            doc, value, err = pointerRemove(doc, from)
+        } else {
+            value, err = pointerGet(doc, from)
+            value = deepCopy(value)
+        }
+        if err != nil {
+            return nil, err
+        }
+        return pointerAdd(doc, path, value)
+    case "test":
+        current, err := pointerGet(doc, path)
+        if err != nil {
+            return nil, err
+        }
+        if !reflect.DeepEqual(current, value) {
+            return nil, errors.New("test failed")
+        }
+        return doc, nil
+    }
+    return nil, fmt.Errorf("unknown operation %q", op.Op)
+}
+
+// parsePointer splits an RFC 6901 JSON Pointer; "" is the whole document.
+func parsePointer(pointer string) ([]string, error) {
+    if pointer == "" {
+        return nil, nil
+    }
+    if !strings.HasPrefix(pointer, "/") {
+        return nil, fmt.Errorf("invalid JSON pointer %q", pointer)
+    }
+    tokens := strings.Split(pointer[1:], "/")
+    for i, t := range tokens {
+        tokens[i] = strings.ReplaceAll(strings.ReplaceAll(t, "~1", "/"), "~0", "~")
+    }
+    return tokens, nil
+}
+
+func isPrefix(prefix, path []string) bool {
+    if len(prefix) > len(path) {
+        return false
+    }
+    for i := range prefix {
+        if prefix[i] != path[i] {
+            return false
+        }
+    }
+    return true
+}
+
+func arrayIndex(token string, length int, allowEnd bool) (int, error) {
+    if allowEnd && token == "-" {
+        return length, nil
+    }
+    i, err := strconv.Atoi(token)
+    if err != nil || i < 0 || (token != "0" && strings.HasPrefix(token, "0")) {
+        return 0, fmt.Errorf("invalid array index %q", token)
+    }
+    if i > length || (i == length && !allowEnd) {
+        return 0, fmt.Errorf("array index %d out of range", i)
+    }
+    return i, nil
+}
+
+func pointerGet(doc interface{}, path []string) (interface{}, error) {
+    for _, token := range path {
+        switch c := doc.(type) {
+        case map[string]interface{}:
+            v, ok := c[token]
+            if !ok {
+                return nil, fmt.Errorf("member %q does not exist", token)
+            }
+            doc = v
+        case []interface{}:
+            i, err := arrayIndex(token, len(c), false)
+            if err != nil {
+                return nil, err
+            }
+            doc = c[i]
+        default:
+            return nil, fmt.Errorf("cannot index %q into a scalar", token)
+        }
+    }
+    return doc, nil
+}
+
+// pointerUpdate replaces the container holding the last token of path with
+// the result of fn, and returns the updated document.
+func pointerUpdate(doc interface{}, path []string, fn func(container interface{}, token string) (interface{}, error)) (interface{}, error) {
+    if len(path) == 1 {
+        return fn(doc, path[0])
+    }
+    switch c := doc.(type) {
+    case map[string]interface{}:
+        child, ok := c[path[0]]
+        if !ok {
+            return nil, fmt.Errorf("member %q does not exist", path[0])
+        }
+        v, err := pointerUpdate(child, path[1:], fn)
+        if err != nil {
+            return nil, err
+        }
+        c[path[0]] = v
+        return c, nil
+    case []interface{}:
+        i, err := arrayIndex(path[0], len(c), false)
+        if err != nil {
+            return nil, err
+        }
+        v, err := pointerUpdate(c[i], path[1:], fn)
+        if err != nil {
+            return nil, err
+        }
+        c[i] = v
+        return c, nil
+    }
+    return nil, fmt.Errorf("cannot index %q into a scalar", path[0])
+}
+
+func pointerAdd(doc interface{}, path []string, value interface{}) (interface{}, error) {
+    if len(path) == 0 {
+        return value, nil
+    }
+    return pointerUpdate(doc, path, func(container interface{}, token string) (interface{}, error) {
+        switch c := container.(type) {
+        case map[string]interface{}:
+            c[token] = value
+            return c, nil
+        case []interface{}:
+            i, err := arrayIndex(token, len(c), true)
+            if err != nil {
+                return nil, err
+            }
+            c = append(c, nil)
+            copy(c[i+1:], c[i:])
+            c[i] = value
+            return c, nil
+        }
+        return nil, fmt.Errorf("cannot add %q to a scalar", token)
+    })
+}
+
+func pointerRemove(doc interface{}, path []string) (interface{}, interface{}, error) {
+    if len(path) == 0 {
+        return nil, nil, errors.New("cannot remove the whole document")
+    }
+    var removed interface{}
+    doc, err := pointerUpdate(doc, path, func(container interface{}, token string) (interface{}, error) {
+        switch c := container.(type) {
+        case map[string]interface{}:
+            v, ok := c[token]
+            if !ok {
+                return nil, fmt.Errorf("member %q does not exist", token)
+            }
+            removed = v
+            delete(c, token)
+            return c, nil
+        case []interface{}:
+            i, err := arrayIndex(token, len(c), false)
+            if err != nil {
+                return nil, err
+            }
+            removed = c[i]
+            return append(c[:i], c[i+1:]...), nil
+        }
+        return nil, fmt.Errorf("cannot remove %q from a scalar", token)
+    })
+    return doc, removed, err
+}
+
+func deepCopy(v interface{}) interface{} {
+    switch c := v.(type) {
+    case map[string]interface{}:
+        out := make(map[string]interface{}, len(c))
+        for k, e := range c {
+            out[k] = deepCopy(e)
+        }
+        return out
+    case []interface{}:
+        out := make([]interface{}, len(c))
+        for i, e := range c {
+            out[i] = deepCopy(e)
+        }
+        return out
+    }
+    return v
+}
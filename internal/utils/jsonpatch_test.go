@@ -0,0 +1,109 @@
+package utils
+
+import (
+    "encoding/json"
+    "reflect"
+    "testing"
+)
+
+func jsonEqual(t *testing.T, got []byte, want string) bool {
+    t.Helper()
+    var g, w interface{}
+    if err := json.Unmarshal(got, &g); err != nil {
+        t.Fatalf("result is not JSON: %v", err)
+    }
+    if err := json.Unmarshal([]byte(want), &w); err != nil {
+        t.Fatalf("bad expectation %s: %v", want, err)
+    }
+    return reflect.DeepEqual(g, w)
+}
+
+func TestApplyJSONPatch(t *testing.T) {
+    doc := `{"a": 1, "list": ["x", "y"], "obj": {"b": 2}, "a/b": 3, "m~n": 4}`
+    tests := []struct {
+        name  string
+        patch string
+        want  string // empty when the patch must fail
+    }{
+        {"add member", `[{"op": "add", "path": "/c", "value": true}]`,
+            `{"a": 1, "c": true, "list": ["x", "y"], "obj": {"b": 2}, "a/b": 3, "m~n": 4}`},
+        {"add replaces existing member", `[{"op": "add", "path": "/a", "value": 9}]`,
+            `{"a": 9, "list": ["x", "y"], "obj": {"b": 2}, "a/b": 3, "m~n": 4}`},
+        {"add inserts into array", `[{"op": "add", "path": "/list/1", "value": "z"}]`,
+            `{"a": 1, "list": ["x", "z", "y"], "obj": {"b": 2}, "a/b": 3, "m~n": 4}`},
+        {"add appends with -", `[{"op": "add", "path": "/list/-", "value": "z"}]`,
+            `{"a": 1, "list": ["x", "y", "z"], "obj": {"b": 2}, "a/b": 3, "m~n": 4}`},
+        {"add to missing parent", `[{"op": "add", "path": "/nope/c", "value": 1}]`, ""},
+        {"add without value", `[{"op": "add", "path": "/c"}]`, ""},
+        {"add past end of array", `[{"op": "add", "path": "/list/3", "value": "z"}]`, ""},
+        {"remove member", `[{"op": "remove", "path": "/obj/b"}]`,
+            `{"a": 1, "list": ["x", "y"], "obj": {}, "a/b": 3, "m~n": 4}`},
+        {"remove array element", `[{"op": "remove", "path": "/list/0"}]`,
+            `{"a": 1, "list": ["y"], "obj": {"b": 2}, "a/b": 3, "m~n": 4}`},
+        {"remove missing member", `[{"op": "remove", "path": "/nope"}]`, ""},
+        {"remove with -", `[{"op": "remove", "path": "/list/-"}]`, ""},
+        {"replace", `[{"op": "replace", "path": "/obj/b", "value": [1]}]`,
+            `{"a": 1, "list": ["x", "y"], "obj": {"b": [1]}, "a/b": 3, "m~n": 4}`},
+        {"replace missing member", `[{"op": "replace", "path": "/nope", "value": 1}]`, ""},
+        {"move", `[{"op": "move", "from": "/obj/b", "path": "/list/0"}]`,
+            `{"a": 1, "list": [2, "x", "y"], "obj": {}, "a/b": 3, "m~n": 4}`},
+        {"move into itself", `[{"op": "move", "from": "/obj", "path": "/obj/inner"}]`, ""},
+        {"copy", `[{"op": "copy", "from": "/obj", "path": "/copy"}, {"op": "add", "path": "/copy/c", "value": 1}]`,
+            `{"a": 1, "list": ["x", "y"], "obj": {"b": 2}, "copy": {"b": 2, "c": 1}, "a/b": 3, "m~n": 4}`},
+        {"test passes", `[{"op": "test", "path": "/list", "value": ["x", "y"]}, {"op": "remove", "path": "/a"}]`,
+            `{"list": ["x", "y"], "obj": {"b": 2}, "a/b": 3, "m~n": 4}`},
+        {"test fails and aborts", `[{"op": "remove", "path": "/a"}, {"op": "test", "path": "/obj/b", "value": 3}]`, ""},
+        {"~1 escapes slash", `[{"op": "replace", "path": "/a~1b", "value": 30}]`,
+            `{"a": 1, "list": ["x", "y"], "obj": {"b": 2}, "a/b": 30, "m~n": 4}`},
+        {"~0 escapes tilde", `[{"op": "remove", "path": "/m~0n"}]`,
+            `{"a": 1, "list": ["x", "y"], "obj": {"b": 2}, "a/b": 3}`},
+        {"leading zero index", `[{"op": "remove", "path": "/list/01"}]`, ""},
+        {"pointer without slash", `[{"op": "remove", "path": "a"}]`, ""},
+        {"unknown operation", `[{"op": "merge", "path": "/a", "value": 1}]`, ""},
+    }
+    for _, tt := range tests {
+        t.Run(tt.name, func(t *testing.T) {
+            got, err := ApplyJSONPatch([]byte(doc), []byte(tt.patch))
+            if tt.want == "" {
+                if err == nil {
+                    t.Fatalf("expected an error, got %s", got)
+                }
+                return
+            }
+            if err != nil {
+                t.Fatal(err)
+            }
+            if !jsonEqual(t, got, tt.want) {
+                t.Errorf("got %s, want %s", got, tt.want)
+            }
+        })
+    }
+}
+
+func TestApplyMergePatch(t *testing.T) {
+    tests := []struct {
+        name, doc, patch, want string
+    }{
+        {"replace member", `{"a": 1, "b": 2}`, `{"a": 3}`, `{"a": 3, "b": 2}`},
+        {"null deletes member", `{"a": 1, "b": 2}`, `{"b": null}`, `{"a": 1}`},
+        {"null for missing member", `{"a": 1}`, `{"c": null}`, `{"a": 1}`},
+        {"nested merge", `{"o": {"x": 1, "y": 2}}`, `{"o": {"y": null, "z": 3}}`, `{"o": {"x": 1, "z": 3}}`},
+        {"arrays are replaced", `{"l": [1, 2]}`, `{"l": [3]}`, `{"l": [3]}`},
+        {"object replaces scalar", `{"a": 1}`, `{"a": {"b": null, "c": 1}}`, `{"a": {"c": 1}}`},
+        {"non-object patch replaces document", `{"a": 1}`, `["x"]`, `["x"]`},
+    }
+    for _, tt := range tests {
+        t.Run(tt.name, func(t *testing.T) {
+            got, err := ApplyMergePatch([]byte(tt.doc), []byte(tt.patch))
+            if err != nil {
+                t.Fatal(err)
+            }
+            if !jsonEqual(t, got, tt.want) {
+                t.Errorf("got %s, want %s", got, tt.want)
+            }
+        })
+    }
+    if _, err := ApplyMergePatch([]byte(`{}`), []byte(`{`)); err == nil {
+        t.Error("expected an error for an invalid patch")
+    }
+}
@@ -0,0 +1,130 @@
+package utils
+
+import (
+    "bytes"
+    "fmt"
+    "io"
+    "strings"
+)
+
+const (
+    pdfPageWidth  = 595.0 // A4 in points
+    pdfPageHeight = 842.0
+    pdfMargin     = 56.0
+)
+
+// PDFWriter lays out simple text documents: headings and wrapped paragraphs
+// in the built-in Helvetica fonts, so no font files are embedded. Text is
+// encoded as WinAnsi; characters outside it print as "?".
+type PDFWriter struct {
+    pages []*bytes.Buffer
+    y     float64
+}
+
+func NewPDFWriter() *PDFWriter {
+    p := &PDFWriter{}
+    p.newPage()
+    return p
+}
+
+func (p *PDFWriter) newPage() {
+    p.pages = append(p.pages, &bytes.Buffer{})
+    p.y = pdfPageHeight - pdfMargin
+}
+
+func (p *PDFWriter) line(font string, size float64, text string) {
+    if p.y-size < pdfMargin {
+        p.newPage()
+    }
+    p.y -= size * 1.4
+    fmt.Fprintf(p.pages[len(p.pages)-1], "BT /%s %.1f Tf %.1f %.1f Td (%s) Tj ET\n", font, size, pdfMargin, p.y, pdfEscape(text))
+}
+
+// Heading writes a bold line; level 1 is the document title.
+func (p *PDFWriter) Heading(level int, text string) {
+    size := 11.0
+    if level == 1 {
+        size = 16
+    }
+    p.line("F2", size, text)
+}
+
+// Paragraph writes text wrapped to the page width. Helvetica averages about
+// half an em per character, which is close enough for letters.
+func (p *PDFWriter) Paragraph(text string) {
+    size := 10.0
+    maxChars := int((pdfPageWidth - 2*pdfMargin) / size / 0.52)
+    var line string
+    for _, word := range strings.Fields(text) {
+        if line != "" && len(line)+1+len(word) > maxChars {
+            p.line("F1", size, line)
+            line = ""
+        }
+        if line != "" {
+            line += " "
+        }
+        line += word
+    }
+    p.line("F1", size, line)
+}
+
+// Space adds a blank line.
+func (p *PDFWriter) Space() {
+    p.y -= 8
+}
+
+// WriteTo writes the finished document.
+func (p *PDFWriter) WriteTo(w io.Writer) (int64, error) {
+    var buf bytes.Buffer
+    var offsets []int
+    obj := func(body string) {
+        offsets = append(offsets, buf.Len())
+        fmt.Fprintf(&buf, "%d 0 obj\n%s\nendobj\n", len(offsets), body)
+    }
+
+    buf.WriteString("%PDF-1.4\n")
+    kids := make([]string, len(p.pages))
+    for i := range p.pages {
+        kids[i] = fmt.Sprintf("%d 0 R", 5+2*i)
+    }
+    obj("<< /Type /Catalog /Pages 2 0 R >>")
+    obj(fmt.Sprintf("<< /Type /Pages /Kids [%s] /Count %d >>", strings.Join(kids, " "), len(p.pages)))
+    obj("<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica /Encoding /WinAnsiEncoding >>")
+    obj("<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica-Bold /Encoding /WinAnsiEncoding >>")
+    for i, page := range p.pages {
+        obj(fmt.Sprintf("<< /Type /Page /Parent 2 0 R /MediaBox [0 0 %.0f %.0f] /Resources << /Font << /F1 3 0 R /F2 4 0 R >> >> /Contents %d 0 R >>",
+            pdfPageWidth, pdfPageHeight, 6+2*i))
+        obj(fmt.Sprintf("<< /Length %d >>\nstream\n%sendstream", page.Len(), page.String()))
+    }
+
+    xref := buf.Len()
+    fmt.Fprintf(&buf, "xref\n0 %d\n0000000000 65535 f \n", len(offsets)+1)
+    for _, off := range offsets {
+        fmt.Fprintf(&buf, "%010d 00000 n \n", off)
+    }
+    fmt.Fprintf(&buf, "trailer\n<< /Size %d /Root 1 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(offsets)+1, xref)
+    return buf.WriteTo(w)
+}
+
+// pdfEscape converts text to a WinAnsi string literal body.
+func pdfEscape(text string) string {
+    var b strings.Builder
+    for _, r := range text {
+        switch {
+        case r == '(' || r == ')' || r == '\\':
+            b.WriteByte('\\')
+            b.WriteRune(r)
+        case r >= 32 && r < 127:
+            b.WriteRune(r)
+        case r >= 160 && r <= 255:
+            fmt.Fprintf(&b, "\\%03o", r)
+        case r == '€':
+            b.WriteString("\\200")
+        case r == '–' || r == '—':
+            b.WriteByte('-')
+        default:
+            b.WriteByte('?')
+        }
+    }
+    return b.String()
+}
@@ -1,6 +1,12 @@
 package utils
 
-import "github.com/gin-gonic/gin"
+import (
+    "encoding/json"
+    "log"
+    "net/http"
+
+    "github.com/gin-gonic/gin"
+)
 
 type Response struct {
     Success bool        `json:"success"`
@@ -13,8 +19,57 @@ func SuccessResponse(c *gin.Context, data interface{}) {
     c.JSON(200, Response{Success: true, Data: data})
 }
 
-func ErrorResponse(c *gin.Context, code int, err string) {
-    c.JSON(code, Response{Success: false, Error: err})
+// Problem is an RFC 7807 problem details body. Success and Error repeat the
+// older error envelope so existing clients keep working.
+type Problem struct {
+    Type     string       `json:"type"`
+    Title    string       `json:"title"`
+    Status   int          `json:"status"`
+    Detail   string       `json:"detail,omitempty"`
+    Instance string       `json:"instance,omitempty"`
+    Code     string       `json:"code"`
+    Errors   []FieldError `json:"errors,omitempty"`
+    Data     interface{}  `json:"data,omitempty"`
+    Success  bool         `json:"success"`
+    Error    string       `json:"error,omitempty"`
+}
+
+const ProblemContentType = "application/problem+json"
+
+// ProblemResponse reports err as problem details. Errors Classify
+// recognises carry their own code and status; any other error is logged and
+// reported with fallback. A server-error fallback gets a generic detail, so
+// driver and I/O messages never reach the client.
+func ProblemResponse(c *gin.Context, err error, fallback int) {
+    if e := Classify(err); e != nil {
+        writeProblem(c, e, e.Kind.Status())
+        return
+    }
+    log.Printf("%s %s: %v", c.Request.Method, c.Request.URL.Path, err)
+    if fallback >= http.StatusInternalServerError {
+        writeProblem(c, &Error{Code: CodeInternal, Message: "internal server error"}, fallback)
+        return
+    }
+    writeProblem(c, &Error{Code: statusCode[fallback], Message: err.Error()}, fallback)
+}
+
+func writeProblem(c *gin.Context, e *Error, status int) {
+    code := e.Code
+    if code == "" {
+        code = CodeInternal
+    }
+    problem := Problem{
+        Type:     "urn:claims-api:problem:" + code,
+        Title:    http.StatusText(status),
+        Status:   status,
+        Detail:   e.Error(),
+        Instance: c.Request.URL.Path,
+        Code:     code,
+        Errors:   e.Fields,
+        Data:     e.Data,
+        Error:    e.Error(),
+    }
+    c.Render(status, problemRender{problem})
 }
 
 func PaginatedResponse(c *gin.Context, data interface{}, total int64, page, limit int) {
@@ -29,4 +84,17 @@ func PaginatedResponse(c *gin.Context, data interface{}, total int64, page, limi
             },
         },
     })
-}
\ No newline at end of file
+}
+
+type problemRender struct {
+    problem Problem
+}
+
+func (r problemRender) Render(w http.ResponseWriter) error {
+    r.WriteContentType(w)
+    return json.NewEncoder(w).Encode(r.problem)
+}
+
+func (r problemRender) WriteContentType(w http.ResponseWriter) {
+    w.Header().Set("Content-Type", ProblemContentType)
+}
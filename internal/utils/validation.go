@@ -0,0 +1,139 @@
+package utils
+
+import (
+    "encoding/json"
+    "errors"
+    "fmt"
+    "reflect"
+    "regexp"
+    "strings"
+    "unicode"
+
+    "github.com/gin-gonic/gin/binding"
+    "github.com/go-playground/validator/v10"
+)
+
+// RegisterValidations names fields by their JSON names in validation errors
+// and adds the policy_number rule, which checks policyPattern.
+func RegisterValidations(policyPattern string) error {
+    re, err := regexp.Compile(policyPattern)
+    if err != nil {
+        return fmt.Errorf("invalid policy number pattern: %w", err)
+    }
+    v, ok := binding.Validator.Engine().(*validator.Validate)
+    if !ok {
+        return errors.New("unexpected validator engine")
+    }
+    v.RegisterTagNameFunc(func(f reflect.StructField) string {
+        name := strings.Split(f.Tag.Get("json"), ",")[0]
+        if name == "-" {
+            return ""
+        }
+        if name == "" {
+            return f.Name
+        }
+        return name
+    })
+    return v.RegisterValidation("policy_number", func(fl validator.FieldLevel) bool {
+        return re.MatchString(fl.Field().String())
+    })
+}
+
+// BindingError turns an error from binding or validating a request into a
+// domain error: rule violations and mistyped fields become field-level
+// validation errors, anything else a bad request.
+func BindingError(err error) *Error {
+    var domain *Error
+    if errors.As(err, &domain) {
+        return domain
+    }
+    var invalid validator.ValidationErrors
+    if errors.As(err, &invalid) {
+        fields := make([]FieldError, len(invalid))
+        for i, fe := range invalid {
+            fields[i] = fieldError(fe)
+        }
+        return ValidationError(fields...)
+    }
+    var typeErr *json.UnmarshalTypeError
+    if errors.As(err, &typeErr) && typeErr.Field != "" {
+        return ValidationError(FieldError{
+            Field:   typeErr.Field,
+            Code:    "invalid_type",
+            Message: "must be a " + jsonTypeName(typeErr.Type),
+        })
+    }
+    return &Error{Kind: KindBadRequest, Code: CodeBadRequest, Message: err.Error(), Err: err}
+}
+
+func fieldError(fe validator.FieldError) FieldError {
+    // The namespace starts with the struct name and includes embedded
+    // structs, e.g. "ImportRecord.CreateClaimRequest.claim_amount". JSON
+    // names are lower case, so Go names are dropped.
+    var path []string
+    for _, part := range strings.Split(fe.Namespace(), ".")[1:] {
+        if part != "" && !unicode.IsUpper(rune(part[0])) {
+            path = append(path, part)
+        }
+    }
+    field := strings.Join(path, ".")
+    if field == "" {
+        field = fe.Field()
+    }
+    out := FieldError{Field: field, Code: "invalid", Message: "is invalid"}
+    param := fe.Param()
+    kind := fe.Kind()
+    unit := ""
+    switch kind {
+    case reflect.String:
+        unit = " characters"
+    case reflect.Slice, reflect.Map, reflect.Array:
+        unit = " items"
+    }
+    numeric := unit == ""
+    switch fe.Tag() {
+    case "required":
+        out.Code, out.Message = "required", "is required"
+    case "gt":
+        out.Code, out.Message = "too_small", "must be greater than "+param
+    case "gte":
+        out.Code, out.Message = "too_small", "must be at least "+param
+    case "lt":
+        out.Code, out.Message = "too_large", "must be less than "+param
+    case "lte":
+        out.Code, out.Message = "too_large", "must be at most "+param
+    case "min":
+        out.Code, out.Message = "too_short", "must have at least "+param+unit
+        if numeric {
+            out.Code, out.Message = "too_small", "must be at least "+param
+        }
+    case "max":
+        out.Code, out.Message = "too_long", "must have at most "+param+unit
+        if numeric {
+            out.Code, out.Message = "too_large", "must be at most "+param
+        }
+    case "oneof":
+        out.Code, out.Message = "not_allowed", "must be one of: "+param
+    case "email":
+        out.Code, out.Message = "invalid_format", "must be an email address"
+    case "policy_number":
+        out.Code, out.Message = "invalid_format", "is not a valid policy number"
+    }
+    return out
+}
+
+func jsonTypeName(t reflect.Type) string {
+    switch t.Kind() {
+    case reflect.String:
+        return "string"
+    case reflect.Bool:
+        return "boolean"
+    case reflect.Slice, reflect.Array:
+        return "list"
+    case reflect.Map, reflect.Struct:
+        return "object"
+    case reflect.Ptr:
+        return jsonTypeName(t.Elem())
+    }
+    return "number"
+}
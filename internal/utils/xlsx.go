@@ -0,0 +1,74 @@
+package utils
+
+import (
+    "archive/zip"
+    "encoding/xml"
+    "fmt"
+    "io"
+    "strings"
+)
+
+var xlsxParts = []struct{ name, body string }{
+    {"[Content_Types].xml", `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Types xmlns="http://schemas.openxmlformats.org/package/2006/content-types"><Default Extension="rels" ContentType="application/vnd.openxmlformats-package.relationships+xml"/><Default Extension="xml" ContentType="application/xml"/><Override PartName="/xl/workbook.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.sheet.main+xml"/><Override PartName="/xl/worksheets/sheet1.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.worksheet+xml"/></Types>`},
+    {"_rels/.rels", `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships"><Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/officeDocument" Target="xl/workbook.xml"/></Relationships>`},
+    {"xl/workbook.xml", `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<workbook xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships"><sheets><sheet name="Claims" sheetId="1" r:id="rId1"/></sheets></workbook>`},
+    {"xl/_rels/workbook.xml.rels", `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships"><Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/worksheet" Target="worksheets/sheet1.xml"/></Relationships>`},
+}
+
+// XLSXWriter streams a single-sheet workbook row by row, so exports never
+// hold the whole sheet in memory. Strings are written inline.
+type XLSXWriter struct {
+    zw    *zip.Writer
+    sheet io.Writer
+}
+
+func NewXLSXWriter(w io.Writer) (*XLSXWriter, error) {
+    zw := zip.NewWriter(w)
+    for _, part := range xlsxParts {
+        f, err := zw.Create(part.name)
+        if err != nil {
+            return nil, err
+        }
+        if _, err := io.WriteString(f, part.body); err != nil {
+            return nil, err
+        }
+    }
+    sheet, err := zw.Create("xl/worksheets/sheet1.xml")
+    if err != nil {
+        return nil, err
+    }
+    _, err = io.WriteString(sheet, `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>`+
+        `<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main"><sheetData>`)
+    return &XLSXWriter{zw, sheet}, err
+}
+
+func (x *XLSXWriter) WriteRow(values []interface{}) error {
+    var b strings.Builder
+    b.WriteString("<row>")
+    for _, v := range values {
+        switch n := v.(type) {
+        case int, int64, float64:
+            fmt.Fprintf(&b, "<c><v>%v</v></c>", n)
+        case nil:
+            b.WriteString("<c/>")
+        default:
+            b.WriteString(`<c t="inlineStr"><is><t xml:space="preserve">`)
+            xml.EscapeText(&b, []byte(fmt.Sprint(v)))
+            b.WriteString("</t></is></c>")
+        }
+    }
+    b.WriteString("</row>")
+    _, err := io.WriteString(x.sheet, b.String())
+    return err
+}
+
+func (x *XLSXWriter) Close() error {
+    if _, err := io.WriteString(x.sheet, "</sheetData></worksheet>"); err != nil {
+        return err
+    }
+    return x.zw.Close()
+}